@@ -8,13 +8,16 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,6 +29,7 @@ import (
 	"yapi.run/cli/internal/cli/middleware"
 	"yapi.run/cli/internal/config"
 	"yapi.run/cli/internal/core"
+	"yapi.run/cli/internal/gqlschema"
 	"yapi.run/cli/internal/importer"
 	"yapi.run/cli/internal/langserver"
 	"yapi.run/cli/internal/observability"
@@ -149,6 +153,7 @@ func main() {
 		Stress:         app.stressE,
 		About:          aboutE,
 		Import:         importE,
+		Introspect:     app.introspectE,
 	}
 
 	rootCmd := commands.BuildRoot(cfg, handlers)
@@ -278,9 +283,82 @@ func printWatchHeader(path string) {
 // runContext holds options for executeRun
 type runContext struct {
 	path         string
-	strict       bool   // If true, return error on failures; if false, print and return nil
-	returnErrors bool   // If true, return errors even when strict is false (for stress tests)
-	envName      string // Target environment from yapi.config.yml
+	strict       bool              // If true, return error on failures; if false, print and return nil
+	returnErrors bool              // If true, return errors even when strict is false (for stress tests)
+	envName      string            // Target environment from yapi.config.yml
+	row          map[string]string // Matrix row to run; nil runs every row of the config's matrix
+	parent       context.Context   // Cancels the run; nil means a new one that Ctrl-C cancels
+	timeout      time.Duration     // Cancel the run after this long (0: no limit)
+}
+
+// errInterrupted is the cause of a run cancelled by Ctrl-C or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// interruptContext returns a context that the first Ctrl-C or SIGTERM cancels, so a
+// run can report partial results and run teardown. Later signals get their default
+// behavior: a second Ctrl-C exits at once.
+func interruptContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintf(os.Stderr, "\n%s\n", color.Yellow("Interrupted; finishing up (Ctrl-C again to quit now)..."))
+			cancel(errInterrupted)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// maxTerminalHexDump caps how many bytes of a binary response are hex-dumped to a terminal.
+const maxTerminalHexDump = 4096
+
+// printStepReport prints how every step of a chain ended, with a count per status.
+func printStepReport(steps []runner.StepReport) {
+	if len(steps) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n--- Steps ---\n")
+	counts := make(map[runner.StepStatus]int)
+	for _, step := range steps {
+		counts[step.Status]++
+		name := step.Name
+		if step.Finally {
+			name += " (finally)"
+		}
+		var label string
+		switch step.Status {
+		case runner.StepPassed:
+			label = color.Green("PASS")
+		case runner.StepFailed:
+			label = color.Red("FAIL")
+		case runner.StepSkipped:
+			label = color.Yellow("SKIP")
+		case runner.StepCancelled:
+			label = color.Yellow("STOP")
+		default:
+			label = color.Dim("----")
+		}
+		line := fmt.Sprintf("  %s  %s", label, name)
+		if step.Status == runner.StepNotRun || (step.Status == runner.StepCancelled && step.Reason == "") {
+			line += color.Dim(fmt.Sprintf(" (%s)", step.Status))
+		} else if step.Reason != "" {
+			line += color.Dim(": " + step.Reason)
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped, %d not run",
+		counts[runner.StepPassed], counts[runner.StepFailed], counts[runner.StepSkipped], counts[runner.StepNotRun])
+	if n := counts[runner.StepCancelled]; n > 0 {
+		summary += fmt.Sprintf(", %d cancelled", n)
+	}
+	fmt.Fprintln(os.Stderr, summary)
 }
 
 // printResult outputs a single result with optional expectation.
@@ -292,18 +370,24 @@ func (app *rootCommand) printResult(result *runner.Result, expectRes *runner.Exp
 		// Check if content is binary
 		isBinary := utils.IsBinaryContent(result.Body)
 
-		// Skip dumping binary output unless explicitly requested with --binary-output
-		if isBinary && !app.binaryOutput {
+		switch {
+		case result.Body == "" && result.OutputFile != "":
+			// Large downloads are streamed to output_file; printResultMeta reports the saved file
+		case isBinary && !app.binaryOutput:
+			// Show a truncated hex dump on a terminal unless raw bytes are requested with
+			// --binary-output. In non-TTY (CI/piped), silently skip binary output.
 			if isTTY {
-				fmt.Fprintf(os.Stderr, "\n%s\n", color.Yellow("Binary content detected. Output hidden to prevent terminal corruption."))
-				fmt.Fprintf(os.Stderr, "%s\n", color.Dim("To display binary output, use --binary-output flag or pipe to a file."))
+				fmt.Print(output.HexDump([]byte(result.Body), maxTerminalHexDump))
+				if len(result.Body) > maxTerminalHexDump {
+					fmt.Fprintf(os.Stderr, "%s\n", color.Dim("Binary content truncated. Use --binary-output for raw bytes or output_file to save it."))
+				}
 			}
-			// In non-TTY (CI/piped), silently skip binary output
-		} else {
+		default:
 			body := strings.TrimRight(output.Highlight(result.Body, result.ContentType, app.noColor), "\n\r")
 			fmt.Println(body)
 		}
 
+		printGraphQLErrors(result)
 		printResultMeta(result)
 	}
 	if expectRes != nil {
@@ -311,18 +395,49 @@ func (app *rootCommand) printResult(result *runner.Result, expectRes *runner.Exp
 	}
 }
 
+// printGraphQLErrors lists the entries of a GraphQL response's `errors` array to stderr.
+func printGraphQLErrors(result *runner.Result) {
+	if len(result.GraphQLErrors) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n%s\n", color.Red(fmt.Sprintf("GraphQL errors (%d):", len(result.GraphQLErrors))))
+	for _, e := range result.GraphQLErrors {
+		fmt.Fprintf(os.Stderr, "  %s\n", color.Red(e.String()))
+	}
+}
+
 // executeRunE is the unified execution pipeline for both Run and Watch modes.
 // Returns error for middleware to capture.
 func (app *rootCommand) executeRunE(ctx runContext) error {
-	opts := runner.Options{
-		URLOverride:  app.urlOverride,
-		NoColor:      app.noColor,
-		BinaryOutput: app.binaryOutput,
-		Insecure:     app.insecure,
+	if ctx.parent == nil {
+		var stop func()
+		ctx.parent, stop = interruptContext(context.Background())
+		defer stop()
+	}
+	runCtx := ctx.parent
+	if ctx.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(runCtx, ctx.timeout, fmt.Errorf("test timeout of %s exceeded", ctx.timeout))
+		defer cancel()
 	}
 
-	// Load project and environment configuration
-	projEnv, err := loadProjectAndEnv(ctx.path, ctx.envName, true)
+	if ctx.row == nil && ctx.path != "-" {
+		rows, err := config.ReadMatrix(ctx.path)
+		if err != nil {
+			if ctx.strict || ctx.returnErrors {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "%s\n", color.Red(err.Error()))
+			return nil
+		}
+		if rows != nil {
+			// Rows share the run's context, so a timeout bounds the whole matrix
+			ctx.parent, ctx.timeout = runCtx, 0
+			return app.runMatrixRows(ctx, rows)
+		}
+	}
+
+	opts, err := app.runOptions(ctx)
 	if err != nil {
 		if ctx.strict || ctx.returnErrors {
 			return err
@@ -331,17 +446,11 @@ func (app *rootCommand) executeRunE(ctx runContext) error {
 		return nil
 	}
 
-	// Apply project settings if found
-	if projEnv != nil {
-		opts.ProjectRoot = projEnv.projectRoot
-		if projEnv.envVars != nil {
-			opts.EnvOverrides = projEnv.envVars
-			opts.ProjectEnv = projEnv.envName
-		}
+	runRes := app.engine.RunConfig(runCtx, ctx.path, opts)
+	if runRes.Error != nil && runCtx.Err() != nil {
+		runRes.Error = fmt.Errorf("request cancelled: %w", context.Cause(runCtx))
 	}
 
-	runRes := app.engine.RunConfig(context.Background(), ctx.path, opts)
-
 	// Handle validation/parse errors first
 	if runRes.Error != nil && runRes.Analysis == nil {
 		if ctx.strict || ctx.returnErrors {
@@ -362,7 +471,7 @@ func (app *rootCommand) executeRunE(ctx runContext) error {
 
 	// Check if this is a chain config
 	if runRes.Analysis != nil && len(runRes.Analysis.Chain) > 0 {
-		chainResult, chainErr := app.engine.RunChain(context.Background(), runRes.Analysis.Base, runRes.Analysis.Chain, opts, runRes.Analysis)
+		chainResult, chainErr := app.engine.RunChain(runCtx, runRes.Analysis.Base, runRes.Analysis.Chain, opts, runRes.Analysis)
 
 		// Print results from all completed steps (even if chain failed)
 		if chainResult != nil {
@@ -374,6 +483,7 @@ func (app *rootCommand) executeRunE(ctx runContext) error {
 				}
 				app.printResult(stepResult, expectRes)
 			}
+			printStepReport(chainResult.Steps)
 		}
 
 		if chainErr != nil {
@@ -384,6 +494,15 @@ func (app *rootCommand) executeRunE(ctx runContext) error {
 			return nil
 		}
 
+		if len(chainResult.Outputs) > 0 {
+			outputs, err := json.MarshalIndent(chainResult.Outputs, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode chain outputs: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "\n--- Outputs ---\n")
+			fmt.Println(string(outputs))
+		}
+
 		fmt.Fprintln(os.Stderr, "\nChain completed successfully.")
 		out, noColor := app.io(ctx.strict)
 		validation.PrintWarnings(runRes.Analysis, out, noColor)
@@ -412,6 +531,103 @@ func (app *rootCommand) executeRunE(ctx runContext) error {
 	return nil
 }
 
+// runOptions builds the runner options for ctx, including the project environment.
+func (app *rootCommand) runOptions(ctx runContext) (runner.Options, error) {
+	opts := runner.Options{
+		URLOverride:  app.urlOverride,
+		NoColor:      app.noColor,
+		BinaryOutput: app.binaryOutput,
+		Insecure:     app.insecure,
+		ConfigPath:   ctx.path,
+	}
+
+	// Load project and environment configuration
+	projEnv, err := loadProjectAndEnv(ctx.path, ctx.envName, true)
+	if err != nil {
+		return opts, err
+	}
+
+	// Apply project settings if found
+	if projEnv != nil {
+		opts.ProjectRoot = projEnv.projectRoot
+		if projEnv.envVars != nil {
+			opts.EnvOverrides = projEnv.envVars
+			opts.ProjectEnv = projEnv.envName
+		}
+	}
+
+	if ctx.row != nil {
+		opts = opts.WithVars(ctx.row)
+	}
+	return opts, nil
+}
+
+// runMatrixRows runs a config once per matrix row, and fails if any row failed.
+func (app *rootCommand) runMatrixRows(ctx runContext, rows []map[string]string) error {
+	if len(rows) == 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", color.Yellow("Matrix has no rows"))
+		return nil
+	}
+
+	// The config is the same for every row, so validate it once rather than per row
+	if err := app.validateMatrixConfig(ctx); err != nil {
+		if ctx.strict || ctx.returnErrors {
+			return err
+		}
+		if !errors.As(err, new(*validation.Error)) {
+			fmt.Fprintf(os.Stderr, "%s\n", color.Red(err.Error()))
+		}
+		return nil
+	}
+
+	failed := 0
+	for i, row := range rows {
+		if err := context.Cause(ctx.parent); err != nil {
+			fmt.Fprintf(os.Stderr, "\n%s\n", color.Yellow(fmt.Sprintf("%d row(s) not run: %v", len(rows)-i, err)))
+			failed += len(rows) - i
+			break
+		}
+		name := fmt.Sprintf("Row %d/%d: %s", i+1, len(rows), config.MatrixRowName(row))
+		fmt.Fprintf(os.Stderr, "\n%s\n", color.Accent("=== "+name+" ==="))
+		rowCtx := ctx
+		rowCtx.row = row
+		rowCtx.returnErrors = true
+		if err := app.executeRunE(rowCtx); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", color.Red("[FAIL]"), name, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color.Green("[PASS]"), name)
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%d of %d matrix rows failed", failed, len(rows))
+	if ctx.strict || ctx.returnErrors {
+		return err
+	}
+	fmt.Println(color.Red(err.Error()))
+	return nil
+}
+
+// validateMatrixConfig analyzes a matrix config without a row and prints its errors.
+func (app *rootCommand) validateMatrixConfig(ctx runContext) error {
+	opts, err := app.runOptions(ctx)
+	if err != nil {
+		return err
+	}
+	analysis, err := app.engine.Analyze(ctx.path, opts)
+	if err != nil {
+		return err
+	}
+	out, noColor := app.io(ctx.strict)
+	validation.PrintErrors(analysis, out, noColor)
+	if analysis.HasErrors() {
+		return &validation.Error{Diagnostics: analysis.Diagnostics}
+	}
+	return nil
+}
+
 // runConfigPathE runs a config file in strict mode (returns error)
 func (app *rootCommand) runConfigPathE(path string) error {
 	return app.executeRunE(runContext{path: path, strict: true})
@@ -474,7 +690,7 @@ func loadProjectAndEnv(configPath string, requestedEnv string, checkRequirement
 		if readErr != nil {
 			return nil, fmt.Errorf("failed to read config: %w", readErr)
 		}
-		req := validation.CheckEnvironmentRequirement(string(configData), project, projectRoot)
+		req := validation.CheckEnvironmentRequirementInDir(string(configData), project, projectRoot, configDir)
 		if req.Required {
 			return nil, fmt.Errorf("%s", req.Message)
 		}
@@ -586,7 +802,11 @@ func validateE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
 
-	analysis, err := validation.AnalyzeConfigString(string(data))
+	dir := ""
+	if path != "-" {
+		dir = filepath.Dir(path)
+	}
+	analysis, err := validation.AnalyzeConfigStringInDir(string(data), nil, "", dir)
 	if err != nil {
 		if jsonOutput {
 			outputValidateError(err)
@@ -775,7 +995,7 @@ func validateAllFiles(args []string, jsonOutput bool) error {
 		}
 
 		// Validate
-		analysis, err := validation.AnalyzeConfigString(string(data))
+		analysis, err := validation.AnalyzeConfigStringInDir(string(data), nil, "", filepath.Dir(filePath))
 		if err != nil {
 			results = append(results, validationResult{
 				file:  relPath,
@@ -933,7 +1153,7 @@ func shareE(cmd *cobra.Command, args []string) error {
 
 // printExpectationResult prints expectation results to stderr
 func printExpectationResult(res *runner.ExpectationResult) {
-	if res.AssertionsTotal == 0 && !res.StatusChecked {
+	if res.AssertionsTotal == 0 && !res.StatusChecked && !res.GraphQLChecked {
 		return
 	}
 
@@ -948,6 +1168,14 @@ func printExpectationResult(res *runner.ExpectationResult) {
 		}
 	}
 
+	if res.GraphQLChecked {
+		if res.GraphQLPassed {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color.Green("[PASS]"), "GraphQL errors allowed")
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color.Red("[FAIL]"), "no GraphQL errors")
+		}
+	}
+
 	// Print each assertion result
 	for _, ar := range res.AssertionResults {
 		if ar.Passed {
@@ -974,7 +1202,35 @@ func printResultMeta(result *runner.Result) {
 		fmt.Fprintf(os.Stderr, "\n%s\n", color.Dim("URL: "+result.RequestURL))
 	}
 	fmt.Fprintf(os.Stderr, "%s\n", color.Dim("Time: "+result.Duration.String()))
-	fmt.Fprintf(os.Stderr, "%s\n", color.Dim(fmt.Sprintf("Size: %s (%d lines, %d chars)", formatBytes(result.BodyBytes), result.BodyLines, result.BodyChars)))
+	if result.Body == "" && result.BodyBytes > 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim("Size: "+formatBytes(result.BodyBytes)))
+	} else {
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim(fmt.Sprintf("Size: %s (%d lines, %d chars)", formatBytes(result.BodyBytes), result.BodyLines, result.BodyChars)))
+	}
+	if result.OutputFile != "" {
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim("Saved: "+result.OutputFile))
+	}
+	if result.Cache != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim("Cache: "+result.Cache.Status))
+	}
+	if len(result.Attempts) > 0 {
+		statuses := make([]string, len(result.Attempts))
+		for i, a := range result.Attempts {
+			statuses[i] = strconv.Itoa(a.StatusCode)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim(fmt.Sprintf("Attempts: %d (%s)", len(result.Attempts), strings.Join(statuses, ", "))))
+	}
+	if result.TLS != nil {
+		session := fmt.Sprintf("TLS: %s, %s", result.TLS.Version, result.TLS.CipherSuite)
+		if result.TLS.NegotiatedProtocol != "" {
+			session += ", ALPN " + result.TLS.NegotiatedProtocol
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", color.Dim(session))
+		for i, cert := range result.TLS.PeerCertificates {
+			fmt.Fprintf(os.Stderr, "%s\n", color.Dim(fmt.Sprintf("  [%d] %s (issuer: %s, expires %s)",
+				i, cert.Subject, cert.Issuer, cert.NotAfter.Format("2006-01-02"))))
+		}
+	}
 }
 
 func formatBytes(b int) string {
@@ -1139,10 +1395,18 @@ func (app *rootCommand) testE(cmd *cobra.Command, args []string) error {
 	envName, _ := cmd.Flags().GetString("env")
 	all, _ := cmd.Flags().GetBool("all")
 	parallel, _ := cmd.Flags().GetInt("parallel")
+	timeoutStr, _ := cmd.Flags().GetString("timeout")
 
 	if parallel < 1 {
 		return fmt.Errorf("parallel must be at least 1")
 	}
+	var timeout time.Duration
+	if timeoutStr != "" {
+		var err error
+		if timeout, err = time.ParseDuration(timeoutStr); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid timeout: %s", timeoutStr)
+		}
+	}
 
 	// Determine search directory
 	searchDir := "."
@@ -1165,7 +1429,28 @@ func (app *rootCommand) testE(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Fprintf(os.Stderr, "%s\n\n", color.Accent(fmt.Sprintf("Running %d test(s)...", len(testFiles))))
+	// Each matrix row is a test case of its own
+	type testCase struct {
+		file string // Path to run
+		name string // Name in reports
+		row  map[string]string
+	}
+	var cases []testCase
+	for _, testFile := range testFiles {
+		relPath, _ := filepath.Rel(searchDir, testFile)
+		rows, err := config.ReadMatrix(testFile)
+		if err != nil || len(rows) == 0 {
+			// A matrix that fails to load fails its file's test case
+			cases = append(cases, testCase{file: testFile, name: relPath})
+			continue
+		}
+		for j, row := range rows {
+			name := fmt.Sprintf("%s [%d: %s]", relPath, j+1, config.MatrixRowName(row))
+			cases = append(cases, testCase{file: testFile, name: name, row: row})
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n\n", color.Accent(fmt.Sprintf("Running %d test(s)...", len(cases))))
 
 	// Run each test and collect results
 	type testResult struct {
@@ -1176,30 +1461,37 @@ func (app *rootCommand) testE(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create channels and wait group for parallel execution
-	results := make(chan testResult, len(testFiles))
+	results := make(chan testResult, len(cases))
 	semaphore := make(chan struct{}, parallel)
 	var wg sync.WaitGroup
 
+	// Ctrl-C stops the run: tests in progress are cancelled and run their teardown,
+	// and tests not yet started are not run
+	testCtx, stop := interruptContext(context.Background())
+	defer stop()
+
 	// Launch all tests in parallel (controlled by semaphore)
-	for i, testFile := range testFiles {
+	for i, tc := range cases {
 		wg.Add(1)
-		go func(idx int, filePath string) {
+		go func(idx int, tc testCase) {
 			defer wg.Done()
 
 			// Acquire semaphore slot
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			relPath, _ := filepath.Rel(searchDir, filePath)
 			if verbose {
-				fmt.Fprintf(os.Stderr, "%s %s\n", color.Dim(fmt.Sprintf("[%d/%d]", idx+1, len(testFiles))), relPath)
+				fmt.Fprintf(os.Stderr, "%s %s\n", color.Dim(fmt.Sprintf("[%d/%d]", idx+1, len(cases))), tc.name)
 			}
 
 			// Run the test file
-			err := app.executeRunE(runContext{path: filePath, strict: true, envName: envName})
+			err := context.Cause(testCtx)
+			if err == nil {
+				err = app.executeRunE(runContext{path: tc.file, strict: true, envName: envName, row: tc.row, parent: testCtx, timeout: timeout})
+			}
 
 			result := testResult{
-				file:   relPath,
+				file:   tc.name,
 				index:  idx,
 				passed: err == nil,
 				err:    err,
@@ -1219,7 +1511,7 @@ func (app *rootCommand) testE(cmd *cobra.Command, args []string) error {
 					fmt.Fprintf(os.Stderr, "  %s %s\n\n", color.Red("FAIL"), color.Dim(err.Error()))
 				}
 			}
-		}(i, testFile)
+		}(i, tc)
 	}
 
 	// Wait for all tests to complete in a separate goroutine
@@ -1549,6 +1841,50 @@ func printStressTestResults(allResults []stressTestResult, startTime, stopTime t
 	return nil
 }
 
+func (app *rootCommand) introspectE(cmd *cobra.Command, args []string) error {
+	envName, _ := cmd.Flags().GetString("env")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	filePath, _, err := selectConfigFile(args, "introspect")
+	if err != nil {
+		return err
+	}
+
+	opts := runner.Options{
+		URLOverride: app.urlOverride,
+		Insecure:    app.insecure,
+	}
+	projEnv, err := loadProjectAndEnv(filePath, envName, true)
+	if err != nil {
+		return err
+	}
+	if projEnv != nil {
+		opts.ProjectRoot = projEnv.projectRoot
+		if projEnv.envVars != nil {
+			opts.EnvOverrides = projEnv.envVars
+			opts.ProjectEnv = projEnv.envName
+		}
+	}
+
+	res, err := app.engine.Introspect(context.Background(), filePath, opts)
+	if err != nil {
+		return err
+	}
+
+	if outputPath == "" {
+		store := gqlschema.NewStore("")
+		if err := store.Save(res.URL, res.SDL); err != nil {
+			return err
+		}
+		outputPath = store.Path(res.URL)
+	} else if err := os.WriteFile(outputPath, []byte(res.SDL), 0600); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n", color.Green("Schema for "+res.URL+" saved to "+outputPath))
+	return nil
+}
+
 func (app *rootCommand) stressE(cmd *cobra.Command, args []string) error {
 	parallel, _ := cmd.Flags().GetInt("parallel")
 	numRequests, _ := cmd.Flags().GetInt("num-requests")
@@ -29,6 +29,7 @@ type Handlers struct {
 	Stress         func(cmd *cobra.Command, args []string) error
 	About          func(cmd *cobra.Command, args []string) error
 	Import         func(cmd *cobra.Command, args []string) error
+	Introspect     func(cmd *cobra.Command, args []string) error
 }
 
 // BuildRoot builds the root command tree with optional handlers.
@@ -52,7 +53,7 @@ func BuildRoot(cfg *Config, handlers *Handlers) *cobra.Command {
 
 	rootCmd.PersistentFlags().StringVarP(&cfg.URLOverride, "url", "u", "", "Override the URL specified in the config file")
 	rootCmd.PersistentFlags().BoolVar(&cfg.NoColor, "no-color", false, "Disable color output")
-	rootCmd.PersistentFlags().BoolVar(&cfg.BinaryOutput, "binary-output", false, "Display binary content to stdout (by default binary content is hidden)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.BinaryOutput, "binary-output", false, "Write binary content to stdout as raw bytes (by default binary content is shown as a hex dump)")
 	rootCmd.PersistentFlags().BoolVar(&cfg.Insecure, "insecure", false, "Skip TLS verification for HTTPS requests; use insecure transport for gRPC")
 
 	// Build commands from manifest
@@ -113,6 +114,16 @@ var cmdManifest = []CommandSpec{
 			{Name: "all", Shorthand: "a", Type: "bool", Default: false, Usage: "Validate all *.yapi.yml files in current directory or specified directory"},
 		},
 	},
+	{
+		Use:   "introspect [file]",
+		Short: "Fetch the GraphQL schema of a request's endpoint for validation and completion",
+		Long:  "Send an introspection query to the GraphQL endpoint of a yapi config file and cache the schema. Cached schemas are used by validate and the language server when the config has no `schema` file.",
+		Args:  cobra.MaximumNArgs(1),
+		Flags: []FlagSpec{
+			{Name: "env", Shorthand: "e", Type: "string", Default: "", Usage: "Target environment from yapi.config.yml"},
+			{Name: "output", Shorthand: "o", Type: "string", Default: "", Usage: "Write the schema as SDL to this file instead of the cache"},
+		},
+	},
 	{
 		Use:   "share [file]",
 		Short: "Generate a shareable yapi.run link for a config file",
@@ -127,6 +138,7 @@ var cmdManifest = []CommandSpec{
 			{Name: "verbose", Shorthand: "v", Type: "bool", Default: false, Usage: "Show verbose output for each test"},
 			{Name: "env", Shorthand: "e", Type: "string", Default: "", Usage: "Target environment from yapi.config.yml"},
 			{Name: "parallel", Shorthand: "p", Type: "int", Default: 1, Usage: "Number of parallel threads to run tests on"},
+			{Name: "timeout", Shorthand: "t", Type: "string", Default: "", Usage: "Fail a test that runs longer than this (e.g., 30s, 2m); its finally steps still run"},
 		},
 	},
 	{
@@ -210,6 +222,8 @@ func getHandler(h *Handlers, use string) func(*cobra.Command, []string) error {
 		return h.About
 	case "import":
 		return h.Import
+	case "introspect":
+		return h.Introspect
 	default:
 		return nil
 	}
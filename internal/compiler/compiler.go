@@ -112,6 +112,24 @@ func Compile(cfg *config.ConfigV1, resolver vars.Resolver) *CompiledRequest {
 		req.Metadata["read_timeout"] = fmt.Sprintf("%d", interpolated.ReadTimeout)
 		req.Metadata["idle_timeout"] = fmt.Sprintf("%d", interpolated.IdleTimeout)
 		req.Metadata["close_after_send"] = fmt.Sprintf("%t", interpolated.CloseAfterSend)
+		if domain.IsTLSSocketURL(req.URL) {
+			req.Metadata["tls"] = "true"
+			req.Metadata["tls_server_name"] = interpolated.TLS.ServerName
+			req.Metadata["tls_ca_cert"] = interpolated.TLS.CACert
+			req.Metadata["tls_client_cert"] = interpolated.TLS.ClientCert
+			req.Metadata["tls_client_key"] = interpolated.TLS.ClientKey
+			req.Metadata["tls_alpn"] = strings.Join(interpolated.TLS.ALPN, ",")
+		}
+
+	case constants.TransportUDP:
+		if interpolated.Encoding != "" && !isValidEncoding(interpolated.Encoding) {
+			res.Errors = append(res.Errors, fmt.Errorf("invalid encoding '%s'", interpolated.Encoding))
+		}
+		req.Metadata["data"] = interpolated.Data
+		req.Metadata["encoding"] = interpolated.Encoding
+		req.Metadata["read_timeout"] = fmt.Sprintf("%d", interpolated.ReadTimeout)
+		req.Metadata["close_after_send"] = fmt.Sprintf("%t", interpolated.CloseAfterSend)
+		req.Metadata["datagrams"] = fmt.Sprintf("%d", interpolated.Datagrams)
 	}
 
 	// JQ Filter
@@ -119,9 +137,40 @@ func Compile(cfg *config.ConfigV1, resolver vars.Resolver) *CompiledRequest {
 		req.Metadata["jq_filter"] = interpolated.JQFilter
 	}
 
+	if interpolated.ResponseEncoding != "" {
+		req.Metadata["response_encoding"] = interpolated.ResponseEncoding
+	}
+
 	// GraphQL
 	if interpolated.Graphql != "" {
 		req.Metadata["graphql_query"] = interpolated.Graphql
+		if interpolated.Schema != "" {
+			req.Metadata["graphql_schema"] = interpolated.Schema
+		}
+		if interpolated.Events != 0 {
+			req.Metadata["graphql_events"] = fmt.Sprintf("%d", interpolated.Events)
+		}
+		if interpolated.PersistedQuery {
+			req.Metadata["graphql_persisted"] = "true"
+		}
+		if interpolated.OperationName != "" {
+			req.Metadata["graphql_operation_name"] = interpolated.OperationName
+		}
+		if len(interpolated.Batch) > 0 {
+			if batch, err := interpolated.BatchMetadata(); err != nil {
+				res.Errors = append(res.Errors, err)
+			} else {
+				req.Metadata["graphql_batch"] = batch
+			}
+		}
+		if len(interpolated.Uploads) > 0 {
+			uploadsJSON, err := json.Marshal(interpolated.Uploads)
+			if err != nil {
+				res.Errors = append(res.Errors, fmt.Errorf("could not marshal graphql uploads: %w", err))
+			} else {
+				req.Metadata["graphql_uploads"] = string(uploadsJSON)
+			}
+		}
 		if interpolated.Variables != nil {
 			varsJSON, err := json.Marshal(interpolated.Variables)
 			if err != nil {
@@ -161,6 +210,9 @@ func resolveConfig(cfg *config.ConfigV1, resolver vars.Resolver) (*config.Config
 	if clone.Query, err = walkStringMap(clone.Query, resolver); err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
+	if clone.Uploads, err = walkStringMap(clone.Uploads, resolver); err != nil {
+		return nil, fmt.Errorf("uploads: %w", err)
+	}
 
 	// Walk deep maps
 	if clone.Body != nil {
@@ -175,6 +227,17 @@ func resolveConfig(cfg *config.ConfigV1, resolver vars.Resolver) (*config.Config
 			return nil, fmt.Errorf("variables: %w", err)
 		}
 	}
+	if clone.Batch != nil {
+		clone.Batch = append([]config.GraphQLOperation(nil), clone.Batch...)
+		for i := range clone.Batch {
+			if clone.Batch[i].Variables == nil {
+				continue
+			}
+			if clone.Batch[i].Variables, err = walkDeep(clone.Batch[i].Variables, resolver); err != nil {
+				return nil, fmt.Errorf("batch[%d].variables: %w", i, err)
+			}
+		}
+	}
 
 	return &clone, nil
 }
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"yapi.run/cli/internal/constants"
+	"yapi.run/cli/internal/domain"
+)
+
+// AuthConfig describes how a request should be authenticated or signed.
+// The Type field selects the scheme; the remaining fields are scheme-specific.
+type AuthConfig struct {
+	Type string `yaml:"type"` // aws_sigv4, hmac
+
+	// AWS Signature V4. Credentials are optional: when omitted they are read from
+	// AWS_* environment variables, project vars, or the AWS shared credentials file.
+	Region          string `yaml:"region,omitempty"`
+	Service         string `yaml:"service,omitempty"` // e.g. execute-api, lambda, s3
+	AccessKeyID     string `yaml:"access_key_id,omitempty"`
+	SecretAccessKey string `yaml:"secret_access_key,omitempty"`
+	SessionToken    string `yaml:"session_token,omitempty"`
+	Profile         string `yaml:"profile,omitempty"` // Shared credentials file profile
+
+	// HMAC signing over a canonical string built from the final request
+	Secret          string `yaml:"secret,omitempty"`
+	Algorithm       string `yaml:"algorithm,omitempty"`        // sha256 (default), sha1, sha512
+	Template        string `yaml:"template,omitempty"`         // Canonical string, e.g. "{method}\n{path}\n{timestamp}\n{body}"
+	Header          string `yaml:"header,omitempty"`           // Header receiving the signature (default X-Signature)
+	Format          string `yaml:"format,omitempty"`           // hex (default), base64
+	Prefix          string `yaml:"prefix,omitempty"`           // Prepended to the signature (e.g. "sha256=")
+	TimestampHeader string `yaml:"timestamp_header,omitempty"` // Optional header carrying {timestamp}
+}
+
+// enrichMetadata adds auth-specific metadata to the request
+func (a *AuthConfig) enrichMetadata(req *domain.Request) {
+	req.Metadata["auth_type"] = a.Type
+
+	switch a.Type {
+	case constants.AuthAWSSigV4:
+		req.Metadata["aws_region"] = a.Region
+		req.Metadata["aws_service"] = a.Service
+		req.Metadata["aws_access_key_id"] = a.AccessKeyID
+		req.Metadata["aws_secret_access_key"] = a.SecretAccessKey
+		req.Metadata["aws_session_token"] = a.SessionToken
+		req.Metadata["aws_profile"] = a.Profile
+	case constants.AuthHMAC:
+		// Signed by the runner at send time, once the request is final
+		req.Metadata["hmac_secret"] = a.Secret
+		req.Metadata["hmac_algorithm"] = a.Algorithm
+		req.Metadata["hmac_template"] = a.Template
+		req.Metadata["hmac_header"] = a.Header
+		req.Metadata["hmac_format"] = a.Format
+		req.Metadata["hmac_prefix"] = a.Prefix
+		req.Metadata["hmac_timestamp_header"] = a.TimestampHeader
+	}
+}
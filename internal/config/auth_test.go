@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadFromString_HMACMetadata(t *testing.T) {
+	t.Setenv("PARTNER_SECRET", "topsecret")
+	res, err := LoadFromString(`yapi: v1
+url: https://partner.example.com
+path: /v1/payments
+method: POST
+json: '{"amount":100}'
+auth:
+  type: hmac
+  secret: ${PARTNER_SECRET}
+  template: "{method}\n{path}\n{body_sha256}"
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	// Signing happens at send time; the load only records the expanded settings
+	if got := res.Request.Headers["X-Signature"]; got != "" {
+		t.Errorf("X-Signature = %q, want the request unsigned at load time", got)
+	}
+	if got := res.Request.Metadata["hmac_secret"]; got != "topsecret" {
+		t.Errorf("hmac_secret = %q, want topsecret", got)
+	}
+	if got := res.Request.Metadata["hmac_template"]; got != "{method}\n{path}\n{body_sha256}" {
+		t.Errorf("hmac_template = %q", got)
+	}
+	if res.Request.Metadata["auth_type"] != "hmac" {
+		t.Errorf("auth_type = %q, want hmac", res.Request.Metadata["auth_type"])
+	}
+}
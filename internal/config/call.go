@@ -0,0 +1,24 @@
+package config
+
+// LoadCallTarget parses the yapi file run by a chain step's `call`, merged with the
+// environment defaults like any file being run. Variables are left for the runner to
+// expand, since the call's `with` parameters provide some of them. A single request
+// becomes a chain of one step with the given name.
+func LoadCallTarget(data, name string, defaults *ConfigV1) (*ConfigV1, []ChainStep, error) {
+	res, err := LoadFromStringWithOptions(data, keepVariable, defaults)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res.Chain) > 0 {
+		return res.Base, res.Chain, nil
+	}
+	step := ChainStep{Name: name}
+	step.Expect = res.Base.Expect
+	return res.Base, []ChainStep{step}, nil
+}
+
+// keepVariable resolves a variable to a reference to itself, so that it survives
+// loading unexpanded.
+func keepVariable(key string) (string, error) {
+	return "${" + key + "}", nil
+}
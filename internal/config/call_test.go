@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestLoadCallTarget(t *testing.T) {
+	base, steps, err := LoadCallTarget(`yapi: v1
+url: ${BASE_URL}/login
+method: POST
+body:
+  username: ${username}
+expect:
+  status: 200
+`, "login", nil)
+	if err != nil {
+		t.Fatalf("LoadCallTarget failed: %v", err)
+	}
+	if base.URL != "${BASE_URL}/login" || base.Body["username"] != "${username}" {
+		t.Errorf("variables should not be expanded: %s %v", base.URL, base.Body)
+	}
+	if len(steps) != 1 || steps[0].Name != "login" || steps[0].Expect.Status != 200 {
+		t.Errorf("a single request should become one step: %+v", steps)
+	}
+
+	base, steps, err = LoadCallTarget(`yapi: v1
+url: https://auth.example.com
+chain_outputs:
+  token: ${token.access_token}
+chain:
+  - name: token
+    path: /token
+`, "login", nil)
+	if err != nil {
+		t.Fatalf("LoadCallTarget failed: %v", err)
+	}
+	if len(steps) != 1 || steps[0].Name != "token" || base.ChainOutputs["token"] != "${token.access_token}" {
+		t.Errorf("unexpected chain: %+v %v", steps, base.ChainOutputs)
+	}
+
+	if _, _, err := LoadCallTarget("yapi: v2\n", "x", nil); err == nil {
+		t.Error("expected an unsupported version error")
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/vars"
+)
+
+// ConversationStep is one send/expect exchange in a scripted TCP conversation.
+// A step may send, expect, or both; the expectation is read after the send.
+type ConversationStep struct {
+	Send          string `yaml:"send,omitempty" json:"send,omitempty"`                     // Data to write, decoded per `encoding`
+	ExpectRegex   string `yaml:"expect_regex,omitempty" json:"expect_regex,omitempty"`     // Read until this regex matches
+	ExpectLiteral string `yaml:"expect_literal,omitempty" json:"expect_literal,omitempty"` // Read until this exact text appears
+	ExpectBytes   int    `yaml:"expect_bytes,omitempty" json:"expect_bytes,omitempty"`     // Read exactly this many bytes
+	Timeout       string `yaml:"timeout,omitempty" json:"timeout,omitempty"`               // Per-step read timeout (e.g. "2s")
+}
+
+// expandConversation expands variables in conversation steps. The slice is copied first
+// because it may be shared with the base config of a chain.
+func (c *ConfigV1) expandConversation(resolver vars.Resolver) {
+	if len(c.Conversation) == 0 {
+		return
+	}
+	steps := make([]ConversationStep, len(c.Conversation))
+	copy(steps, c.Conversation)
+	for i := range steps {
+		vars.ExpandAll(&steps[i], resolver)
+	}
+	c.Conversation = steps
+}
+
+// enrichConversation serializes the conversation into request metadata for the TCP transport
+func (c *ConfigV1) enrichConversation(req *domain.Request) error {
+	for i, step := range c.Conversation {
+		expects := 0
+		if step.ExpectRegex != "" {
+			expects++
+		}
+		if step.ExpectLiteral != "" {
+			expects++
+		}
+		if step.ExpectBytes > 0 {
+			expects++
+		}
+		if expects > 1 {
+			return fmt.Errorf("conversation step %d: `expect_regex`, `expect_literal`, and `expect_bytes` are mutually exclusive", i+1)
+		}
+		if expects == 0 && step.Send == "" {
+			return fmt.Errorf("conversation step %d: requires `send` or an expectation", i+1)
+		}
+	}
+
+	data, err := json.Marshal(c.Conversation)
+	if err != nil {
+		return fmt.Errorf("could not marshal conversation: %w", err)
+	}
+	req.Metadata["conversation"] = string(data)
+	return nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromString_Conversation(t *testing.T) {
+	t.Setenv("SMTP_USER", "yapi")
+	res, err := LoadFromString(`yapi: v1
+url: tcp://localhost:25
+conversation:
+  - expect_regex: "^220 "
+  - send: "EHLO ${SMTP_USER}\r\n"
+    expect_literal: "250 OK"
+    timeout: 2s
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+
+	var steps []ConversationStep
+	if err := json.Unmarshal([]byte(res.Request.Metadata["conversation"]), &steps); err != nil {
+		t.Fatalf("conversation metadata is not valid JSON: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(steps))
+	}
+	if steps[1].Send != "EHLO yapi\r\n" || steps[1].ExpectLiteral != "250 OK" || steps[1].Timeout != "2s" {
+		t.Errorf("unexpected step: %+v", steps[1])
+	}
+}
+
+func TestConversation_ExpandDoesNotMutateBase(t *testing.T) {
+	t.Setenv("CMD", "PING")
+	base := ConfigV1{
+		URL:          "tcp://localhost:6379",
+		Conversation: []ConversationStep{{Send: "${CMD}\r\n", ExpectLiteral: "+PONG"}},
+	}
+
+	merged := base.Merge(ChainStep{Name: "ping"})
+	if _, err := merged.ToDomain(); err != nil {
+		t.Fatalf("ToDomain failed: %v", err)
+	}
+	if base.Conversation[0].Send != "${CMD}\r\n" {
+		t.Errorf("base conversation was mutated: %q", base.Conversation[0].Send)
+	}
+}
+
+func TestConversation_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "multiple expectations",
+			yaml: `yapi: v1
+url: tcp://localhost:25
+conversation:
+  - expect_regex: "^220"
+    expect_bytes: 3`,
+			wantErr: "mutually exclusive",
+		},
+		{
+			name: "empty step",
+			yaml: `yapi: v1
+url: tcp://localhost:25
+conversation:
+  - timeout: 1s`,
+			wantErr: "requires `send` or an expectation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromString(tt.yaml)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// FramingConfig describes how a TCP response message is delimited, so the
+// transport can return as soon as one complete frame has arrived.
+type FramingConfig struct {
+	Type        string `yaml:"type"`                   // delimiter, fixed, length_prefix
+	Delimiter   string `yaml:"delimiter,omitempty"`    // delimiter: frame terminator, decoded per `encoding`
+	Length      int    `yaml:"length,omitempty"`       // fixed: frame size in bytes
+	PrefixBytes int    `yaml:"prefix_bytes,omitempty"` // length_prefix: 1, 2 or 4 (default 4)
+	ByteOrder   string `yaml:"byte_order,omitempty"`   // length_prefix: big (default) or little
+}
+
+// enrichMetadata adds framing settings to the request
+func (f *FramingConfig) enrichMetadata(req *domain.Request) {
+	req.Metadata["framing"] = f.Type
+	req.Metadata["framing_delimiter"] = f.Delimiter
+	req.Metadata["framing_length"] = fmt.Sprintf("%d", f.Length)
+	req.Metadata["framing_prefix_bytes"] = fmt.Sprintf("%d", f.PrefixBytes)
+	req.Metadata["framing_byte_order"] = f.ByteOrder
+}
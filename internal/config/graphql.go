@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"yapi.run/cli/internal/vars"
+)
+
+// GraphQLOperation is one operation of a batched GraphQL request, selected from the
+// request's graphql document.
+type GraphQLOperation struct {
+	Name          string         `yaml:"name,omitempty"`           // Key of the result in the response body (defaults to operation_name)
+	OperationName string         `yaml:"operation_name,omitempty"` // Operation to run from the document
+	Variables     map[string]any `yaml:"variables,omitempty"`
+}
+
+// GraphQLBatchEntry is a batch operation as sent to the GraphQL transport, with its
+// result key resolved.
+type GraphQLBatchEntry struct {
+	Name          string         `json:"name"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// expandBatch expands variables in batch operations. The slice is copied first
+// because it may be shared with the base config of a chain.
+func (c *ConfigV1) expandBatch(resolver vars.Resolver) {
+	if len(c.Batch) == 0 {
+		return
+	}
+	ops := make([]GraphQLOperation, len(c.Batch))
+	copy(ops, c.Batch)
+	for i := range ops {
+		vars.ExpandAll(&ops[i], resolver)
+	}
+	c.Batch = ops
+}
+
+// BatchMetadata serializes the batch for the GraphQL transport. Each result is keyed
+// by the operation's name, its operation_name, or its position in the batch.
+func (c *ConfigV1) BatchMetadata() (string, error) {
+	entries := make([]GraphQLBatchEntry, 0, len(c.Batch))
+	seen := make(map[string]bool, len(c.Batch))
+	for i, op := range c.Batch {
+		name := op.Name
+		if name == "" {
+			name = op.OperationName
+		}
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		if seen[name] {
+			return "", fmt.Errorf("batch operation %d: duplicate result name %q; set `name` to tell them apart", i+1, name)
+		}
+		seen[name] = true
+		entries = append(entries, GraphQLBatchEntry{
+			Name:          name,
+			OperationName: op.OperationName,
+			Variables:     op.Variables,
+		})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal graphql batch: %w", err)
+	}
+	return string(data), nil
+}
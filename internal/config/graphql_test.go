@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromString_GraphQLBatch(t *testing.T) {
+	t.Setenv("USER_ID", "42")
+	res, err := LoadFromString(`yapi: v1
+url: http://localhost/graphql
+graphql: |
+  query GetUser($id: ID!) { user(id: $id) { name } }
+  query ListPosts { posts { title } }
+batch:
+  - operation_name: GetUser
+    variables:
+      id: ${USER_ID}
+  - name: other_user
+    operation_name: GetUser
+    variables:
+      id: "7"
+  - operation_name: ListPosts
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+
+	var batch []GraphQLBatchEntry
+	if err := json.Unmarshal([]byte(res.Request.Metadata["graphql_batch"]), &batch); err != nil {
+		t.Fatalf("batch metadata is not valid JSON: %v", err)
+	}
+	var names []string
+	for _, entry := range batch {
+		names = append(names, entry.Name)
+	}
+	if strings.Join(names, ",") != "GetUser,other_user,ListPosts" {
+		t.Errorf("result names = %v", names)
+	}
+	if batch[0].Variables["id"] != "42" {
+		t.Errorf("variables were not expanded: %v", batch[0].Variables)
+	}
+}
+
+func TestLoadFromString_GraphQLBatchDuplicateNames(t *testing.T) {
+	_, err := LoadFromString(`yapi: v1
+url: http://localhost/graphql
+graphql: 'query GetUser { me { name } }'
+batch:
+  - operation_name: GetUser
+  - operation_name: GetUser
+`)
+	if err == nil || !strings.Contains(err.Error(), `duplicate result name "GetUser"`) {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestLoadFromString_GraphQLOperationName(t *testing.T) {
+	res, err := LoadFromString(`yapi: v1
+url: http://localhost/graphql
+graphql: 'query A { a } query B { b }'
+operation_name: B
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	if got := res.Request.Metadata["graphql_operation_name"]; got != "B" {
+		t.Errorf("graphql_operation_name = %q, want B", got)
+	}
+}
@@ -172,3 +172,24 @@ chain:
 		_, _ = LoadFromString(input)
 	})
 }
+
+func TestLoadFromString_GraphQLKeepsQueryVariables(t *testing.T) {
+	t.Setenv("USER_FIELDS", "id name")
+	t.Setenv("ORG", "acme")
+	t.Setenv("id", "env-id") // Shadowed by the operation's own $id
+	res, err := LoadFromString(`yapi: v1
+url: http://localhost/graphql
+graphql: |
+  query GetUser($id: ID!) { user(id: $id, org: "$ORG") { ${USER_FIELDS} } }
+variables:
+  id: "1"
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+
+	want := "query GetUser($id: ID!) { user(id: $id, org: \"acme\") { id name } }\n"
+	if got := res.Request.Metadata["graphql_query"]; got != want {
+		t.Errorf("graphql_query = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadMatrix returns the matrix rows of the config file at path, or nil if it has
+// no matrix.
+func ReadMatrix(path string) ([]map[string]string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the config file being run
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg struct {
+		Matrix any `yaml:"matrix"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	if cfg.Matrix == nil {
+		return nil, nil
+	}
+	return MatrixRows(cfg.Matrix, filepath.Dir(path))
+}
+
+// MatrixRows returns the rows of a matrix: an inline list of mappings, or the path,
+// relative to dir, of a CSV file with a header row, a JSON array of objects, or a
+// JSONL file of one object per line. Values that are not strings are encoded as JSON.
+func MatrixRows(matrix any, dir string) ([]map[string]string, error) {
+	switch v := matrix.(type) {
+	case []any:
+		return matrixRowsFromList(v)
+	case string:
+		if v == "" {
+			return nil, fmt.Errorf("matrix: empty file path")
+		}
+		path := v
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path) // #nosec G304 -- path is the matrix file named by the config
+		if err != nil {
+			return nil, fmt.Errorf("matrix: %w", err)
+		}
+		var rows []map[string]string
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".csv":
+			rows, err = matrixRowsFromCSV(data)
+		case ".json":
+			var list []any
+			if err = json.Unmarshal(data, &list); err == nil {
+				rows, err = matrixRowsFromList(list)
+			}
+		case ".jsonl":
+			rows, err = matrixRowsFromJSONL(data)
+		default:
+			return nil, fmt.Errorf("matrix: unsupported file type '%s' (use .csv, .json or .jsonl)", ext)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("matrix %s: %w", v, err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("matrix: expected a list of rows or a file path, got %T", matrix)
+	}
+}
+
+func matrixRowsFromList(list []any) ([]map[string]string, error) {
+	rows := make([]map[string]string, 0, len(list))
+	for i, item := range list {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("row %d: expected a mapping of fields, got %T", i+1, item)
+		}
+		row := make(map[string]string, len(fields))
+		for k, val := range fields {
+			s, err := matrixValue(val)
+			if err != nil {
+				return nil, fmt.Errorf("row %d field '%s': %w", i+1, k, err)
+			}
+			row[k] = s
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func matrixRowsFromCSV(data []byte) ([]map[string]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for j, name := range header {
+			row[strings.TrimSpace(name)] = record[j]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func matrixRowsFromJSONL(data []byte) ([]map[string]string, error) {
+	var list []any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var item any
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		list = append(list, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return matrixRowsFromList(list)
+}
+
+// matrixValue turns a row field into the string a ${field} reference expands to.
+func matrixValue(val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// MatrixRowName describes a row for reports: its `name` field if it has one, or its
+// fields in key order.
+func MatrixRowName(row map[string]string) string {
+	if name := row["name"]; name != "" {
+		return name
+	}
+	keys := make([]string, 0, len(row))
+	for k := range row {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + row[k]
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatrixRows(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rows.csv":   "email,status\nbad,400\n\"a@b.c\",201\n",
+		"rows.json":  `[{"email": "bad", "status": 400}, {"email": "a@b.c", "status": 201}]`,
+		"rows.jsonl": "{\"email\": \"bad\", \"status\": 400}\n\n{\"email\": \"a@b.c\", \"status\": 201}\n",
+		"rows.txt":   "bad",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []map[string]string{
+		{"email": "bad", "status": "400"},
+		{"email": "a@b.c", "status": "201"},
+	}
+
+	inline := []any{
+		map[string]any{"email": "bad", "status": 400},
+		map[string]any{"email": "a@b.c", "status": 201},
+	}
+	for _, matrix := range []any{inline, "rows.csv", "rows.json", "rows.jsonl"} {
+		rows, err := MatrixRows(matrix, dir)
+		if err != nil {
+			t.Errorf("MatrixRows(%v) error: %v", matrix, err)
+			continue
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("MatrixRows(%v) = %v, want %v", matrix, rows, want)
+		}
+	}
+
+	for matrix, msg := range map[any]string{
+		"rows.txt":     "unsupported file type '.txt'",
+		"missing.csv":  "no such file",
+		"":             "empty file path",
+		42:             "expected a list of rows or a file path",
+		"../rows.json": "no such file",
+	} {
+		if _, err := MatrixRows(matrix, dir); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("MatrixRows(%v) error = %v, want it to contain %q", matrix, err, msg)
+		}
+	}
+	if _, err := MatrixRows([]any{"bad"}, dir); err == nil || !strings.Contains(err.Error(), "row 1: expected a mapping") {
+		t.Errorf("expected a row error, got %v", err)
+	}
+}
+
+func TestMatrixRowName(t *testing.T) {
+	if got := MatrixRowName(map[string]string{"name": "empty email", "email": ""}); got != "empty email" {
+		t.Errorf("MatrixRowName() = %q", got)
+	}
+	if got := MatrixRowName(map[string]string{"status": "400", "email": "bad"}); got != "email=bad, status=400" {
+		t.Errorf("MatrixRowName() = %q", got)
+	}
+}
@@ -208,6 +208,7 @@ func TestAssertionSet_UnmarshalYAML(t *testing.T) {
 		yaml        string
 		wantBody    []string
 		wantHeaders []string
+		wantCache   []string
 		wantErr     bool
 	}{
 		{
@@ -251,6 +252,17 @@ func TestAssertionSet_UnmarshalYAML(t *testing.T) {
 			wantHeaders: []string{`.["Content-Type"] != null`},
 			wantErr:     false,
 		},
+		{
+			name: "grouped map - cache",
+			yaml: `assert:
+  cache:
+    - .revalidated == true
+  body:
+    - .id == 1`,
+			wantBody:  []string{".id == 1"},
+			wantCache: []string{".revalidated == true"},
+			wantErr:   false,
+		},
 		{
 			name:        "empty flat array",
 			yaml:        `assert: []`,
@@ -310,6 +322,10 @@ func TestAssertionSet_UnmarshalYAML(t *testing.T) {
 					t.Errorf("UnmarshalYAML() headers[%d] = %q, want %q", i, data.Assert.Headers[i], want)
 				}
 			}
+
+			if len(data.Assert.Cache) != len(tt.wantCache) {
+				t.Errorf("UnmarshalYAML() cache count = %d, want %d", len(data.Assert.Cache), len(tt.wantCache))
+			}
 		})
 	}
 }
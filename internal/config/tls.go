@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// TLSConfig configures TLS for socket transports (tls:// and tcps:// URLs).
+// Certificate verification is disabled with the top-level `insecure` flag.
+type TLSConfig struct {
+	ServerName string   `yaml:"server_name,omitempty"` // SNI and verification name, defaults to the URL host
+	CACert     string   `yaml:"ca_cert,omitempty"`     // PEM CA bundle used instead of the system roots
+	ClientCert string   `yaml:"client_cert,omitempty"` // PEM client certificate for mutual TLS
+	ClientKey  string   `yaml:"client_key,omitempty"`  // PEM private key for client_cert
+	ALPN       []string `yaml:"alpn,omitempty"`        // Application protocols to offer (e.g. h2, redis)
+}
+
+// enrichMetadata adds TLS settings to the request
+func (t *TLSConfig) enrichMetadata(req *domain.Request) {
+	req.Metadata["tls"] = "true"
+	req.Metadata["tls_server_name"] = t.ServerName
+	req.Metadata["tls_ca_cert"] = t.CACert
+	req.Metadata["tls_client_cert"] = t.ClientCert
+	req.Metadata["tls_client_key"] = t.ClientKey
+	req.Metadata["tls_alpn"] = strings.Join(t.ALPN, ",")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -19,35 +20,55 @@ import (
 // knownV1Keys is the set of valid keys for v1 config files.
 // Must be kept in sync with ConfigV1 struct yaml tags.
 var knownV1Keys = map[string]bool{
-	"yapi":             true,
-	"url":              true,
-	"path":             true,
-	"method":           true,
-	"content_type":     true,
-	"headers":          true,
-	"body":             true,
-	"json":             true,
-	"form":             true,
-	"query":            true,
-	"graphql":          true,
-	"variables":        true,
-	"service":          true,
-	"rpc":              true,
-	"proto":            true,
-	"proto_path":       true,
-	"data":             true,
-	"encoding":         true,
-	"jq_filter":        true,
-	"insecure":         true,
-	"plaintext":        true,
-	"read_timeout":     true,
-	"idle_timeout":     true,
-	"close_after_send": true,
-	"chain":            true,
-	"expect":           true,
-	"delay":            true,
-	"output_file":      true,
-	"timeout":          true,
+	"yapi":              true,
+	"url":               true,
+	"path":              true,
+	"method":            true,
+	"content_type":      true,
+	"headers":           true,
+	"body":              true,
+	"json":              true,
+	"form":              true,
+	"query":             true,
+	"graphql":           true,
+	"variables":         true,
+	"service":           true,
+	"rpc":               true,
+	"proto":             true,
+	"proto_path":        true,
+	"data":              true,
+	"encoding":          true,
+	"jq_filter":         true,
+	"insecure":          true,
+	"plaintext":         true,
+	"read_timeout":      true,
+	"idle_timeout":      true,
+	"close_after_send":  true,
+	"chain":             true,
+	"expect":            true,
+	"delay":             true,
+	"output_file":       true,
+	"timeout":           true,
+	"auth":              true,
+	"output_resume":     true,
+	"output_sha256":     true,
+	"cache":             true,
+	"datagrams":         true,
+	"tls":               true,
+	"conversation":      true,
+	"framing":           true,
+	"response_encoding": true,
+	"schema":            true,
+	"events":            true,
+	"uploads":           true,
+	"persisted_query":   true,
+	"operation_name":    true,
+	"batch":             true,
+	"parallel":          true,
+	"chain_outputs":     true,
+	"finally":           true,
+	"matrix":            true,
+	"chain_timeout":     true,
 }
 
 // FindUnknownKeys checks a raw map for keys not in knownV1Keys.
@@ -75,11 +96,16 @@ type ConfigV1 struct {
 	JSON           string            `yaml:"json,omitempty"` // Raw JSON override
 	Form           map[string]string `yaml:"form,omitempty"` // Form data (application/x-www-form-urlencoded or multipart/form-data)
 	Query          map[string]string `yaml:"query,omitempty"`
-	Graphql        string            `yaml:"graphql,omitempty"`   // GraphQL query/mutation
-	Variables      map[string]any    `yaml:"variables,omitempty"` // GraphQL variables
-	Service        string            `yaml:"service,omitempty"`   // gRPC
-	RPC            string            `yaml:"rpc,omitempty"`       // gRPC
-	Proto          string            `yaml:"proto,omitempty"`     // gRPC
+	Graphql        string            `yaml:"graphql,omitempty"`         // GraphQL query/mutation
+	Variables      map[string]any    `yaml:"variables,omitempty"`       // GraphQL variables
+	Schema         string            `yaml:"schema,omitempty"`          // GraphQL SDL or introspection JSON file for validation
+	Events         int               `yaml:"events,omitempty"`          // GraphQL subscription events to collect
+	Uploads        map[string]string `yaml:"uploads,omitempty"`         // GraphQL Upload variables mapped to local files (multipart request)
+	PersistedQuery bool              `yaml:"persisted_query,omitempty"` // GraphQL Automatic Persisted Queries
+	OperationName  string            `yaml:"operation_name,omitempty"`  // GraphQL operation to run when the document has several
+	Service        string            `yaml:"service,omitempty"`         // gRPC
+	RPC            string            `yaml:"rpc,omitempty"`             // gRPC
+	Proto          string            `yaml:"proto,omitempty"`           // gRPC
 	ProtoPath      string            `yaml:"proto_path,omitempty"`
 	Data           string            `yaml:"data,omitempty"`     // TCP raw data
 	Encoding       string            `yaml:"encoding,omitempty"` // text, hex, base64
@@ -89,26 +115,76 @@ type ConfigV1 struct {
 	ReadTimeout    int               `yaml:"read_timeout,omitempty"` // TCP read timeout in seconds
 	IdleTimeout    int               `yaml:"idle_timeout,omitempty"` // TCP idle timeout in milliseconds (default 500)
 	CloseAfterSend bool              `yaml:"close_after_send,omitempty"`
+	Datagrams      int               `yaml:"datagrams,omitempty"` // UDP reply datagrams to await (default 1)
+
+	// Batch sends several operations of the graphql document in one request
+	Batch []GraphQLOperation `yaml:"batch,omitempty"`
+
+	// Conversation scripts send/expect exchanges over a single TCP connection
+	Conversation []ConversationStep `yaml:"conversation,omitempty"`
+
+	// Framing ends a TCP response at a message boundary instead of EOF or idle timeout
+	Framing FramingConfig `yaml:"framing,omitempty"`
+
+	// TLS configures tls:// and tcps:// socket connections
+	TLS TLSConfig `yaml:"tls,omitempty"`
+
+	// Auth signs or authenticates the request (e.g. AWS Signature V4)
+	Auth AuthConfig `yaml:"auth,omitempty"`
 
 	// Flow control
 	Delay   string `yaml:"delay,omitempty"`   // Wait before executing this step (e.g. "5s", "500ms")
 	Timeout string `yaml:"timeout,omitempty"` // HTTP request timeout (e.g. "4s", "100ms", "1m")
 
 	// Output
-	OutputFile string `yaml:"output_file,omitempty"` // Save response to file (e.g. "./output.json", "./image.png")
+	OutputFile   string `yaml:"output_file,omitempty"`   // Save response to file (e.g. "./output.json", "./image.png")
+	OutputResume bool   `yaml:"output_resume,omitempty"` // Resume a partial output_file with a Range request
+	OutputSHA256 string `yaml:"output_sha256,omitempty"` // Verify output_file against this hex SHA-256 digest
+
+	// ResponseEncoding wraps the response bytes as a hex or base64 string so binary bodies can be asserted
+	ResponseEncoding string `yaml:"response_encoding,omitempty"`
+
+	// Cache stores ETag/Last-Modified and revalidates with conditional requests on later runs
+	Cache bool `yaml:"cache,omitempty"`
 
 	// Expect defines assertions to run after the request
 	Expect Expectation `yaml:"expect,omitempty"`
 
 	// Chain allows executing multiple dependent requests
 	Chain []ChainStep `yaml:"chain,omitempty"`
+
+	// Parallel runs up to this many independent chain steps at once (default 1: in order)
+	Parallel int `yaml:"parallel,omitempty"`
+
+	// ChainOutputs names values, usually step references, reported as the chain's result
+	ChainOutputs map[string]string `yaml:"chain_outputs,omitempty"`
+
+	// Finally holds teardown steps that run in order once the chain ends, even after failures
+	Finally []ChainStep `yaml:"finally,omitempty"`
+
+	// ChainTimeout bounds the whole chain (e.g. "2m"); finally steps still run after it expires
+	ChainTimeout string `yaml:"chain_timeout,omitempty"`
+
+	// Matrix runs the request or chain once per row: a list of rows, or a CSV, JSON or
+	// JSONL file of rows relative to the config file. Row fields are read as ${field}.
+	Matrix any `yaml:"matrix,omitempty"`
 }
 
 // ChainStep represents a single step in a request chain.
 // It embeds ConfigV1 so all config fields are available as overrides.
 type ChainStep struct {
-	Name     string           `yaml:"name"` // Required: unique step identifier
-	ConfigV1 `yaml:",inline"` // All ConfigV1 fields available as overrides
+	Name      string            `yaml:"name"`                 // Required: unique step identifier
+	DependsOn []string          `yaml:"depends_on,omitempty"` // Earlier steps to wait for, besides those referenced
+	When      string            `yaml:"when,omitempty"`       // jq condition over earlier steps; the step is skipped if false
+	SkipIf    string            `yaml:"skip_if,omitempty"`    // jq condition over earlier steps; the step is skipped if true
+	Foreach   any               `yaml:"foreach,omitempty"`    // jq expression over earlier steps, or a literal list; the step runs once per item
+	WaitUntil *WaitUntil        `yaml:"wait_until,omitempty"` // Re-run the step until a condition on its response holds
+	Capture   map[string]string `yaml:"capture,omitempty"`    // Named jq expressions over the response, referenced as ${step.name}
+	Call      string            `yaml:"call,omitempty"`       // Another yapi file to run as this step, relative to this one
+	With      map[string]string `yaml:"with,omitempty"`       // Parameters for the called file, which reads them as ${NAME}
+	// ContinueOnError lets the chain go on, and succeed, when this step fails
+	ContinueOnError bool             `yaml:"continue_on_error,omitempty"`
+	ConfigV1        `yaml:",inline"` // All ConfigV1 fields available as overrides
 }
 
 // Merge creates a full ConfigV1 by applying step overrides to the base config.
@@ -116,6 +192,10 @@ type ChainStep struct {
 func (c *ConfigV1) Merge(step ChainStep) ConfigV1 {
 	m := *c
 	m.Chain = nil
+	m.ChainOutputs = nil
+	m.Finally = nil
+	m.Matrix = nil
+	m.ChainTimeout = ""
 	m.Expect = step.Expect
 
 	// Scalar overrides using Coalesce
@@ -135,6 +215,31 @@ func (c *ConfigV1) Merge(step ChainStep) ConfigV1 {
 	m.Delay = utils.Coalesce(step.Delay, c.Delay)
 	m.Timeout = utils.Coalesce(step.Timeout, c.Timeout)
 	m.OutputFile = utils.Coalesce(step.OutputFile, c.OutputFile)
+	m.OutputSHA256 = utils.Coalesce(step.OutputSHA256, c.OutputSHA256)
+	m.ResponseEncoding = utils.Coalesce(step.ResponseEncoding, c.ResponseEncoding)
+	m.Schema = utils.Coalesce(step.Schema, c.Schema)
+	m.OperationName = utils.Coalesce(step.OperationName, c.OperationName)
+
+	if step.Auth.Type != "" {
+		m.Auth = step.Auth
+	}
+
+	m.TLS.ServerName = utils.Coalesce(step.TLS.ServerName, c.TLS.ServerName)
+	m.TLS.CACert = utils.Coalesce(step.TLS.CACert, c.TLS.CACert)
+	m.TLS.ClientCert = utils.Coalesce(step.TLS.ClientCert, c.TLS.ClientCert)
+	m.TLS.ClientKey = utils.Coalesce(step.TLS.ClientKey, c.TLS.ClientKey)
+	if step.TLS.ALPN != nil {
+		m.TLS.ALPN = step.TLS.ALPN
+	}
+	if step.Conversation != nil {
+		m.Conversation = step.Conversation
+	}
+	if step.Batch != nil {
+		m.Batch = step.Batch
+	}
+	if step.Framing.Type != "" {
+		m.Framing = step.Framing
+	}
 
 	// Bool/Int overrides
 	if step.Insecure {
@@ -146,16 +251,32 @@ func (c *ConfigV1) Merge(step ChainStep) ConfigV1 {
 	if step.CloseAfterSend {
 		m.CloseAfterSend = true
 	}
+	if step.OutputResume {
+		m.OutputResume = true
+	}
+	if step.Cache {
+		m.Cache = true
+	}
 	if step.ReadTimeout != 0 {
 		m.ReadTimeout = step.ReadTimeout
 	}
 	if step.IdleTimeout != 0 {
 		m.IdleTimeout = step.IdleTimeout
 	}
+	if step.Datagrams != 0 {
+		m.Datagrams = step.Datagrams
+	}
+	if step.Events != 0 {
+		m.Events = step.Events
+	}
+	if step.PersistedQuery {
+		m.PersistedQuery = true
+	}
 
 	// Generic map merging
 	m.Headers = utils.MergeMaps(c.Headers, step.Headers)
 	m.Query = utils.MergeMaps(c.Query, step.Query)
+	m.Uploads = utils.MergeMaps(c.Uploads, step.Uploads)
 
 	// Deep clone Body/Variables from c, then override if step has values
 	m.Body = utils.DeepCloneMap(c.Body)
@@ -193,6 +314,31 @@ func (c *ConfigV1) MergeWithDefaults(defaults ConfigV1) ConfigV1 {
 	m.Delay = utils.Coalesce(c.Delay, defaults.Delay)
 	m.Timeout = utils.Coalesce(c.Timeout, defaults.Timeout)
 	m.OutputFile = utils.Coalesce(c.OutputFile, defaults.OutputFile)
+	m.OutputSHA256 = utils.Coalesce(c.OutputSHA256, defaults.OutputSHA256)
+	m.ResponseEncoding = utils.Coalesce(c.ResponseEncoding, defaults.ResponseEncoding)
+	m.Schema = utils.Coalesce(c.Schema, defaults.Schema)
+	m.OperationName = utils.Coalesce(c.OperationName, defaults.OperationName)
+
+	if c.Auth.Type != "" {
+		m.Auth = c.Auth
+	}
+
+	m.TLS.ServerName = utils.Coalesce(c.TLS.ServerName, defaults.TLS.ServerName)
+	m.TLS.CACert = utils.Coalesce(c.TLS.CACert, defaults.TLS.CACert)
+	m.TLS.ClientCert = utils.Coalesce(c.TLS.ClientCert, defaults.TLS.ClientCert)
+	m.TLS.ClientKey = utils.Coalesce(c.TLS.ClientKey, defaults.TLS.ClientKey)
+	if c.TLS.ALPN != nil {
+		m.TLS.ALPN = c.TLS.ALPN
+	}
+	if c.Conversation != nil {
+		m.Conversation = c.Conversation
+	}
+	if c.Batch != nil {
+		m.Batch = c.Batch
+	}
+	if c.Framing.Type != "" {
+		m.Framing = c.Framing
+	}
 
 	// Bool/Int overrides - file values take precedence
 	if c.Insecure {
@@ -204,16 +350,35 @@ func (c *ConfigV1) MergeWithDefaults(defaults ConfigV1) ConfigV1 {
 	if c.CloseAfterSend {
 		m.CloseAfterSend = true
 	}
+	if c.OutputResume {
+		m.OutputResume = true
+	}
+	if c.Cache {
+		m.Cache = true
+	}
 	if c.ReadTimeout != 0 {
 		m.ReadTimeout = c.ReadTimeout
 	}
 	if c.IdleTimeout != 0 {
 		m.IdleTimeout = c.IdleTimeout
 	}
+	if c.Datagrams != 0 {
+		m.Datagrams = c.Datagrams
+	}
+	if c.Events != 0 {
+		m.Events = c.Events
+	}
+	if c.Parallel != 0 {
+		m.Parallel = c.Parallel
+	}
+	if c.PersistedQuery {
+		m.PersistedQuery = true
+	}
 
 	// Map merging - file values override defaults
 	m.Headers = utils.MergeMaps(defaults.Headers, c.Headers)
 	m.Query = utils.MergeMaps(defaults.Query, c.Query)
+	m.Uploads = utils.MergeMaps(defaults.Uploads, c.Uploads)
 
 	// Body/Variables - file values override if present
 	m.Body = utils.DeepCloneMap(defaults.Body)
@@ -237,17 +402,22 @@ func (c *ConfigV1) MergeWithDefaults(defaults ConfigV1) ConfigV1 {
 type Expectation struct {
 	Status any          `yaml:"status,omitempty"` // int or []int
 	Assert AssertionSet `yaml:"assert,omitempty"` // JQ expressions that must evaluate to true
+
+	// AllowErrors accepts GraphQL responses whose `errors` array is non-empty
+	AllowErrors bool `yaml:"allow_errors,omitempty"`
 }
 
 // AssertionSet represents assertions that can be either a flat list or grouped by context
 type AssertionSet struct {
 	Body    []string // Assertions on response body (default context)
 	Headers []string // Assertions on response headers
+	Cache   []string // Assertions on cache semantics (validators, Cache-Control, revalidation)
+	TLS     []string // Assertions on the negotiated TLS session and peer certificates
 }
 
 // UnmarshalYAML implements custom unmarshaling for AssertionSet to support both:
 // - Flat array: assert: [...]  (all treated as body assertions)
-// - Grouped map: assert: { headers: [...], body: [...] }
+// - Grouped map: assert: { headers: [...], body: [...], cache: [...], tls: [...] }
 func (a *AssertionSet) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// Try to unmarshal as array first (backward compatible)
 	var flatList []string
@@ -264,6 +434,8 @@ func (a *AssertionSet) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 	a.Headers = grouped["headers"]
 	a.Body = grouped["body"]
+	a.Cache = grouped["cache"]
+	a.TLS = grouped["tls"]
 	return nil
 }
 
@@ -316,12 +488,39 @@ func (c *ConfigV1) toDomainInternal() (*domain.Request, error) {
 
 // expandEnvVars expands environment variables in all string fields using reflection
 func (c *ConfigV1) expandEnvVars() {
-	vars.ExpandAll(c, vars.EnvResolver)
+	c.ExpandWithResolver(vars.EnvResolver)
 }
 
 // ExpandWithResolver expands environment variables using a custom resolver
 func (c *ConfigV1) ExpandWithResolver(resolver vars.Resolver) {
+	query := c.Graphql
 	vars.ExpandAll(c, resolver)
+	c.Graphql = expandGraphqlQuery(query, resolver)
+	c.expandConversation(resolver)
+	c.expandBatch(resolver)
+}
+
+// graphqlVariableDef matches the "$name:" definitions in a GraphQL operation's
+// variable list. Group 1: the variable name.
+var graphqlVariableDef = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)\s*:`)
+
+// expandGraphqlQuery expands variables in a GraphQL document, leaving alone the
+// $name references to variables the operation declares itself.
+func expandGraphqlQuery(query string, resolver vars.Resolver) string {
+	declared := map[string]bool{}
+	for _, m := range graphqlVariableDef.FindAllStringSubmatch(query, -1) {
+		declared[m[1]] = true
+	}
+	expanded, err := vars.ExpandString(query, func(key string) (string, error) {
+		if declared[key] {
+			return "$" + key, nil
+		}
+		return resolver(key)
+	})
+	if err != nil {
+		return query
+	}
+	return expanded
 }
 
 // setDefaults applies default values for Method
@@ -442,6 +641,23 @@ func (c *ConfigV1) enrichMetadata(req *domain.Request) error {
 		req.Metadata["read_timeout"] = fmt.Sprintf("%d", c.ReadTimeout)
 		req.Metadata["idle_timeout"] = fmt.Sprintf("%d", c.IdleTimeout)
 		req.Metadata["close_after_send"] = fmt.Sprintf("%t", c.CloseAfterSend)
+		if domain.IsTLSSocketURL(c.URL) {
+			c.TLS.enrichMetadata(req)
+		}
+		if len(c.Conversation) > 0 {
+			if err := c.enrichConversation(req); err != nil {
+				return err
+			}
+		}
+		if c.Framing.Type != "" {
+			c.Framing.enrichMetadata(req)
+		}
+	case constants.TransportUDP:
+		req.Metadata["data"] = c.Data
+		req.Metadata["encoding"] = c.Encoding
+		req.Metadata["read_timeout"] = fmt.Sprintf("%d", c.ReadTimeout)
+		req.Metadata["close_after_send"] = fmt.Sprintf("%t", c.CloseAfterSend)
+		req.Metadata["datagrams"] = fmt.Sprintf("%d", c.Datagrams)
 	}
 
 	if c.JQFilter != "" {
@@ -450,14 +666,56 @@ func (c *ConfigV1) enrichMetadata(req *domain.Request) error {
 
 	if c.OutputFile != "" {
 		req.Metadata["output_file"] = c.OutputFile
+		req.Metadata["output_resume"] = fmt.Sprintf("%t", c.OutputResume)
+		if c.OutputSHA256 != "" {
+			req.Metadata["output_sha256"] = c.OutputSHA256
+		}
+	}
+
+	if c.ResponseEncoding != "" {
+		req.Metadata["response_encoding"] = c.ResponseEncoding
 	}
 
 	if c.Timeout != "" {
 		req.Metadata["timeout"] = c.Timeout
 	}
 
+	if c.Cache {
+		req.Metadata["cache"] = "true"
+	}
+
+	if c.Auth.Type != "" {
+		c.Auth.enrichMetadata(req)
+	}
+
 	if c.Graphql != "" {
 		req.Metadata["graphql_query"] = c.Graphql
+		if c.Schema != "" {
+			req.Metadata["graphql_schema"] = c.Schema
+		}
+		if c.Events != 0 {
+			req.Metadata["graphql_events"] = fmt.Sprintf("%d", c.Events)
+		}
+		if c.PersistedQuery {
+			req.Metadata["graphql_persisted"] = "true"
+		}
+		if c.OperationName != "" {
+			req.Metadata["graphql_operation_name"] = c.OperationName
+		}
+		if len(c.Batch) > 0 {
+			batch, err := c.BatchMetadata()
+			if err != nil {
+				return err
+			}
+			req.Metadata["graphql_batch"] = batch
+		}
+		if len(c.Uploads) > 0 {
+			uploads, err := json.Marshal(c.Uploads)
+			if err != nil {
+				return fmt.Errorf("could not marshal graphql uploads: %w", err)
+			}
+			req.Metadata["graphql_uploads"] = string(uploads)
+		}
 		if c.Variables != nil {
 			vars, err := json.Marshal(c.Variables)
 			if err != nil {
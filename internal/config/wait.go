@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaitUntil re-runs a chain step until a jq condition on its response is true.
+type WaitUntil struct {
+	Condition   string  `yaml:"condition"`              // jq expression over the response body, e.g. .status == "done"
+	Interval    string  `yaml:"interval,omitempty"`     // Wait between attempts (default 1s)
+	Backoff     float64 `yaml:"backoff,omitempty"`      // Multiply the interval by this after each attempt (default 1)
+	MaxInterval string  `yaml:"max_interval,omitempty"` // Upper bound for a backed-off interval
+	Timeout     string  `yaml:"timeout,omitempty"`      // Give up after this long (default 1m)
+}
+
+// Default wait_until timings.
+const (
+	DefaultWaitInterval = time.Second
+	DefaultWaitTimeout  = time.Minute
+)
+
+// Durations parses the interval, max_interval and timeout, applying defaults. A
+// zero max interval means the interval is not capped.
+func (w *WaitUntil) Durations() (interval, maxInterval, timeout time.Duration, err error) {
+	interval, timeout = DefaultWaitInterval, DefaultWaitTimeout
+	for _, f := range []struct {
+		field, value string
+		d            *time.Duration
+	}{
+		{"interval", w.Interval, &interval},
+		{"max_interval", w.MaxInterval, &maxInterval},
+		{"timeout", w.Timeout, &timeout},
+	} {
+		if f.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(f.value)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid %s '%s': %w", f.field, f.value, err)
+		}
+		if parsed <= 0 {
+			return 0, 0, 0, fmt.Errorf("%s must be positive, got '%s'", f.field, f.value)
+		}
+		*f.d = parsed
+	}
+	return interval, maxInterval, timeout, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitUntil_Durations(t *testing.T) {
+	tests := []struct {
+		name                           string
+		wait                           WaitUntil
+		interval, maxInterval, timeout time.Duration
+		wantErr                        bool
+	}{
+		{"defaults", WaitUntil{}, DefaultWaitInterval, 0, DefaultWaitTimeout, false},
+		{"explicit", WaitUntil{Interval: "250ms", MaxInterval: "2s", Timeout: "30s"}, 250 * time.Millisecond, 2 * time.Second, 30 * time.Second, false},
+		{"invalid", WaitUntil{Timeout: "forever"}, 0, 0, 0, true},
+		{"not positive", WaitUntil{Interval: "0s"}, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, maxInterval, timeout, err := tt.wait.Durations()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Durations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if interval != tt.interval || maxInterval != tt.maxInterval || timeout != tt.timeout {
+				t.Errorf("Durations() = %s, %s, %s", interval, maxInterval, timeout)
+			}
+		})
+	}
+}
@@ -19,9 +19,16 @@ const (
 	TransportHTTP    = "http"
 	TransportGRPC    = "grpc"
 	TransportTCP     = "tcp"
+	TransportUDP     = "udp"
 	TransportGraphQL = "graphql"
 )
 
+// Auth types
+const (
+	AuthAWSSigV4 = "aws_sigv4"
+	AuthHMAC     = "hmac"
+)
+
 // ValidHTTPMethods contains all valid HTTP verbs for validation
 var ValidHTTPMethods = map[string]bool{
 	MethodGET:     true,
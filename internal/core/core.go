@@ -3,15 +3,21 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/constants"
 	"yapi.run/cli/internal/executor"
+	"yapi.run/cli/internal/gqlschema"
 	"yapi.run/cli/internal/runner"
 	"yapi.run/cli/internal/validation"
+	"yapi.run/cli/internal/vars"
 )
 
 // RequestHook is called after a request completes with stats about the execution.
@@ -58,45 +64,12 @@ func (e *Engine) RunConfig(
 	path string,
 	opts runner.Options,
 ) *RunConfigResult {
-	// Load project config if available for validation
-	var project *config.ProjectConfigV1
-	if opts.ProjectRoot != "" {
-		var err error
-		project, err = config.LoadProject(opts.ProjectRoot)
-		if err != nil {
-			// If user explicitly requested an environment via --env flag,
-			// they need to know if project loading failed
-			if opts.ProjectEnv != "" {
-				return &RunConfigResult{Error: fmt.Errorf("failed to load project config: %w", err)}
-			}
-			// Otherwise, ignore project load errors during validation - still run the config
-			project = nil
-		}
-	}
-
-	// Analyze with project context if available
-	var analysis *validation.Analysis
-	var err error
-	if project != nil {
-		data, readErr := os.ReadFile(path) // #nosec G304 -- path is validated user-provided config file path
-		if readErr != nil {
-			return &RunConfigResult{Error: readErr}
-		}
-
-		// If a specific environment was requested, temporarily override the default
-		// This ensures the correct environment is used for URL resolution and defaults
-		if opts.ProjectEnv != "" {
-			originalDefault := project.DefaultEnvironment
-			project.DefaultEnvironment = opts.ProjectEnv
-			analysis, err = validation.AnalyzeConfigStringWithProject(string(data), project, opts.ProjectRoot)
-			project.DefaultEnvironment = originalDefault
-		} else {
-			analysis, err = validation.AnalyzeConfigStringWithProject(string(data), project, opts.ProjectRoot)
-		}
-	} else {
-		analysis, err = validation.AnalyzeConfigFile(path)
+	// Relative file paths in the config resolve against its directory
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = path
 	}
 
+	analysis, err := e.Analyze(path, opts)
 	if err != nil {
 		return &RunConfigResult{Error: err}
 	}
@@ -111,31 +84,8 @@ func (e *Engine) RunConfig(
 		return &RunConfigResult{Analysis: analysis}
 	}
 
-	// Re-expand variables if EnvOverrides is provided
-	if len(opts.EnvOverrides) > 0 && analysis.Base != nil {
-		// Create a custom resolver with correct precedence order:
-		// 1. OS environment (highest priority - matches runner/context.go)
-		// 2. Project EnvOverrides
-		// 3. Empty string fallback
-		resolver := func(key string) (string, error) {
-			// 1. Check OS environment first (highest priority)
-			if val, ok := os.LookupEnv(key); ok {
-				return val, nil
-			}
-			// 2. Check project EnvOverrides
-			if val, ok := opts.EnvOverrides[key]; ok {
-				return val, nil
-			}
-			// 3. Return empty string (os.ExpandEnv behavior)
-			return "", nil
-		}
-
-		// Re-convert to domain request using custom resolver
-		req, err := analysis.Base.ToDomainWithResolver(resolver)
-		if err != nil {
-			return &RunConfigResult{Analysis: analysis, Error: err}
-		}
-		analysis.Request = req
+	if err := resolveRequest(analysis, opts); err != nil {
+		return &RunConfigResult{Analysis: analysis, Error: err}
 	}
 
 	if analysis.Request == nil {
@@ -153,9 +103,9 @@ func (e *Engine) RunConfig(
 
 	result, runErr := runner.Run(ctx, exec, analysis.Request, analysis.Warnings, opts)
 
-	// Check expectations if present
+	// Check expectations if present; GraphQL errors are checked even without expectations
 	var expectRes *runner.ExpectationResult
-	if result != nil && (analysis.Expect.Status != nil || len(analysis.Expect.Assert.Body) > 0 || len(analysis.Expect.Assert.Headers) > 0) {
+	if result != nil && (analysis.Expect.Status != nil || len(analysis.Expect.Assert.Body) > 0 || len(analysis.Expect.Assert.Headers) > 0 || len(analysis.Expect.Assert.Cache) > 0 || len(analysis.Expect.Assert.TLS) > 0 || len(result.GraphQLErrors) > 0) {
 		expectRes = runner.CheckExpectationsWithEnv(analysis.Expect, result, opts.EnvOverrides)
 	}
 
@@ -182,6 +132,152 @@ func (e *Engine) RunConfig(
 	return &RunConfigResult{Analysis: analysis, Result: result, ExpectRes: expectRes}
 }
 
+// Analyze loads the project config if available and analyzes the config at path
+// without running it.
+func (e *Engine) Analyze(path string, opts runner.Options) (*validation.Analysis, error) {
+	// Load project config if available for validation
+	var project *config.ProjectConfigV1
+	if opts.ProjectRoot != "" {
+		var err error
+		project, err = config.LoadProject(opts.ProjectRoot)
+		if err != nil {
+			// If user explicitly requested an environment via --env flag,
+			// they need to know if project loading failed
+			if opts.ProjectEnv != "" {
+				return nil, fmt.Errorf("failed to load project config: %w", err)
+			}
+			// Otherwise, ignore project load errors during validation - still run the config
+			project = nil
+		}
+	}
+
+	// Analyze with project context if available
+	if project == nil {
+		return validation.AnalyzeConfigFile(path)
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is validated user-provided config file path
+	if err != nil {
+		return nil, err
+	}
+
+	// If a specific environment was requested, temporarily override the default
+	// This ensures the correct environment is used for URL resolution and defaults
+	if opts.ProjectEnv != "" {
+		originalDefault := project.DefaultEnvironment
+		project.DefaultEnvironment = opts.ProjectEnv
+		defer func() { project.DefaultEnvironment = originalDefault }()
+	}
+	return validation.AnalyzeConfigStringInDir(string(data), project, opts.ProjectRoot, filepath.Dir(path))
+}
+
+// resolveRequest re-expands the request with project variables if EnvOverrides or Vars
+// are provided.
+func resolveRequest(analysis *validation.Analysis, opts runner.Options) error {
+	if (len(opts.EnvOverrides) == 0 && len(opts.Vars) == 0) || analysis.Base == nil {
+		return nil
+	}
+
+	// Create a custom resolver with correct precedence order:
+	// 0. Vars, such as a matrix row
+	// 1. OS environment (matches runner/context.go)
+	// 2. Project EnvOverrides
+	// 3. Empty string fallback
+	resolver := func(key string) (string, error) {
+		// 0. Check vars, which are set for this run only
+		if val, ok := opts.Vars[key]; ok {
+			return val, nil
+		}
+		// 1. Check OS environment
+		if val, ok := os.LookupEnv(key); ok {
+			return val, nil
+		}
+		// 2. Check project EnvOverrides
+		if val, ok := opts.EnvOverrides[key]; ok {
+			return val, nil
+		}
+		// 3. Return empty string (os.ExpandEnv behavior)
+		return "", nil
+	}
+
+	// Re-convert to domain request using custom resolver
+	req, err := analysis.Base.ToDomainWithResolver(resolver)
+	if err != nil {
+		return err
+	}
+	analysis.Request = req
+
+	// A status given as a reference, e.g. ${status} from a matrix row, resolves the same way
+	if s, ok := analysis.Base.Expect.Status.(string); ok {
+		status, err := vars.ExpandString(s, resolver)
+		if err != nil {
+			return err
+		}
+		analysis.Expect.Status = status
+	}
+	return nil
+}
+
+// IntrospectResult is a GraphQL schema fetched by Introspect.
+type IntrospectResult struct {
+	URL string // Endpoint the schema was fetched from
+	SDL string // Schema rendered as SDL
+}
+
+// Introspect sends the standard introspection query to the GraphQL endpoint of the
+// config at path, with the config's headers and environment, and returns the schema.
+func (e *Engine) Introspect(ctx context.Context, path string, opts runner.Options) (*IntrospectResult, error) {
+	analysis, err := e.Analyze(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(analysis.Chain) > 0 {
+		return nil, errors.New("introspection requires a single GraphQL request, not a chain")
+	}
+	if err := resolveRequest(analysis, opts); err != nil {
+		return nil, err
+	}
+	// Diagnostics are not fatal here: a stale schema is what introspection fixes
+	req := analysis.Request
+	if req == nil {
+		return nil, &validation.Error{Diagnostics: analysis.Diagnostics}
+	}
+	if req.Metadata["transport"] != constants.TransportGraphQL {
+		return nil, fmt.Errorf("%s is not a GraphQL request", path)
+	}
+
+	// Only the query changes; headers, auth and TLS settings still apply. Every other
+	// GraphQL setting (batch, uploads, persisted queries, subscriptions...) would
+	// change how the introspection query is sent.
+	for key := range req.Metadata {
+		if strings.HasPrefix(key, "graphql_") {
+			delete(req.Metadata, key)
+		}
+	}
+	req.Metadata["graphql_query"] = gqlschema.IntrospectionQuery
+	for _, key := range []string{"jq_filter", "output_file", "response_encoding", "cache"} {
+		delete(req.Metadata, key)
+	}
+
+	exec, err := e.factory.Create(constants.TransportGraphQL)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.Run(ctx, exec, req, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	if result.StatusCode >= 400 {
+		return nil, fmt.Errorf("introspection request to %s failed with status %d", req.URL, result.StatusCode)
+	}
+
+	sdl, err := gqlschema.SDLFromIntrospection([]byte(result.Body))
+	if err != nil {
+		return nil, err
+	}
+	return &IntrospectResult{URL: req.URL, SDL: sdl}, nil
+}
+
 // RunChain executes a chain configuration
 func (e *Engine) RunChain(
 	ctx context.Context,
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"yapi.run/cli/internal/gqlschema"
+	"yapi.run/cli/internal/runner"
+)
+
+func TestIntrospect_IgnoresOperationSettings(t *testing.T) {
+	schema, err := gqlschema.FromSDL("type Query { user(id: ID!): User }\ntype User { name: String }")
+	if err != nil {
+		t.Fatalf("FromSDL failed: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A batch or an operationName from the config would break introspection
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "expected a single JSON operation: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body["query"] != gqlschema.IntrospectionQuery || body["operationName"] != nil || body["variables"] != nil {
+			http.Error(w, "unexpected operation", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(graphql.Do(graphql.Params{Schema: *schema, RequestString: gqlschema.IntrospectionQuery}))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "users.yapi.yml")
+	cfg := `yapi: v1
+url: ` + srv.URL + `
+graphql: |
+  query GetUser($id: ID!) { user(id: $id) { name } }
+operation_name: GetUser
+batch:
+  - operation_name: GetUser
+    variables: { id: "1" }
+  - name: admin
+    operation_name: GetUser
+    variables: { id: "2" }
+`
+	if err := os.WriteFile(path, []byte(cfg), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	res, err := NewEngine(srv.Client()).Introspect(context.Background(), path, runner.Options{})
+	if err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+	if !strings.Contains(res.SDL, "user(id: ID!): User") {
+		t.Errorf("SDL missing the user field:\n%s", res.SDL)
+	}
+}
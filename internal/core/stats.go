@@ -26,16 +26,16 @@ func ExtractConfigStats(analysis *validation.Analysis) map[string]any {
 	stats["chain_step_count"] = len(analysis.Chain)
 
 	// Expectations
-	hasExpectations := analysis.Expect.Status != nil || len(analysis.Expect.Assert.Body) > 0 || len(analysis.Expect.Assert.Headers) > 0
-	assertionCount := len(analysis.Expect.Assert.Body) + len(analysis.Expect.Assert.Headers)
+	hasExpectations := analysis.Expect.Status != nil || len(analysis.Expect.Assert.Body) > 0 || len(analysis.Expect.Assert.Headers) > 0 || len(analysis.Expect.Assert.Cache) > 0 || len(analysis.Expect.Assert.TLS) > 0
+	assertionCount := len(analysis.Expect.Assert.Body) + len(analysis.Expect.Assert.Headers) + len(analysis.Expect.Assert.Cache) + len(analysis.Expect.Assert.TLS)
 	hasStatusCheck := analysis.Expect.Status != nil
 
 	// Count expectations across chain steps too
 	for _, step := range analysis.Chain {
-		if step.Expect.Status != nil || len(step.Expect.Assert.Body) > 0 || len(step.Expect.Assert.Headers) > 0 {
+		if step.Expect.Status != nil || len(step.Expect.Assert.Body) > 0 || len(step.Expect.Assert.Headers) > 0 || len(step.Expect.Assert.Cache) > 0 || len(step.Expect.Assert.TLS) > 0 {
 			hasExpectations = true
 		}
-		assertionCount += len(step.Expect.Assert.Body) + len(step.Expect.Assert.Headers)
+		assertionCount += len(step.Expect.Assert.Body) + len(step.Expect.Assert.Headers) + len(step.Expect.Assert.Cache) + len(step.Expect.Assert.TLS)
 		if step.Expect.Status != nil {
 			hasStatusCheck = true
 		}
@@ -74,9 +74,12 @@ func detectTransport(c *config.ConfigV1) string {
 	if len(url) >= 7 && (url[:7] == "grpc://" || (len(url) >= 8 && url[:8] == "grpcs://")) {
 		return "grpc"
 	}
-	if len(url) >= 6 && url[:6] == "tcp://" {
+	if len(url) >= 6 && (url[:6] == "tcp://" || url[:6] == "tls://" || (len(url) >= 7 && url[:7] == "tcps://")) {
 		return "tcp"
 	}
+	if len(url) >= 6 && url[:6] == "udp://" {
+		return "udp"
+	}
 	if c.Graphql != "" {
 		return "graphql"
 	}
@@ -29,4 +29,5 @@ type Response struct {
 	Headers    map[string]string
 	Body       io.ReadCloser // Streamable response
 	Duration   time.Duration
+	TLS        *TLSInfo // Negotiated TLS session, nil for plaintext connections
 }
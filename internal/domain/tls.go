@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// TLSInfo describes a negotiated TLS session.
+type TLSInfo struct {
+	Version            string            `json:"version"`             // e.g. "TLS 1.3"
+	CipherSuite        string            `json:"cipher_suite"`        // e.g. "TLS_AES_128_GCM_SHA256"
+	NegotiatedProtocol string            `json:"negotiated_protocol"` // ALPN result, empty if none
+	ServerName         string            `json:"server_name"`         // SNI sent by the client
+	PeerCertificates   []CertificateInfo `json:"peer_certificates"`   // Leaf first
+}
+
+// CertificateInfo summarizes an X.509 certificate presented by the peer.
+type CertificateInfo struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	SerialNumber      string    `json:"serial_number"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	DNSNames          []string  `json:"dns_names,omitempty"`
+	SHA256Fingerprint string    `json:"sha256_fingerprint"`
+}
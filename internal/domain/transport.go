@@ -13,11 +13,20 @@ func DetectTransport(url string, hasGraphQL bool) string {
 	if strings.HasPrefix(urlLower, "grpc://") || strings.HasPrefix(urlLower, "grpcs://") {
 		return constants.TransportGRPC
 	}
-	if strings.HasPrefix(urlLower, "tcp://") {
+	if strings.HasPrefix(urlLower, "tcp://") || IsTLSSocketURL(url) {
 		return constants.TransportTCP
 	}
+	if strings.HasPrefix(urlLower, "udp://") {
+		return constants.TransportUDP
+	}
 	if hasGraphQL {
 		return constants.TransportGraphQL
 	}
 	return constants.TransportHTTP
 }
+
+// IsTLSSocketURL reports whether url uses a TLS-wrapped TCP scheme (tls:// or tcps://).
+func IsTLSSocketURL(url string) bool {
+	urlLower := strings.ToLower(url)
+	return strings.HasPrefix(urlLower, "tls://") || strings.HasPrefix(urlLower, "tcps://")
+}
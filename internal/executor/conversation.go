@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"time"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// defaultReadUntilTimeout bounds reads that wait for a conversation expectation
+// or a response frame when no timeout is configured.
+const defaultReadUntilTimeout = 5 * time.Second
+
+// conversationStep mirrors config.ConversationStep as serialized in request metadata.
+type conversationStep struct {
+	Send          string `json:"send,omitempty"`
+	ExpectRegex   string `json:"expect_regex,omitempty"`
+	ExpectLiteral string `json:"expect_literal,omitempty"`
+	ExpectBytes   int    `json:"expect_bytes,omitempty"`
+	Timeout       string `json:"timeout,omitempty"`
+}
+
+// ConversationExchange records one step of a scripted TCP conversation.
+type ConversationExchange struct {
+	Step       int      `json:"step"`
+	Sent       string   `json:"sent"`             // Data written, in the request encoding
+	Received   string   `json:"received"`         // Data read up to and including the match, in the request encoding
+	Groups     []string `json:"groups,omitempty"` // Regex capture groups, if any
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// ConversationResult is the JSON response body produced by a TCP conversation.
+type ConversationResult struct {
+	Count     int                    `json:"count"`
+	Exchanges []ConversationExchange `json:"exchanges"`
+}
+
+// runConversation executes send/expect steps over an established connection.
+// Bytes read past a match are kept for the next step's expectation.
+func runConversation(ctx context.Context, conn net.Conn, script, encoding string, readTimeout int, session *domain.TLSInfo) (*domain.Response, error) {
+	var steps []conversationStep
+	if err := json.Unmarshal([]byte(script), &steps); err != nil {
+		return nil, fmt.Errorf("invalid conversation: %w", err)
+	}
+
+	defaultTimeout := defaultReadUntilTimeout
+	if readTimeout > 0 {
+		defaultTimeout = time.Duration(readTimeout) * time.Second
+	}
+
+	result := ConversationResult{Exchanges: make([]ConversationExchange, 0, len(steps))}
+	var pending []byte
+
+	for i, step := range steps {
+		start := time.Now()
+		exchange := ConversationExchange{Step: i + 1}
+
+		if step.Send != "" {
+			payload, err := decodePayload(step.Send, nil, encoding)
+			if err != nil {
+				return nil, fmt.Errorf("conversation step %d: %w", i+1, err)
+			}
+			if _, err := conn.Write(payload); err != nil {
+				return nil, fmt.Errorf("conversation step %d: failed to write: %w", i+1, err)
+			}
+			exchange.Sent = encodePayload(payload, encoding)
+		}
+
+		match, describe, err := conversationMatcher(step, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("conversation step %d: %w", i+1, err)
+		}
+
+		if match != nil {
+			timeout := defaultTimeout
+			if step.Timeout != "" {
+				if timeout, err = time.ParseDuration(step.Timeout); err != nil {
+					return nil, fmt.Errorf("conversation step %d: invalid timeout '%s': %w", i+1, step.Timeout, err)
+				}
+			}
+			deadline := time.Now().Add(timeout)
+			if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+				deadline = ctxDeadline
+			}
+
+			var received []byte
+			received, pending, exchange.Groups, err = readUntil(conn, pending, deadline, match)
+			if err != nil {
+				return nil, fmt.Errorf("conversation step %d: %s while waiting for %s; received %q", i+1, err, describe, received)
+			}
+			exchange.Received = encodePayload(received, encoding)
+		}
+
+		exchange.DurationMs = time.Since(start).Milliseconds()
+		result.Exchanges = append(result.Exchanges, exchange)
+	}
+	result.Count = len(result.Exchanges)
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	return &domain.Response{
+		StatusCode: 0, // TCP has no status code
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		TLS:        session,
+	}, nil
+}
+
+// matchFunc returns the number of buffered bytes that satisfy an expectation
+// (including everything before the match) and any capture groups, or -1 if not yet satisfied.
+type matchFunc func(buf []byte) (int, []string)
+
+// conversationMatcher builds the matcher for a step, or nil if the step only sends.
+func conversationMatcher(step conversationStep, encoding string) (matchFunc, string, error) {
+	switch {
+	case step.ExpectRegex != "":
+		re, err := regexp.Compile(step.ExpectRegex)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid expect_regex: %w", err)
+		}
+		return func(buf []byte) (int, []string) {
+			loc := re.FindSubmatchIndex(buf)
+			if loc == nil {
+				return -1, nil
+			}
+			var groups []string
+			for g := 1; g < len(loc)/2; g++ {
+				if loc[2*g] >= 0 {
+					groups = append(groups, string(buf[loc[2*g]:loc[2*g+1]]))
+				} else {
+					groups = append(groups, "")
+				}
+			}
+			return loc[1], groups
+		}, fmt.Sprintf("regex %q", step.ExpectRegex), nil
+	case step.ExpectLiteral != "":
+		literal, err := decodePayload(step.ExpectLiteral, nil, encoding)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid expect_literal: %w", err)
+		}
+		return func(buf []byte) (int, []string) {
+			idx := bytes.Index(buf, literal)
+			if idx < 0 {
+				return -1, nil
+			}
+			return idx + len(literal), nil
+		}, fmt.Sprintf("literal %q", step.ExpectLiteral), nil
+	case step.ExpectBytes > 0:
+		n := step.ExpectBytes
+		return func(buf []byte) (int, []string) {
+			if len(buf) < n {
+				return -1, nil
+			}
+			return n, nil
+		}, fmt.Sprintf("%d bytes", n), nil
+	default:
+		return nil, "", nil
+	}
+}
+
+// readUntil reads from conn until match is satisfied or the deadline passes.
+// It returns the matched bytes, the leftover bytes, and any capture groups.
+func readUntil(conn net.Conn, pending []byte, deadline time.Time, match matchFunc) ([]byte, []byte, []string, error) {
+	buf := pending
+	chunk := make([]byte, 4096)
+	_ = conn.SetReadDeadline(deadline)
+	for {
+		if n, groups := match(buf); n >= 0 {
+			return buf[:n], buf[n:], groups, nil
+		}
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if err != nil {
+			if n, groups := match(buf); n >= 0 {
+				return buf[:n], buf[n:], groups, nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return buf, nil, nil, errors.New("timed out")
+			}
+			if errors.Is(err, io.EOF) {
+				return buf, nil, nil, errors.New("connection closed")
+			}
+			return buf, nil, nil, err
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package executor_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+// startSMTPServer runs a minimal line-based SMTP-like server.
+func startSMTPServer(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("220 mail.test ESMTP ready\r\n"))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				// Multi-line reply written in two segments to exercise buffering
+				_, _ = conn.Write([]byte("250-mail.test\r\n250-SIZE 1000"))
+				_, _ = conn.Write([]byte("0\r\n250 OK\r\n"))
+			case strings.HasPrefix(line, "PING"):
+				_, _ = conn.Write([]byte("+PONG\r\n$5\r\nhello\r\n"))
+			case strings.HasPrefix(line, "QUIT"):
+				_, _ = conn.Write([]byte("221 Bye\r\n"))
+				return
+			}
+		}
+	}()
+	return l.Addr().String()
+}
+
+func runConversation(t *testing.T, yaml string) (*executor.ConversationResult, error) {
+	t.Helper()
+	res, err := config.LoadFromString(yaml)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	resp, err := executor.TCPTransport(context.Background(), res.Request)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var result executor.ConversationResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("response is not valid JSON: %v\n%s", err, body)
+	}
+	return &result, nil
+}
+
+func TestTCPTransport_Conversation(t *testing.T) {
+	addr := startSMTPServer(t)
+
+	result, err := runConversation(t, fmt.Sprintf(`
+yapi: v1
+url: tcp://%s
+conversation:
+  - expect_regex: '^220 (\S+) ESMTP'
+  - send: "EHLO yapi\r\n"
+    expect_regex: '250 OK\r\n'
+    timeout: 1s
+  - send: "PING\r\n"
+    expect_literal: "+PONG\r\n"
+  - expect_bytes: 4
+  - send: "QUIT\r\n"
+    expect_literal: "221"
+`, addr))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if result.Count != 5 {
+		t.Fatalf("Count = %d, want 5", result.Count)
+	}
+	ex := result.Exchanges
+	if ex[0].Received != "220 mail.test ESMTP" || len(ex[0].Groups) != 1 || ex[0].Groups[0] != "mail.test" {
+		t.Errorf("greeting exchange = %+v", ex[0])
+	}
+	if ex[1].Sent != "EHLO yapi\r\n" || ex[1].Received != " ready\r\n250-mail.test\r\n250-SIZE 10000\r\n250 OK\r\n" {
+		t.Errorf("EHLO exchange = %+v", ex[1])
+	}
+	if ex[2].Received != "+PONG\r\n" {
+		t.Errorf("PING exchange = %+v", ex[2])
+	}
+	// Bytes read past the previous match are kept for the next step
+	if ex[3].Sent != "" || ex[3].Received != "$5\r\n" {
+		t.Errorf("byte count exchange = %+v", ex[3])
+	}
+	if ex[4].Received != "hello\r\n221" {
+		t.Errorf("QUIT exchange = %+v", ex[4])
+	}
+}
+
+func TestTCPTransport_ConversationTimeout(t *testing.T) {
+	addr := startSMTPServer(t)
+
+	_, err := runConversation(t, fmt.Sprintf(`
+yapi: v1
+url: tcp://%s
+conversation:
+  - expect_literal: "554"
+    timeout: 200ms
+`, addr))
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	for _, want := range []string{"conversation step 1", "timed out", `literal "554"`, "220 mail.test"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestTCPTransport_ConversationHexEncoding(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte{0x00, 0x02, 0xca, 0xfe})
+	}()
+
+	result, err := runConversation(t, fmt.Sprintf(`
+yapi: v1
+url: tcp://%s
+encoding: hex
+conversation:
+  - send: "0101"
+    expect_literal: "cafe"
+`, l.Addr().String()))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Exchanges[0].Sent != "0101" || result.Exchanges[0].Received != "0002cafe" {
+		t.Errorf("unexpected exchange: %+v", result.Exchanges[0])
+	}
+}
@@ -1,4 +1,4 @@
-// Package executor provides transport implementations for HTTP, gRPC, TCP, and GraphQL.
+// Package executor provides transport implementations for HTTP, gRPC, TCP, UDP, and GraphQL.
 package executor
 
 import (
@@ -43,6 +43,8 @@ func (f *Factory) Create(transport string) (TransportFunc, error) {
 		fn = GRPCTransport
 	case constants.TransportTCP:
 		fn = TCPTransport
+	case constants.TransportUDP:
+		fn = UDPTransport
 	default:
 		return nil, fmt.Errorf("unsupported transport: %s", transport)
 	}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// maxFrameLength guards against allocating huge buffers for a corrupt length prefix.
+const maxFrameLength = 64 << 20
+
+// framer detects a complete frame in buffered data and extracts its payload.
+type framer struct {
+	match    matchFunc                 // Reports the size of the complete frame
+	payload  func(frame []byte) []byte // Strips delimiters or length prefixes
+	check    func(frame []byte) error  // Optional validation of the matched frame
+	describe string
+}
+
+// newFramer builds the framer for a request's framing metadata.
+// Returns nil if no framing is configured.
+func newFramer(meta map[string]string, encoding string) (*framer, error) {
+	switch meta["framing"] {
+	case "":
+		return nil, nil
+
+	case "delimiter":
+		delimiter, err := decodePayload(meta["framing_delimiter"], nil, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid framing delimiter: %w", err)
+		}
+		if len(delimiter) == 0 {
+			return nil, fmt.Errorf("framing type `delimiter` requires `delimiter`")
+		}
+		return &framer{
+			match: func(buf []byte) (int, []string) {
+				idx := bytes.Index(buf, delimiter)
+				if idx < 0 {
+					return -1, nil
+				}
+				return idx + len(delimiter), nil
+			},
+			payload:  func(frame []byte) []byte { return frame[:len(frame)-len(delimiter)] },
+			describe: fmt.Sprintf("delimiter %q", meta["framing_delimiter"]),
+		}, nil
+
+	case "fixed":
+		n, _ := strconv.Atoi(meta["framing_length"])
+		if n <= 0 {
+			return nil, fmt.Errorf("framing type `fixed` requires a positive `length`")
+		}
+		return &framer{
+			match: func(buf []byte) (int, []string) {
+				if len(buf) < n {
+					return -1, nil
+				}
+				return n, nil
+			},
+			payload:  func(frame []byte) []byte { return frame },
+			describe: fmt.Sprintf("%d-byte frame", n),
+		}, nil
+
+	case "length_prefix":
+		size, _ := strconv.Atoi(meta["framing_prefix_bytes"])
+		if size == 0 {
+			size = 4
+		}
+		if size != 1 && size != 2 && size != 4 {
+			return nil, fmt.Errorf("unsupported framing prefix_bytes %d (allowed: 1, 2, 4)", size)
+		}
+		var order binary.ByteOrder
+		switch meta["framing_byte_order"] {
+		case "", "big":
+			order = binary.BigEndian
+		case "little":
+			order = binary.LittleEndian
+		default:
+			return nil, fmt.Errorf("unsupported framing byte_order '%s' (allowed: big, little)", meta["framing_byte_order"])
+		}
+		return &framer{
+			match: func(buf []byte) (int, []string) {
+				if len(buf) < size {
+					return -1, nil
+				}
+				length := frameLength(buf[:size], order)
+				if length > maxFrameLength {
+					return size, nil // Stop at the prefix; check rejects it
+				}
+				if len(buf) < size+length {
+					return -1, nil
+				}
+				return size + length, nil
+			},
+			payload: func(frame []byte) []byte { return frame[size:] },
+			check: func(frame []byte) error {
+				if length := frameLength(frame[:size], order); length > maxFrameLength {
+					return fmt.Errorf("frame length %d exceeds maximum of %d bytes", length, maxFrameLength)
+				}
+				return nil
+			},
+			describe: fmt.Sprintf("%d-byte %s-endian length prefix", size, byteOrderName(order)),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported framing type '%s' (allowed: delimiter, fixed, length_prefix)", meta["framing"])
+	}
+}
+
+// frameLength decodes a 1, 2 or 4 byte length prefix.
+func frameLength(prefix []byte, order binary.ByteOrder) int {
+	switch len(prefix) {
+	case 1:
+		return int(prefix[0])
+	case 2:
+		return int(order.Uint16(prefix))
+	default:
+		return int(order.Uint32(prefix))
+	}
+}
+
+func byteOrderName(order binary.ByteOrder) string {
+	if order == binary.LittleEndian {
+		return "little"
+	}
+	return "big"
+}
@@ -0,0 +1,162 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+// startFramedServer writes the reply in two segments and keeps the connection open,
+// so only framing (not EOF or idle timeout) can end the read quickly.
+func startFramedServer(t *testing.T, reply []byte) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		half := len(reply) / 2
+		_, _ = conn.Write(reply[:half])
+		time.Sleep(20 * time.Millisecond)
+		_, _ = conn.Write(reply[half:])
+		time.Sleep(3 * time.Second)
+	}()
+	return l.Addr().String()
+}
+
+func TestTCPTransport_Framing(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   []byte
+		framing string
+		want    string
+	}{
+		{
+			name:    "delimiter",
+			reply:   []byte("+OK ready\r\nextra"),
+			framing: "type: delimiter\n  delimiter: \"\\r\\n\"",
+			want:    "+OK ready",
+		},
+		{
+			name:    "fixed length",
+			reply:   []byte("0123456789abcdef"),
+			framing: "type: fixed\n  length: 10",
+			want:    "0123456789",
+		},
+		{
+			name:    "1-byte prefix",
+			reply:   append([]byte{5}, "hello world"...),
+			framing: "type: length_prefix\n  prefix_bytes: 1",
+			want:    "hello",
+		},
+		{
+			name:    "2-byte big-endian prefix",
+			reply:   append([]byte{0x00, 0x05}, "hello world"...),
+			framing: "type: length_prefix\n  prefix_bytes: 2",
+			want:    "hello",
+		},
+		{
+			name:    "2-byte little-endian prefix",
+			reply:   append([]byte{0x05, 0x00}, "hello world"...),
+			framing: "type: length_prefix\n  prefix_bytes: 2\n  byte_order: little",
+			want:    "hello",
+		},
+		{
+			name:    "4-byte prefix default",
+			reply:   append([]byte{0x00, 0x00, 0x00, 0x0b}, "hello world!!"...),
+			framing: "type: length_prefix",
+			want:    "hello world",
+		},
+		{
+			name:    "4-byte little-endian prefix",
+			reply:   append([]byte{0x0b, 0x00, 0x00, 0x00}, "hello world!!"...),
+			framing: "type: length_prefix\n  prefix_bytes: 4\n  byte_order: little",
+			want:    "hello world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startFramedServer(t, tt.reply)
+			res, err := config.LoadFromString(fmt.Sprintf(`
+yapi: v1
+url: tcp://%s
+read_timeout: 2
+framing:
+  %s`, addr, tt.framing))
+			if err != nil {
+				t.Fatalf("LoadFromString failed: %v", err)
+			}
+
+			start := time.Now()
+			resp, err := executor.TCPTransport(context.Background(), res.Request)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("framed read took %s, expected it to return as soon as the frame arrived", elapsed)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestTCPTransport_FramingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   []byte
+		framing string
+		wantErr string
+	}{
+		{
+			name:    "incomplete frame",
+			reply:   append([]byte{0x00, 0x10}, "short"...),
+			framing: "type: length_prefix\n  prefix_bytes: 2",
+			wantErr: "timed out while waiting for 2-byte big-endian length prefix",
+		},
+		{
+			name:    "oversized frame",
+			reply:   []byte{0x7f, 0xff, 0xff, 0xff, 0x00},
+			framing: "type: length_prefix",
+			wantErr: "exceeds maximum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startFramedServer(t, tt.reply)
+			res, err := config.LoadFromString(fmt.Sprintf(`
+yapi: v1
+url: tcp://%s
+read_timeout: 1
+framing:
+  %s`, addr, tt.framing))
+			if err != nil {
+				t.Fatalf("LoadFromString failed: %v", err)
+			}
+
+			_, err = executor.TCPTransport(context.Background(), res.Request)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
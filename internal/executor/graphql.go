@@ -1,28 +1,46 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
 	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/gqlschema"
 )
 
 // graphqlPayload represents the standard GraphQL JSON envelope
 type graphqlPayload struct {
-	Query     string         `json:"query"`
-	Variables map[string]any `json:"variables,omitempty"`
+	Query         string         `json:"query,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
 }
 
-// GraphQLTransport returns a transport function for GraphQL requests.
+// graphqlBatchEntry is an operation of a batched request (see config.GraphQLBatchEntry).
+type graphqlBatchEntry struct {
+	Name          string         `json:"name"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLTransport returns a transport function for GraphQL requests. Queries and
+// mutations are POSTed, as multipart requests when files are uploaded, as persisted
+// query hashes when enabled, or as an array when batched; subscriptions run over
+// graphql-transport-ws.
 func GraphQLTransport(client HTTPClient) TransportFunc {
 	httpFn := HTTPTransport(client)
 
 	return func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
 		// Construct the GraphQL payload
 		payload := graphqlPayload{
-			Query: req.Metadata["graphql_query"],
+			Query:         req.Metadata["graphql_query"],
+			OperationName: req.Metadata["graphql_operation_name"],
 		}
 		if vars, ok := req.Metadata["graphql_variables"]; ok && vars != "" {
 			if err := json.Unmarshal([]byte(vars), &payload.Variables); err != nil {
@@ -30,25 +48,191 @@ func GraphQLTransport(client HTTPClient) TransportFunc {
 			}
 		}
 
-		// Marshal to JSON
-		jsonBytes, err := json.Marshal(payload)
+		// Subscriptions stream events over a WebSocket instead of a single POST
+		if isWebSocketURL(req.URL) || gqlschema.OperationType(payload.Query, payload.OperationName) == "subscription" {
+			return graphqlSubscribe(ctx, req, payload)
+		}
+
+		if raw := req.Metadata["graphql_batch"]; raw != "" {
+			return graphqlBatch(ctx, httpFn, req, payload.Query, raw)
+		}
+
+		var uploads map[string]string
+		if raw := req.Metadata["graphql_uploads"]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &uploads); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal graphql uploads: %w", err)
+			}
+		}
+
+		post := func(payload graphqlPayload) (*domain.Response, error) {
+			headers := make(map[string]string, len(req.Headers)+1)
+			for k, v := range req.Headers {
+				headers[k] = v
+			}
+
+			var body io.Reader
+			if len(uploads) > 0 {
+				var contentType string
+				var err error
+				if body, contentType, err = graphqlMultipartBody(payload, uploads); err != nil {
+					return nil, err
+				}
+				headers["Content-Type"] = contentType
+				// Apollo Server rejects multipart requests without a preflight header as CSRF
+				if !hasHeader(headers, "Apollo-Require-Preflight") && !hasHeader(headers, "X-Apollo-Operation-Name") {
+					headers["Apollo-Require-Preflight"] = "true"
+				}
+			} else {
+				jsonBytes, err := json.Marshal(payload)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal graphql payload: %w", err)
+				}
+				body = bytes.NewReader(jsonBytes)
+				headers["Content-Type"] = "application/json"
+			}
+
+			return httpFn(ctx, &domain.Request{
+				URL:      req.URL,
+				Method:   "POST",
+				Headers:  headers,
+				Body:     body,
+				Metadata: req.Metadata, // Preserve metadata (including timeout)
+			})
+		}
+
+		if req.Metadata["graphql_persisted"] != "true" {
+			return post(payload)
+		}
+
+		// Automatic Persisted Queries: send only the query hash, and the full query
+		// if the server has not seen it yet
+		hash := sha256.Sum256([]byte(payload.Query))
+		payload.Extensions = map[string]any{
+			"persistedQuery": map[string]any{"version": 1, "sha256Hash": hex.EncodeToString(hash[:])},
+		}
+		query := payload.Query
+		payload.Query = ""
+		resp, err := post(payload)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal graphql payload: %w", err)
+			return nil, err
+		}
+		missing, err := persistedQueryMissing(resp)
+		if err != nil || !missing {
+			return resp, err
 		}
 
-		// Create a new request for HTTP execution
-		httpReq := &domain.Request{
-			URL:      req.URL,
-			Method:   "POST",
-			Headers:  req.Headers,
-			Body:     strings.NewReader(string(jsonBytes)),
-			Metadata: req.Metadata, // Preserve metadata (including timeout)
+		payload.Query = query
+		full, err := post(payload)
+		if err != nil {
+			return nil, err
 		}
-		if httpReq.Headers == nil {
-			httpReq.Headers = make(map[string]string)
+		full.Duration += resp.Duration
+		return full, nil
+	}
+}
+
+// graphqlBatch POSTs the batch operations as a JSON array and maps the array of
+// results back to an object keyed by each operation's name, so assertions and chain
+// references can address them as .GetUser.data. A response that is not an array
+// (e.g. a server rejecting batches) is returned unchanged.
+func graphqlBatch(ctx context.Context, httpFn TransportFunc, req *domain.Request, query, raw string) (*domain.Response, error) {
+	var entries []graphqlBatchEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graphql batch: %w", err)
+	}
+	payloads := make([]graphqlPayload, len(entries))
+	for i, entry := range entries {
+		payloads[i] = graphqlPayload{Query: query, OperationName: entry.OperationName, Variables: entry.Variables}
+	}
+	jsonBytes, err := json.Marshal(payloads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal graphql batch: %w", err)
+	}
+
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	headers["Content-Type"] = "application/json"
+	resp, err := httpFn(ctx, &domain.Request{
+		URL:      req.URL,
+		Method:   "POST",
+		Headers:  headers,
+		Body:     bytes.NewReader(jsonBytes),
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var results []json.RawMessage
+	if json.Unmarshal(body, &results) != nil {
+		return resp, nil
+	}
+	// Build the object by hand to keep the results in batch order
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, result := range results {
+		if i >= len(entries) {
+			break
+		}
+		if i > 0 {
+			buf.WriteByte(',')
 		}
-		httpReq.Headers["Content-Type"] = "application/json"
+		name, _ := json.Marshal(entries[i].Name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(result)
+	}
+	buf.WriteByte('}')
+	resp.Body = io.NopCloser(&buf)
+	return resp, nil
+}
+
+// persistedQueryMissing reports whether the server answered a persisted query hash
+// with PersistedQueryNotFound (or does not support persisted queries at all). The
+// body is buffered so resp can still be returned as is.
+func persistedQueryMissing(resp *domain.Response) (bool, error) {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
 
-		return httpFn(ctx, httpReq)
+	var result struct {
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false, nil
+	}
+	for _, e := range result.Errors {
+		switch {
+		case e.Message == "PersistedQueryNotFound", e.Extensions.Code == "PERSISTED_QUERY_NOT_FOUND",
+			e.Message == "PersistedQueryNotSupported", e.Extensions.Code == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
 	}
+	return false
 }
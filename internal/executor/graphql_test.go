@@ -0,0 +1,187 @@
+package executor_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+func runGraphQL(t *testing.T, yaml string) string {
+	t.Helper()
+	res, err := config.LoadFromString(yaml)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	resp, err := executor.GraphQLTransport(&http.Client{})(context.Background(), res.Request)
+	if err != nil {
+		t.Fatalf("GraphQLTransport failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	return string(body)
+}
+
+func TestGraphQLTransport_MultipartUpload(t *testing.T) {
+	dir := t.TempDir()
+	avatar := filepath.Join(dir, "avatar.png")
+	doc := filepath.Join(dir, "notes.json")
+	if err := os.WriteFile(avatar, []byte("PNGDATA"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(doc, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var operations, fileMap string
+	files := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Apollo-Require-Preflight") == "" {
+			t.Error("expected an Apollo-Require-Preflight header")
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("not a multipart request: %v", err)
+			return
+		}
+		operations = r.FormValue("operations")
+		fileMap = r.FormValue("map")
+		for field, headers := range r.MultipartForm.File {
+			f, _ := headers[0].Open()
+			data, _ := io.ReadAll(f)
+			files[field] = headers[0].Filename + ":" + headers[0].Header.Get("Content-Type") + ":" + string(data)
+		}
+		_, _ = w.Write([]byte(`{"data":{"upload":true}}`))
+	}))
+	defer srv.Close()
+
+	runGraphQL(t, `yapi: v1
+url: `+srv.URL+`
+graphql: |
+  mutation($input: ProfileInput!, $docs: [Upload!]!) { upload(input: $input, docs: $docs) }
+variables:
+  input:
+    name: Ada
+uploads:
+  input.avatar: `+avatar+`
+  docs.0: `+doc+`
+`)
+
+	if operations != `{"query":"mutation($input: ProfileInput!, $docs: [Upload!]!) { upload(input: $input, docs: $docs) }\n","variables":{"docs":[null],"input":{"avatar":null,"name":"Ada"}}}` {
+		t.Errorf("unexpected operations: %s", operations)
+	}
+	if fileMap != `{"0":["variables.docs.0"],"1":["variables.input.avatar"]}` {
+		t.Errorf("unexpected map: %s", fileMap)
+	}
+	if files["0"] != "notes.json:application/json:{}" || files["1"] != "avatar.png:image/png:PNGDATA" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestGraphQLTransport_PersistedQuery(t *testing.T) {
+	query := "{ now }"
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		known    bool
+		requests int
+	}{
+		{"hash known", true, 1},
+		{"falls back to the full query", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payloads []map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload map[string]any
+				_ = json.NewDecoder(r.Body).Decode(&payload)
+				payloads = append(payloads, payload)
+				if payload["query"] == nil && !tt.known {
+					_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"data":{"now":"2024-01-01"}}`))
+			}))
+			defer srv.Close()
+
+			body := runGraphQL(t, "yapi: v1\nurl: "+srv.URL+"\ngraphql: '"+query+"'\npersisted_query: true\n")
+			if body != `{"data":{"now":"2024-01-01"}}` {
+				t.Errorf("body = %s", body)
+			}
+			if len(payloads) != tt.requests {
+				t.Fatalf("got %d requests, want %d", len(payloads), tt.requests)
+			}
+			first := payloads[0]
+			if first["query"] != nil {
+				t.Errorf("first request should only send the hash, got query %v", first["query"])
+			}
+			pq := first["extensions"].(map[string]any)["persistedQuery"].(map[string]any)
+			if pq["sha256Hash"] != hash || pq["version"] != float64(1) {
+				t.Errorf("unexpected persistedQuery extension: %v", pq)
+			}
+			if !tt.known && payloads[1]["query"] != query {
+				t.Errorf("retry should send the full query, got %v", payloads[1])
+			}
+		})
+	}
+}
+
+func TestGraphQLTransport_OperationName(t *testing.T) {
+	var payload map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte(`{"data":{"b":1}}`))
+	}))
+	defer srv.Close()
+
+	runGraphQL(t, "yapi: v1\nurl: "+srv.URL+"\ngraphql: 'query A { a } query B { b }'\noperation_name: B\n")
+	if payload["operationName"] != "B" {
+		t.Errorf("operationName = %v, want B", payload["operationName"])
+	}
+}
+
+func TestGraphQLTransport_Batch(t *testing.T) {
+	var payloads []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payloads)
+		_, _ = w.Write([]byte(`[{"data":{"user":{"name":"Ada"}}},{"data":{"user":null},"errors":[{"message":"not found"}]},{"data":{"posts":[]}}]`))
+	}))
+	defer srv.Close()
+
+	body := runGraphQL(t, `yapi: v1
+url: `+srv.URL+`
+graphql: |
+  query GetUser($id: ID!) { user(id: $id) { name } }
+  query ListPosts { posts { title } }
+batch:
+  - operation_name: GetUser
+    variables:
+      id: "1"
+  - name: missing
+    operation_name: GetUser
+    variables:
+      id: "2"
+  - operation_name: ListPosts
+`)
+
+	want := `{"GetUser":{"data":{"user":{"name":"Ada"}}},"missing":{"data":{"user":null},"errors":[{"message":"not found"}]},"ListPosts":{"data":{"posts":[]}}}`
+	if body != want {
+		t.Errorf("body = %s\nwant %s", body, want)
+	}
+	if len(payloads) != 3 || payloads[1]["operationName"] != "GetUser" || payloads[1]["variables"].(map[string]any)["id"] != "2" {
+		t.Errorf("unexpected batch payloads: %v", payloads)
+	}
+}
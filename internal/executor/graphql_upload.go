@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// graphqlMultipartBody encodes payload as a GraphQL multipart request
+// (https://github.com/jaydenseric/graphql-multipart-request-spec). uploads maps
+// variable paths such as `file`, `input.avatar` or `files.0` to local files; those
+// variables are sent as null and the files follow as numbered parts.
+func graphqlMultipartBody(payload graphqlPayload, uploads map[string]string) (io.Reader, string, error) {
+	paths := make([]string, 0, len(uploads))
+	for path := range uploads {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if payload.Variables == nil {
+		payload.Variables = map[string]any{}
+	}
+	fileMap := make(map[string][]string, len(paths))
+	for i, path := range paths {
+		vars, err := setUploadVariable(payload.Variables, strings.Split(path, "."))
+		if err != nil {
+			return nil, "", fmt.Errorf("uploads: %s: %w", path, err)
+		}
+		payload.Variables = vars.(map[string]any)
+		fileMap[strconv.Itoa(i)] = []string{"variables." + path}
+	}
+
+	operations, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal graphql payload: %w", err)
+	}
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal graphql upload map: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("operations", string(operations)); err != nil {
+		return nil, "", fmt.Errorf("failed to write operations field: %w", err)
+	}
+	if err := writer.WriteField("map", string(mapJSON)); err != nil {
+		return nil, "", fmt.Errorf("failed to write map field: %w", err)
+	}
+	for i, path := range paths {
+		if err := writeUploadPart(writer, strconv.Itoa(i), uploads[path]); err != nil {
+			return nil, "", fmt.Errorf("uploads: %s: %w", path, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// setUploadVariable sets the variable at path to null, creating the objects and list
+// entries leading to it, and returns the updated value.
+func setUploadVariable(v any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	seg := path[0]
+
+	if idx, err := strconv.Atoi(seg); err == nil && idx >= 0 {
+		list, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("cannot index %T with %s", v, seg)
+		}
+		for len(list) <= idx {
+			list = append(list, nil)
+		}
+		child, err := setUploadVariable(list[idx], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		list[idx] = child
+		return list, nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		if v != nil {
+			return nil, fmt.Errorf("cannot set field %s on %T", seg, v)
+		}
+		obj = map[string]any{}
+	}
+	child, err := setUploadVariable(obj[seg], path[1:])
+	if err != nil {
+		return nil, err
+	}
+	obj[seg] = child
+	return obj, nil
+}
+
+func writeUploadPart(writer *multipart.Writer, field, path string) error {
+	f, err := os.Open(path) // #nosec G304 -- uploads are user-provided paths
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     field,
+		"filename": filepath.Base(path),
+	}))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create file part: %w", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	return nil
+}
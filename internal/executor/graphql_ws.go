@@ -0,0 +1,195 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"yapi.run/cli/internal/domain"
+)
+
+// WebSocket subprotocols for GraphQL subscriptions: graphql-transport-ws (the graphql-ws
+// library) and graphql-ws (the legacy subscriptions-transport-ws protocol).
+const (
+	protocolTransportWS = "graphql-transport-ws"
+	protocolLegacyWS    = "graphql-ws"
+)
+
+// subscriptionID identifies the single operation run over each connection.
+const subscriptionID = "1"
+
+// GraphQLSubscriptionResult is the JSON response body produced for subscription operations.
+type GraphQLSubscriptionResult struct {
+	Count  int               `json:"count"`
+	Events []json.RawMessage `json:"events"` // Execution results ({"data": ..., "errors": ...})
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphqlSubscribe runs a subscription over a WebSocket and collects its events until
+// `graphql_events` have arrived, the server completes it, or the timeout elapses.
+func graphqlSubscribe(ctx context.Context, req *domain.Request, payload graphqlPayload) (*domain.Response, error) {
+	limit, _ := strconv.Atoi(req.Metadata["graphql_events"])
+
+	// The timeout bounds how long events are collected rather than failing the request
+	subCtx := ctx
+	if timeoutStr := req.Metadata["timeout"]; timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout value %q: %w", timeoutStr, err)
+		}
+		var cancel context.CancelFunc
+		subCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:        http.ProxyFromEnvironment,
+		Subprotocols: []string{protocolTransportWS, protocolLegacyWS},
+	}
+	if insecure, _ := strconv.ParseBool(req.Metadata["insecure"]); insecure {
+		dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user-controlled insecure TLS option
+	}
+	header := http.Header{}
+	for k, v := range req.Headers {
+		header.Set(k, v)
+	}
+
+	conn, handshake, err := dialer.DialContext(subCtx, websocketURL(req.URL), header)
+	if err != nil {
+		if handshake != nil {
+			return nil, fmt.Errorf("websocket handshake failed with status %s: %w", handshake.Status, err)
+		}
+		return nil, fmt.Errorf("failed to open websocket: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Unblock reads once the timeout elapses or the run is cancelled
+	stop := context.AfterFunc(subCtx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	legacy := conn.Subprotocol() == protocolLegacyWS
+	subscribe, next, stopType := "subscribe", "next", "complete"
+	if legacy {
+		subscribe, next, stopType = "start", "data", "stop"
+	}
+
+	if err := conn.WriteJSON(wsMessage{Type: "connection_init", Payload: json.RawMessage("{}")}); err != nil {
+		return nil, fmt.Errorf("failed to initialize subscription: %w", err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal graphql payload: %w", err)
+	}
+
+	result := GraphQLSubscriptionResult{Events: []json.RawMessage{}}
+	acked, completed := false, false
+	for !completed && (limit <= 0 || len(result.Events) < limit) {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if subCtx.Err() != nil && acked {
+				break // Timeout elapsed: return the events collected so far
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				break
+			}
+			if subCtx.Err() != nil {
+				return nil, fmt.Errorf("subscription was not acknowledged: %w", subCtx.Err())
+			}
+			return nil, fmt.Errorf("failed to read subscription message: %w", err)
+		}
+
+		switch msg.Type {
+		case "connection_ack":
+			acked = true
+			if err := conn.WriteJSON(wsMessage{ID: subscriptionID, Type: subscribe, Payload: body}); err != nil {
+				return nil, fmt.Errorf("failed to start subscription: %w", err)
+			}
+		case next:
+			result.Events = append(result.Events, msg.Payload)
+		case "error":
+			// Operation errors end the subscription; report them like a GraphQL response
+			result.Events = append(result.Events, errorEvent(msg.Payload))
+			completed = true
+		case "complete":
+			completed = true
+		case "ping":
+			if err := conn.WriteJSON(wsMessage{Type: "pong"}); err != nil {
+				return nil, fmt.Errorf("failed to answer ping: %w", err)
+			}
+		case "connection_error":
+			return nil, fmt.Errorf("subscription connection rejected: %s", msg.Payload)
+		}
+	}
+
+	// Best effort: stop the operation and close the connection cleanly
+	if !completed {
+		_ = conn.WriteJSON(wsMessage{ID: subscriptionID, Type: stopType})
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+	result.Count = len(result.Events)
+	out, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal subscription events: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range handshake.Header {
+		if len(v) > 0 && k != "Content-Type" {
+			headers[k] = v[0]
+		}
+	}
+	return &domain.Response{
+		StatusCode: handshake.StatusCode,
+		Headers:    headers,
+		Body:       io.NopCloser(bytes.NewReader(out)),
+	}, nil
+}
+
+// errorEvent wraps an `error` message payload as an execution result. graphql-transport-ws
+// sends a list of GraphQL errors; the legacy protocol sends a single error object.
+func errorEvent(payload json.RawMessage) json.RawMessage {
+	errs := payload
+	if trimmed := bytes.TrimSpace(payload); len(trimmed) == 0 || trimmed[0] != '[' {
+		errs = append(append(json.RawMessage("["), trimmed...), ']')
+	}
+	event, err := json.Marshal(map[string]json.RawMessage{"errors": errs})
+	if err != nil {
+		return json.RawMessage(`{"errors":[{"message":"invalid subscription error"}]}`)
+	}
+	return event
+}
+
+// websocketURL maps http(s) endpoints to ws(s); ws:// and wss:// URLs are kept.
+func websocketURL(url string) string {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.HasPrefix(lower, "https://"):
+		return "wss://" + url[len("https://"):]
+	case strings.HasPrefix(lower, "http://"):
+		return "ws://" + url[len("http://"):]
+	}
+	return url
+}
+
+// isWebSocketURL reports whether url uses a ws:// or wss:// scheme.
+func isWebSocketURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
@@ -0,0 +1,169 @@
+package executor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// startSubscriptionServer speaks protocol and answers a subscription with the given
+// event payloads, then either completes it or (if hold) keeps the connection open.
+func startSubscriptionServer(t *testing.T, protocol string, events []string, final *wsMessage, hold bool) (url string, subscribed chan wsMessage) {
+	t.Helper()
+	subscribed = make(chan wsMessage, 1)
+	upgrader := websocket.Upgrader{Subprotocols: []string{protocol}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection_init" {
+			return
+		}
+		_ = conn.WriteJSON(wsMessage{Type: "connection_ack"})
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		subscribed <- msg
+
+		next := "next"
+		if protocol == "graphql-ws" {
+			next = "data"
+		}
+		_ = conn.WriteJSON(wsMessage{Type: "ping"})
+		for _, e := range events {
+			_ = conn.WriteJSON(wsMessage{ID: msg.ID, Type: next, Payload: json.RawMessage(e)})
+		}
+		if final != nil {
+			final.ID = msg.ID
+			_ = conn.WriteJSON(final)
+		}
+		if hold {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, subscribed
+}
+
+func runSubscription(t *testing.T, url, extra string) (*executor.GraphQLSubscriptionResult, error) {
+	t.Helper()
+	res, err := config.LoadFromString(fmt.Sprintf(`yapi: v1
+url: %s
+graphql: |
+  subscription OnTick($every: Int!) { tick(every: $every) }
+variables:
+  every: 1
+%s`, url, extra))
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	resp, err := executor.GraphQLTransport(&http.Client{})(context.Background(), res.Request)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("StatusCode = %d, want 101", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	var result executor.GraphQLSubscriptionResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("response is not a subscription result: %v\n%s", err, body)
+	}
+	return &result, nil
+}
+
+func TestGraphQLSubscription_CollectsEvents(t *testing.T) {
+	events := []string{`{"data":{"tick":1}}`, `{"data":{"tick":2}}`, `{"data":{"tick":3}}`}
+	url, subscribed := startSubscriptionServer(t, "graphql-transport-ws", events, nil, true)
+
+	result, err := runSubscription(t, url, "events: 2")
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	if result.Count != 2 || string(result.Events[1]) != events[1] {
+		t.Errorf("got %d events %s, want the first 2", result.Count, result.Events)
+	}
+
+	msg := <-subscribed
+	if msg.Type != "subscribe" || !strings.Contains(string(msg.Payload), `"every":1`) {
+		t.Errorf("unexpected subscribe message: %+v (%s)", msg, msg.Payload)
+	}
+}
+
+func TestGraphQLSubscription_StopsOnComplete(t *testing.T) {
+	url, _ := startSubscriptionServer(t, "graphql-transport-ws", []string{`{"data":{"tick":1}}`}, &wsMessage{Type: "complete"}, false)
+
+	result, err := runSubscription(t, url, "events: 5")
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Count = %d, want 1", result.Count)
+	}
+}
+
+func TestGraphQLSubscription_TimeoutReturnsCollectedEvents(t *testing.T) {
+	url, _ := startSubscriptionServer(t, "graphql-transport-ws", []string{`{"data":{"tick":1}}`}, nil, true)
+
+	result, err := runSubscription(t, url, "timeout: 200ms")
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Count = %d, want 1", result.Count)
+	}
+}
+
+func TestGraphQLSubscription_LegacyProtocol(t *testing.T) {
+	url, subscribed := startSubscriptionServer(t, "graphql-ws", []string{`{"data":{"tick":1}}`}, &wsMessage{Type: "complete"}, false)
+
+	result, err := runSubscription(t, strings.Replace(url, "http://", "ws://", 1), "events: 1")
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Count = %d, want 1", result.Count)
+	}
+	if msg := <-subscribed; msg.Type != "start" {
+		t.Errorf("legacy protocol should send start, got %q", msg.Type)
+	}
+}
+
+func TestGraphQLSubscription_ErrorBecomesEvent(t *testing.T) {
+	final := &wsMessage{Type: "error", Payload: json.RawMessage(`[{"message":"not allowed"}]`)}
+	url, _ := startSubscriptionServer(t, "graphql-transport-ws", nil, final, false)
+
+	result, err := runSubscription(t, url, "events: 1")
+	if err != nil {
+		t.Fatalf("subscription failed: %v", err)
+	}
+	if result.Count != 1 || string(result.Events[0]) != `{"errors":[{"message":"not allowed"}]}` {
+		t.Errorf("unexpected events: %s", result.Events)
+	}
+}
@@ -1,13 +1,16 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"yapi.run/cli/internal/constants"
 	"yapi.run/cli/internal/domain"
 )
 
@@ -26,7 +29,18 @@ func HTTPTransport(client HTTPClient) TransportFunc {
 			defer cancel()
 		}
 
-		httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, req.Body)
+		// Signing needs the exact body bytes, so buffer the body up front
+		body := req.Body
+		var signedBody []byte
+		if req.Metadata["auth_type"] == constants.AuthAWSSigV4 && req.Body != nil {
+			var err error
+			if signedBody, err = io.ReadAll(req.Body); err != nil {
+				return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+			}
+			body = bytes.NewReader(signedBody)
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -36,6 +50,14 @@ func HTTPTransport(client HTTPClient) TransportFunc {
 			httpReq.Header.Set(k, v)
 		}
 
+		if req.Metadata["auth_type"] == constants.AuthAWSSigV4 {
+			creds, err := resolveAWSCredentials(req.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("aws_sigv4: %w", err)
+			}
+			signAWSV4(httpReq, signedBody, creds, req.Metadata["aws_region"], req.Metadata["aws_service"], time.Now())
+		}
+
 		clientToUse := client
 		if insecureStr, ok := req.Metadata["insecure"]; ok && insecureStr != "" {
 			insecure, err := strconv.ParseBool(insecureStr)
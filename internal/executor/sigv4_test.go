@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// Test vectors from the AWS Signature Version 4 test suite.
+var sigV4TestCreds = awsCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var sigV4TestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignAWSV4_TestSuite(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			method:    "GET",
+			url:       "https://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			method:    "GET",
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:      "post-vanilla",
+			method:    "POST",
+			url:       "https://example.amazonaws.com/",
+			signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			signAWSV4(req, nil, sigV4TestCreds, "us-east-1", "service", sigV4TestTime)
+
+			auth := req.Header.Get("Authorization")
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.signature
+			if auth != want {
+				t.Errorf("Authorization mismatch\n got: %s\nwant: %s", auth, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q, want 20150830T123600Z", got)
+			}
+		})
+	}
+}
+
+func TestSignAWSV4_SessionTokenAndS3(t *testing.T) {
+	creds := sigV4TestCreds
+	creds.SessionToken = "session-token"
+
+	req, err := http.NewRequest("PUT", "https://bucket.s3.amazonaws.com/my%20file.txt", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	body := []byte("hello")
+	signAWSV4(req, body, creds, "eu-west-1", "s3", sigV4TestTime)
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session-token" {
+		t.Errorf("X-Amz-Security-Token = %q, want session-token", got)
+	}
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != sha256Hex(body) {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want %q", got, sha256Hex(body))
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers: %s", req.Header.Get("Authorization"))
+	}
+	if got := sigV4CanonicalURI(req, "s3"); got != "/my%20file.txt" {
+		t.Errorf("s3 canonical URI = %q, want single-encoded path", got)
+	}
+	if got := sigV4CanonicalURI(req, "execute-api"); got != "/my%2520file.txt" {
+		t.Errorf("canonical URI = %q, want double-encoded path", got)
+	}
+}
+
+func TestSignAWSV4_IgnoresExistingAuthorization(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	// Set by the config or project env; replaced by the signature, so never signed
+	req.Header.Set("Authorization", "Bearer project-token")
+	req.Header.Set("User-Agent", "yapi")
+
+	signAWSV4(req, nil, sigV4TestCreds, "us-east-1", "service", sigV4TestTime)
+
+	// Same signature as the get-vanilla test vector
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestLoadSharedCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# comment
+[staging]
+aws_access_key_id=AKIDSTAGING
+aws_secret_access_key=staging-secret
+aws_session_token=staging-token
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	creds, err := loadSharedCredentials(path, "staging")
+	if err != nil {
+		t.Fatalf("loadSharedCredentials failed: %v", err)
+	}
+	want := awsCredentials{AccessKeyID: "AKIDSTAGING", SecretAccessKey: "staging-secret", SessionToken: "staging-token"}
+	if creds != want {
+		t.Errorf("got %+v, want %+v", creds, want)
+	}
+
+	if _, err := loadSharedCredentials(path, "missing"); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
+
+func TestResolveAWSCredentials_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte("[default]\naws_access_key_id=FILE\naws_secret_access_key=file-secret\n"), 0600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	creds, err := resolveAWSCredentials(map[string]string{})
+	if err != nil {
+		t.Fatalf("resolveAWSCredentials failed: %v", err)
+	}
+	if creds.AccessKeyID != "FILE" {
+		t.Errorf("expected credentials from shared file, got %q", creds.AccessKeyID)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	creds, _ = resolveAWSCredentials(map[string]string{})
+	if creds.AccessKeyID != "ENV" {
+		t.Errorf("expected credentials from environment, got %q", creds.AccessKeyID)
+	}
+
+	creds, _ = resolveAWSCredentials(map[string]string{
+		"aws_access_key_id":     "EXPLICIT",
+		"aws_secret_access_key": "explicit-secret",
+	})
+	if creds.AccessKeyID != "EXPLICIT" {
+		t.Errorf("expected explicit credentials, got %q", creds.AccessKeyID)
+	}
+}
+
+func TestHTTPTransport_AWSSigV4(t *testing.T) {
+	var gotAuth, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req := &domain.Request{
+		URL:     srv.URL + "/prod/items",
+		Method:  "POST",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    bytes.NewReader([]byte(`{"id":1}`)),
+		Metadata: map[string]string{
+			"auth_type":             "aws_sigv4",
+			"aws_region":            "us-east-1",
+			"aws_service":           "execute-api",
+			"aws_access_key_id":     "AKIDEXAMPLE",
+			"aws_secret_access_key": "secret",
+		},
+	}
+
+	resp, err := HTTPTransport(&http.Client{})(context.Background(), req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		t.Errorf("missing SigV4 Authorization header, got %q", gotAuth)
+	}
+	if !strings.Contains(gotAuth, "/us-east-1/execute-api/aws4_request") {
+		t.Errorf("unexpected credential scope: %q", gotAuth)
+	}
+	if !strings.Contains(gotAuth, "SignedHeaders=content-type;host;x-amz-date,") {
+		t.Errorf("unexpected signed headers: %q", gotAuth)
+	}
+	if gotBody != `{"id":1}` {
+		t.Errorf("body not forwarded after signing, got %q", gotBody)
+	}
+}
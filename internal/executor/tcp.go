@@ -3,6 +3,7 @@ package executor
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 )
 
 // TCPTransport is the transport function for TCP requests.
+// tls:// and tcps:// URLs are dialed over TLS and report the negotiated session.
 func TCPTransport(ctx context.Context, req *domain.Request) (*domain.Response, error) {
 	// Extract metadata
 	data := req.Metadata["data"]
@@ -25,51 +27,41 @@ func TCPTransport(ctx context.Context, req *domain.Request) (*domain.Response, e
 	closeAfterSend, _ := strconv.ParseBool(req.Metadata["close_after_send"])
 
 	// Extract host and port from URL
-	target := strings.TrimPrefix(req.URL, "tcp://")
-	if !strings.Contains(target, ":") {
+	_, target, found := strings.Cut(req.URL, "://")
+	if !found || !strings.Contains(target, ":") {
 		return nil, fmt.Errorf("TCP URL must be in format tcp://host:port, got %s", req.URL)
 	}
 
-	// Prepare data to send
-	var sendData []byte
-	var err error
-	if data != "" {
-		sendData = []byte(data)
-	} else if req.Body != nil {
-		var buf bytes.Buffer
-		if _, err = io.Copy(&buf, req.Body); err != nil {
-			return nil, fmt.Errorf("failed to read request body for TCP: %w", err)
-		}
-		sendData = buf.Bytes()
+	sendData, err := decodePayload(data, req.Body, encoding)
+	if err != nil {
+		return nil, err
 	}
 
-	// Handle encoding
-	switch encoding {
-	case "hex":
-		decoded, err := hex.DecodeString(string(sendData))
+	// Establish connection
+	var conn net.Conn
+	var session *domain.TLSInfo
+	if req.Metadata["tls"] == "true" {
+		cfg, err := socketTLSConfig(req.Metadata, target)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode hex data: %w", err)
+			return nil, err
 		}
-		sendData = decoded
-	case "base64":
-		decoded, err := base64.StdEncoding.DecodeString(string(sendData))
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64 data: %w", err)
+		d := tls.Dialer{Config: cfg}
+		if conn, err = d.DialContext(ctx, "tcp", target); err != nil {
+			return nil, fmt.Errorf("failed to dial TLS target %s: %w", target, err)
+		}
+		session = tlsInfo(conn.(*tls.Conn).ConnectionState())
+	} else {
+		var d net.Dialer
+		if conn, err = d.DialContext(ctx, "tcp", target); err != nil {
+			return nil, fmt.Errorf("failed to dial TCP target %s: %w", target, err)
 		}
-		sendData = decoded
-	case "text", "": // Default is text
-		// No special decoding needed
-	default:
-		return nil, fmt.Errorf("unsupported TCP encoding: %s", encoding)
 	}
+	defer func() { _ = conn.Close() }()
+	defer closeOnCancel(ctx, conn)()
 
-	// Establish connection
-	var d net.Dialer
-	conn, err := d.DialContext(ctx, "tcp", target)
-	if err != nil {
-		return nil, fmt.Errorf("failed to dial TCP target %s: %w", target, err)
+	if script := req.Metadata["conversation"]; script != "" {
+		return runConversation(ctx, conn, script, encoding, readTimeout, session)
 	}
-	defer func() { _ = conn.Close() }()
 
 	// Write data if present
 	if len(sendData) > 0 {
@@ -78,12 +70,21 @@ func TCPTransport(ctx context.Context, req *domain.Request) (*domain.Response, e
 			return nil, fmt.Errorf("failed to write data to TCP connection: %w", err)
 		}
 		if closeAfterSend {
-			if tcpConn, ok := conn.(*net.TCPConn); ok {
-				_ = tcpConn.CloseWrite()
+			// Both *net.TCPConn and *tls.Conn support half-close
+			if hc, ok := conn.(interface{ CloseWrite() error }); ok {
+				_ = hc.CloseWrite()
 			}
 		}
 	}
 
+	frame, err := newFramer(req.Metadata, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if frame != nil {
+		return readFrame(ctx, conn, frame, readTimeout, session)
+	}
+
 	// Read response
 	var respBuf bytes.Buffer
 
@@ -105,5 +106,74 @@ func TCPTransport(ctx context.Context, req *domain.Request) (*domain.Response, e
 	return &domain.Response{
 		StatusCode: 0, // TCP has no status code
 		Body:       io.NopCloser(&respBuf),
+		TLS:        session,
+	}, nil
+}
+
+// closeOnCancel closes conn once ctx is cancelled, so blocked reads and writes return
+// at once. The returned func stops the watch.
+func closeOnCancel(ctx context.Context, conn net.Conn) func() bool {
+	return context.AfterFunc(ctx, func() { _ = conn.Close() })
+}
+
+// decodePayload returns the bytes to send for a socket transport. The payload comes from
+// the data field, or from the request body if data is empty, and is decoded per encoding.
+func decodePayload(data string, body io.Reader, encoding string) ([]byte, error) {
+	var payload []byte
+	if data != "" {
+		payload = []byte(data)
+	} else if body != nil {
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		payload = buf.Bytes()
+	}
+
+	switch encoding {
+	case "hex":
+		decoded, err := hex.DecodeString(string(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hex data: %w", err)
+		}
+		return decoded, nil
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(string(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 data: %w", err)
+		}
+		return decoded, nil
+	case "text", "": // Default is text
+		return payload, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// readFrame reads until one complete frame has arrived and returns its payload.
+func readFrame(ctx context.Context, conn net.Conn, frame *framer, readTimeout int, session *domain.TLSInfo) (*domain.Response, error) {
+	timeout := defaultReadUntilTimeout
+	if readTimeout > 0 {
+		timeout = time.Duration(readTimeout) * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	data, _, _, err := readUntil(conn, nil, deadline, frame.match)
+	if err != nil {
+		return nil, fmt.Errorf("%s while waiting for %s; received %q", err, frame.describe, data)
+	}
+	if frame.check != nil {
+		if err := frame.check(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.Response{
+		StatusCode: 0, // TCP has no status code
+		Body:       io.NopCloser(bytes.NewReader(frame.payload(data))),
+		TLS:        session,
 	}, nil
 }
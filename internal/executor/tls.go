@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// socketTLSConfig builds the client TLS configuration for a tls:// or tcps:// request.
+// target is the host:port being dialed; its host is the default SNI name.
+func socketTLSConfig(meta map[string]string, target string) (*tls.Config, error) {
+	insecureFlag, _ := strconv.ParseBool(meta["insecure"])
+
+	serverName := meta["tls_server_name"]
+	if serverName == "" {
+		if host, _, err := net.SplitHostPort(target); err == nil {
+			serverName = host
+		}
+	}
+
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureFlag, //nolint:gosec // user-controlled insecure TLS option
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if alpn := meta["tls_alpn"]; alpn != "" {
+		cfg.NextProtos = strings.Split(alpn, ",")
+	}
+
+	if caFile := meta["tls_ca_cert"]; caFile != "" {
+		pem, err := os.ReadFile(caFile) // #nosec G304 -- ca_cert is a user-provided path
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_cert '%s': %w", caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert '%s' contains no PEM certificates", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	certFile, keyFile := meta["tls_client_cert"], meta["tls_client_key"]
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// tlsInfo summarizes a completed handshake for the result.
+func tlsInfo(state tls.ConnectionState) *domain.TLSInfo {
+	info := &domain.TLSInfo{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		ServerName:         state.ServerName,
+		PeerCertificates:   make([]domain.CertificateInfo, 0, len(state.PeerCertificates)),
+	}
+	for _, cert := range state.PeerCertificates {
+		fingerprint := sha256.Sum256(cert.Raw)
+		info.PeerCertificates = append(info.PeerCertificates, domain.CertificateInfo{
+			Subject:           cert.Subject.String(),
+			Issuer:            cert.Issuer.String(),
+			SerialNumber:      cert.SerialNumber.Text(16),
+			NotBefore:         cert.NotBefore,
+			NotAfter:          cert.NotAfter,
+			DNSNames:          cert.DNSNames,
+			SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+		})
+	}
+	return info
+}
@@ -0,0 +1,244 @@
+package executor_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+// selfSignedCert creates a self-signed certificate and writes cert/key PEM files to dir.
+func selfSignedCert(t *testing.T, dir, name string, dnsNames ...string) (tls.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              dnsNames,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load key pair: %v", err)
+	}
+	return cert, certFile, keyFile
+}
+
+// startTLSEchoServer echoes one line back over TLS.
+func startTLSEchoServer(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				received, err := io.ReadAll(c)
+				if err != nil {
+					return
+				}
+				_, _ = c.Write(received)
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestTCPTransport_TLS(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, caFile, _ := selfSignedCert(t, dir, "server", "yapi.test")
+	_, clientCertFile, clientKeyFile := selfSignedCert(t, dir, "client")
+	clientPEM, _ := os.ReadFile(clientCertFile)
+	clientPool := x509.NewCertPool()
+	clientPool.AppendCertsFromPEM(clientPEM)
+
+	addr := startTLSEchoServer(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		NextProtos:   []string{"echo/1"},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientPool,
+	})
+	_, port, _ := net.SplitHostPort(addr)
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "ca bundle and sni",
+			yaml: fmt.Sprintf(`
+yapi: v1
+url: tls://%s
+data: "hello"
+close_after_send: true
+read_timeout: 1
+tls:
+  server_name: yapi.test
+  ca_cert: %s
+  alpn: [echo/1]`, addr, caFile),
+		},
+		{
+			name: "tcps scheme with client certificate",
+			yaml: fmt.Sprintf(`
+yapi: v1
+url: tcps://localhost:%s
+data: "hello"
+close_after_send: true
+read_timeout: 1
+tls:
+  server_name: yapi.test
+  ca_cert: %s
+  client_cert: %s
+  client_key: %s
+  alpn: [echo/1]`, port, caFile, clientCertFile, clientKeyFile),
+		},
+		{
+			name: "insecure skips verification",
+			yaml: fmt.Sprintf(`
+yapi: v1
+url: tls://%s
+data: "hello"
+close_after_send: true
+read_timeout: 1
+insecure: true
+tls:
+  alpn: [echo/1]`, addr),
+		},
+		{
+			name: "unknown authority",
+			yaml: fmt.Sprintf(`
+yapi: v1
+url: tls://%s
+data: "hello"
+read_timeout: 1`, addr),
+			wantErr: "certificate",
+		},
+		{
+			name: "sni mismatch",
+			yaml: fmt.Sprintf(`
+yapi: v1
+url: tls://%s
+data: "hello"
+read_timeout: 1
+tls:
+  server_name: other.test
+  ca_cert: %s`, addr, caFile),
+			wantErr: "other.test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString(tt.yaml)
+			if err != nil {
+				t.Fatalf("LoadFromString failed: %v", err)
+			}
+
+			resp, err := executor.TCPTransport(context.Background(), res.Request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != "hello" {
+				t.Errorf("Expected response %q, got %q", "hello", string(body))
+			}
+			if resp.TLS == nil {
+				t.Fatal("expected TLS session info")
+			}
+			if resp.TLS.Version != "TLS 1.3" {
+				t.Errorf("Version = %q, want TLS 1.3", resp.TLS.Version)
+			}
+			if resp.TLS.NegotiatedProtocol != "echo/1" {
+				t.Errorf("NegotiatedProtocol = %q, want echo/1", resp.TLS.NegotiatedProtocol)
+			}
+			if len(resp.TLS.PeerCertificates) != 1 || resp.TLS.PeerCertificates[0].Subject != "CN=server" {
+				t.Errorf("unexpected peer certificates: %+v", resp.TLS.PeerCertificates)
+			}
+		})
+	}
+}
+
+func TestTCPTransport_TLSMissingClientCert(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, caFile, _ := selfSignedCert(t, dir, "server", "yapi.test")
+	addr := startTLSEchoServer(t, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+
+	res, err := config.LoadFromString(fmt.Sprintf(`
+yapi: v1
+url: tls://%s
+data: "hello"
+close_after_send: true
+read_timeout: 1
+tls:
+  server_name: yapi.test
+  ca_cert: %s
+  client_cert: %s`, addr, caFile, filepath.Join(dir, "missing.crt")))
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+
+	_, err = executor.TCPTransport(context.Background(), res.Request)
+	if err == nil || !strings.Contains(err.Error(), "failed to load client certificate") {
+		t.Errorf("expected client certificate error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// defaultUDPReadTimeout bounds the wait for replies, since lost datagrams are never retransmitted.
+const defaultUDPReadTimeout = 5 * time.Second
+
+// maxDatagramSize is the largest UDP payload that can be received.
+const maxDatagramSize = 65535
+
+// UDPDatagram is a single datagram received in reply to a UDP request.
+type UDPDatagram struct {
+	From string `json:"from"`
+	Size int    `json:"size"`
+	Data string `json:"data"` // Payload in the request's encoding (text, hex, base64)
+}
+
+// UDPResult is the JSON response body produced by the UDP transport.
+type UDPResult struct {
+	Count     int           `json:"count"`
+	Datagrams []UDPDatagram `json:"datagrams"`
+}
+
+// UDPTransport is the transport function for UDP requests.
+// It sends one datagram and waits for the configured number of reply datagrams.
+func UDPTransport(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+	encoding := req.Metadata["encoding"]
+	readTimeout, _ := strconv.Atoi(req.Metadata["read_timeout"])
+	closeAfterSend, _ := strconv.ParseBool(req.Metadata["close_after_send"])
+	expected, _ := strconv.Atoi(req.Metadata["datagrams"])
+	if expected <= 0 {
+		expected = 1
+	}
+
+	target := strings.TrimPrefix(req.URL, "udp://")
+	if !strings.Contains(target, ":") {
+		return nil, fmt.Errorf("UDP URL must be in format udp://host:port, got %s", req.URL)
+	}
+
+	sendData, err := decodePayload(req.Metadata["data"], req.Body, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial UDP target %s: %w", target, err)
+	}
+	defer func() { _ = conn.Close() }()
+	defer closeOnCancel(ctx, conn)()
+
+	if _, err := conn.Write(sendData); err != nil {
+		return nil, fmt.Errorf("failed to write datagram: %w", err)
+	}
+
+	result := UDPResult{Datagrams: []UDPDatagram{}}
+
+	// Fire-and-forget (e.g. metrics ingestion): don't wait for a reply
+	if !closeAfterSend {
+		timeout := defaultUDPReadTimeout
+		if readTimeout > 0 {
+			timeout = time.Duration(readTimeout) * time.Second
+		}
+		deadline := time.Now().Add(timeout)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		_ = conn.SetReadDeadline(deadline)
+
+		buf := make([]byte, maxDatagramSize)
+		for len(result.Datagrams) < expected {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					return nil, fmt.Errorf("expected %d datagram(s) from %s, received %d before read timeout", expected, target, len(result.Datagrams))
+				}
+				return nil, fmt.Errorf("failed to read from UDP connection: %w", err)
+			}
+			result.Datagrams = append(result.Datagrams, UDPDatagram{
+				From: conn.RemoteAddr().String(),
+				Size: n,
+				Data: encodePayload(buf[:n], encoding),
+			})
+		}
+	}
+	result.Count = len(result.Datagrams)
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode UDP response: %w", err)
+	}
+
+	return &domain.Response{
+		StatusCode: 0, // UDP has no status code
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+// encodePayload renders received bytes in the same encoding used for the request data.
+func encodePayload(b []byte, encoding string) string {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(b)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	default:
+		return string(b)
+	}
+}
@@ -0,0 +1,148 @@
+package executor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/executor"
+)
+
+// startUDPServer replies to each datagram with the given replies (or an echo if none).
+func startUDPServer(t *testing.T, replies ...[]byte) (addr string, received chan []byte) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	received = make(chan []byte, 10)
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, from, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			msg := append([]byte(nil), buf[:n]...)
+			received <- msg
+			if len(replies) == 0 {
+				_, _ = pc.WriteTo(msg, from)
+				continue
+			}
+			for _, r := range replies {
+				_, _ = pc.WriteTo(r, from)
+			}
+		}
+	}()
+	return pc.LocalAddr().String(), received
+}
+
+func runUDP(t *testing.T, yaml string) (*executor.UDPResult, error) {
+	t.Helper()
+	res, err := config.LoadFromString(yaml)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	resp, err := executor.UDPTransport(context.Background(), res.Request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if resp.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", resp.Headers["Content-Type"])
+	}
+	var result executor.UDPResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("response is not valid JSON: %v\n%s", err, body)
+	}
+	return &result, nil
+}
+
+func TestUDPTransport_Echo(t *testing.T) {
+	addr, received := startUDPServer(t)
+
+	result, err := runUDP(t, fmt.Sprintf(`
+yapi: v1
+url: udp://%s
+data: "ping"
+read_timeout: 1`, addr))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := string(<-received); got != "ping" {
+		t.Errorf("Server received %q, want ping", got)
+	}
+	if result.Count != 1 || result.Datagrams[0].Data != "ping" || result.Datagrams[0].Size != 4 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Datagrams[0].From != addr {
+		t.Errorf("From = %q, want %q", result.Datagrams[0].From, addr)
+	}
+}
+
+func TestUDPTransport_MultipleDatagramsHex(t *testing.T) {
+	addr, received := startUDPServer(t, []byte{0xde, 0xad}, []byte{0xbe, 0xef})
+
+	result, err := runUDP(t, fmt.Sprintf(`
+yapi: v1
+url: udp://%s
+data: "0001"
+encoding: hex
+datagrams: 2
+read_timeout: 1`, addr))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := <-received; string(got) != "\x00\x01" {
+		t.Errorf("Server received %x, want 0001", got)
+	}
+	if result.Count != 2 || result.Datagrams[0].Data != "dead" || result.Datagrams[1].Data != "beef" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestUDPTransport_TimeoutWaitingForDatagrams(t *testing.T) {
+	addr, _ := startUDPServer(t, []byte("only one"))
+
+	_, err := runUDP(t, fmt.Sprintf(`
+yapi: v1
+url: udp://%s
+data: "hello"
+datagrams: 2
+read_timeout: 1`, addr))
+	if err == nil || !strings.Contains(err.Error(), "received 1 before read timeout") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestUDPTransport_FireAndForget(t *testing.T) {
+	addr, received := startUDPServer(t, []byte{})
+
+	result, err := runUDP(t, fmt.Sprintf(`
+yapi: v1
+url: udp://%s
+data: "requests:1|c"
+close_after_send: true`, addr))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := string(<-received); got != "requests:1|c" {
+		t.Errorf("Server received %q, want metric", got)
+	}
+	if result.Count != 0 || len(result.Datagrams) != 0 {
+		t.Errorf("expected no datagrams, got %+v", result)
+	}
+}
@@ -70,6 +70,44 @@ func ApplyJQWithVars(input string, filterExpr string, variables map[string]any)
 	return collectResults(iter)
 }
 
+// EvalJQValue evaluates a jq expression with optional variables and returns its
+// single result as a typed value. Large integers are kept as *big.Int.
+func EvalJQValue(input string, expr string, variables map[string]any) (any, error) {
+	expr = strings.TrimSpace(expr)
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jq expression %q: %w", expr, err)
+	}
+	inputData, err := parseJSONPreserveNumbers(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input as JSON: %w", err)
+	}
+	iter, _, _, err := compileAndRunWithVars(query, inputData, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := v.(error); isErr {
+			return nil, fmt.Errorf("jq error: %w", err)
+		}
+		results = append(results, v)
+	}
+	switch len(results) {
+	case 0:
+		return nil, fmt.Errorf("jq expression %q produced no result", expr)
+	case 1:
+		return results[0], nil
+	default:
+		return nil, fmt.Errorf("jq expression %q produced %d results; collect them with [...]", expr, len(results))
+	}
+}
+
 // collectResults collects results from a JQ iterator
 func collectResults(iter gojq.Iter) (string, error) {
 	var results []string
@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -839,3 +840,39 @@ func TestEvalJQBoolWithDetailAndVars(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalJQValue(t *testing.T) {
+	input := `{"items":[{"id":9007199254740993,"tags":["a","b"]},{"id":2}],"status":"ok"}`
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr string
+	}{
+		{name: "array index", expr: ".items[1].id", want: "2"},
+		{name: "large integer", expr: ".items[0].id", want: "9007199254740993"},
+		{name: "computed", expr: "[.items[].id] | length", want: "2"},
+		{name: "object", expr: ".items[0].tags", want: `["a","b"]`},
+		{name: "variable", expr: "$prefix + .status", want: `"status: ok"`},
+		{name: "several results", expr: ".items[].id", wantErr: "produced 2 results"},
+		{name: "no result", expr: "empty", wantErr: "produced no result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalJQValue(input, tt.expr, map[string]any{"prefix": "status: "})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("EvalJQValue() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvalJQValue() error: %v", err)
+			}
+			b, _ := json.Marshal(got)
+			if string(b) != tt.want {
+				t.Errorf("EvalJQValue() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
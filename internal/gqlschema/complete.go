@@ -0,0 +1,614 @@
+package gqlschema
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/lexer"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// ItemKind classifies a schema element offered for completion or shown on hover.
+type ItemKind int
+
+const (
+	ItemField ItemKind = iota
+	ItemArgument
+	ItemType
+	ItemEnumValue
+	ItemDirective
+	ItemKeyword
+)
+
+func (k ItemKind) String() string {
+	switch k {
+	case ItemField:
+		return "Field"
+	case ItemArgument:
+		return "Argument"
+	case ItemType:
+		return "Type"
+	case ItemEnumValue:
+		return "Enum value"
+	case ItemDirective:
+		return "Directive"
+	}
+	return "Keyword"
+}
+
+// Item is a schema element at a position in a query.
+type Item struct {
+	Kind        ItemKind
+	Name        string
+	Parent      string // Type owning a field, argument or enum value
+	Detail      string // Type of a field or argument, or the kind of a type
+	Description string
+	Deprecation string // Deprecation reason, if deprecated
+	Start, End  int    // Span of the name in the query (Describe only)
+}
+
+// Complete returns the schema elements that can be typed at offset in query: fields
+// inside selection sets, arguments and input fields, enum values, type conditions,
+// variable types and directives. The query may be incomplete.
+func (s *Schema) Complete(query string, offset int) []Item {
+	w := &walker{schema: s}
+	prefix := ""
+	for _, tok := range tokenize(query) {
+		if tok.Start >= offset {
+			break
+		}
+		if tok.End >= offset {
+			if tok.Kind != lexer.NAME {
+				if tok.End > offset {
+					return nil // Inside a string or number
+				}
+				w.step(tok)
+				break
+			}
+			prefix = query[tok.Start:offset]
+			break
+		}
+		w.step(tok)
+	}
+
+	var items []Item
+	for _, item := range w.candidates() {
+		if strings.HasPrefix(item.Name, prefix) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Describe returns the schema element named at offset in query, or nil.
+func (s *Schema) Describe(query string, offset int) *Item {
+	w := &walker{schema: s}
+	for _, tok := range tokenize(query) {
+		if tok.Start > offset {
+			return nil
+		}
+		if tok.Kind == lexer.NAME && tok.End >= offset {
+			item := w.describe(tok.Value)
+			if item != nil {
+				item.Start, item.End = tok.Start, tok.End
+			}
+			return item
+		}
+		w.step(tok)
+	}
+	return nil
+}
+
+// tokenize lexes as much of query as it can; a lexing error (such as an unterminated
+// string being typed) ends the token stream.
+func tokenize(query string) []lexer.Token {
+	lex := lexer.Lex(source.NewSource(&source.Source{Body: []byte(query)}))
+	var tokens []lexer.Token
+	for {
+		tok, err := lex(0)
+		if err != nil || tok.Kind == lexer.EOF {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+type frameKind int
+
+const (
+	frameSelection frameKind = iota // { fields } of typ
+	frameArguments                  // ( name: value ) of a field or directive
+	frameObject                     // { name: value } of the input object typ
+	frameList                       // [ values ] of item type typ
+	frameVariables                  // ( $name: Type ) of an operation
+)
+
+type frame struct {
+	kind    frameKind
+	typ     graphql.Type
+	args    []*graphql.Argument      // Arguments frame
+	field   *graphql.FieldDefinition // Selection frame: last field selected
+	key     string                   // Argument or input field whose value follows
+	inValue bool                     // A value (or variable type) is expected rather than a key
+	used    map[string]bool          // Keys already given
+}
+
+// walker follows the structure of a query token by token, tolerating incomplete input.
+type walker struct {
+	schema    *Schema
+	stack     []*frame
+	prev      lexer.Token
+	next      graphql.Type // Type of the selection set opened by the next '{'
+	nextSet   bool
+	typeCond  bool // The next name is a type condition
+	directive *graphql.Directive
+}
+
+func (w *walker) top() *frame {
+	if len(w.stack) == 0 {
+		return nil
+	}
+	return w.stack[len(w.stack)-1]
+}
+
+func (w *walker) push(f *frame) {
+	f.used = make(map[string]bool)
+	w.stack = append(w.stack, f)
+}
+
+// pop closes the innermost frame; a closed object or list completes the value it was in.
+func (w *walker) pop() {
+	if len(w.stack) == 0 {
+		return
+	}
+	closed := w.top()
+	w.stack = w.stack[:len(w.stack)-1]
+	if parent := w.top(); parent != nil && (closed.kind == frameObject || closed.kind == frameList) && parent.kind != frameList {
+		parent.inValue = false
+	}
+}
+
+func (w *walker) step(tok lexer.Token) {
+	prev := w.prev
+	w.prev = tok
+	directive := w.directive
+	w.directive = nil
+
+	switch {
+	case tok.Kind == lexer.AT:
+		return
+	case tok.Kind == lexer.NAME && prev.Kind == lexer.AT:
+		w.directive = w.schema.Directive(tok.Value)
+		return
+	case tok.Kind == lexer.PAREN_L && directive != nil:
+		w.push(&frame{kind: frameArguments, args: directive.Args})
+		return
+	}
+
+	f := w.top()
+	if f == nil {
+		w.stepDocument(tok)
+		return
+	}
+	switch f.kind {
+	case frameSelection:
+		w.stepSelection(f, tok, prev)
+	case frameVariables:
+		w.stepVariables(f, tok)
+	default:
+		w.stepValue(f, tok, prev)
+	}
+}
+
+func (w *walker) stepDocument(tok lexer.Token) {
+	switch tok.Kind {
+	case lexer.NAME:
+		switch {
+		case w.typeCond:
+			w.next, w.typeCond = w.schema.Type(tok.Value), false
+		case tok.Value == "on":
+			w.typeCond = true
+		case tok.Value == "query":
+			w.setNext(w.schema.QueryType())
+		case tok.Value == "mutation":
+			w.setNext(w.schema.MutationType())
+		case tok.Value == "subscription":
+			w.setNext(w.schema.SubscriptionType())
+		case tok.Value == "fragment":
+			w.next, w.nextSet = nil, true
+		}
+	case lexer.PAREN_L:
+		w.push(&frame{kind: frameVariables})
+	case lexer.BRACE_L:
+		typ := w.next
+		if !w.nextSet {
+			typ = w.rootQuery() // Query shorthand
+		}
+		w.push(&frame{kind: frameSelection, typ: typ})
+		w.next, w.nextSet = nil, false
+	}
+}
+
+// setNext records the root type opened by the operation; a missing root is a nil Type.
+func (w *walker) setNext(root *graphql.Object) {
+	w.next, w.nextSet = nil, true
+	if root != nil {
+		w.next = root
+	}
+}
+
+func (w *walker) rootQuery() graphql.Type {
+	if q := w.schema.QueryType(); q != nil {
+		return q
+	}
+	return nil
+}
+
+func (w *walker) stepSelection(f *frame, tok lexer.Token, prev lexer.Token) {
+	switch tok.Kind {
+	case lexer.NAME:
+		switch {
+		case prev.Kind == lexer.SPREAD && tok.Value == "on":
+			w.typeCond = true
+		case prev.Kind == lexer.SPREAD:
+			w.next, w.nextSet = nil, false // Fragment spread
+		case w.typeCond:
+			w.next, w.typeCond = w.schema.Type(tok.Value), false
+		default:
+			f.field = fieldDef(f.typ, tok.Value)
+		}
+	case lexer.SPREAD:
+		w.next, w.nextSet = f.typ, true
+	case lexer.PAREN_L:
+		var args []*graphql.Argument
+		if f.field != nil {
+			args = f.field.Args
+		}
+		w.push(&frame{kind: frameArguments, args: args})
+	case lexer.BRACE_L:
+		typ := w.next
+		if !w.nextSet {
+			typ = nil
+			if f.field != nil {
+				typ = named(f.field.Type)
+			}
+		}
+		w.push(&frame{kind: frameSelection, typ: typ})
+		w.next, w.nextSet = nil, false
+	case lexer.BRACE_R:
+		w.pop()
+	}
+}
+
+func (w *walker) stepVariables(f *frame, tok lexer.Token) {
+	switch tok.Kind {
+	case lexer.COLON:
+		f.inValue = true
+	case lexer.DOLLAR, lexer.EQUALS:
+		f.inValue = false
+	case lexer.PAREN_R:
+		w.pop()
+	}
+}
+
+func (w *walker) stepValue(f *frame, tok lexer.Token, prev lexer.Token) {
+	if f.kind != frameList && !f.inValue {
+		switch tok.Kind {
+		case lexer.NAME:
+			f.key = tok.Value
+			f.used[tok.Value] = true
+		case lexer.COLON:
+			f.inValue = true
+		case lexer.PAREN_R, lexer.BRACE_R:
+			w.pop()
+		}
+		return
+	}
+
+	switch tok.Kind {
+	case lexer.NAME, lexer.INT, lexer.FLOAT, lexer.STRING, lexer.BLOCK_STRING:
+		if f.kind != frameList {
+			f.inValue = false
+		}
+	case lexer.BRACE_L:
+		w.push(&frame{kind: frameObject, typ: named(w.valueType(f))})
+	case lexer.BRACKET_L:
+		item := w.valueType(f)
+		if nn, ok := item.(*graphql.NonNull); ok {
+			item = nn.OfType
+		}
+		if list, ok := item.(*graphql.List); ok {
+			item = list.OfType
+		}
+		w.push(&frame{kind: frameList, typ: item})
+	case lexer.PAREN_R, lexer.BRACE_R, lexer.BRACKET_R:
+		w.pop()
+	}
+}
+
+// valueType is the input type expected at the current value of f.
+func (w *walker) valueType(f *frame) graphql.Type {
+	if f.kind == frameList {
+		return f.typ
+	}
+	for _, in := range w.inputs(f) {
+		if in.Name == f.key {
+			return in.typ
+		}
+	}
+	return nil
+}
+
+type input struct {
+	Item
+	typ graphql.Type
+}
+
+// inputs lists the arguments or input fields that are valid keys in f.
+func (w *walker) inputs(f *frame) []input {
+	var ins []input
+	switch f.kind {
+	case frameArguments:
+		for _, a := range f.args {
+			ins = append(ins, input{Item{Kind: ItemArgument, Name: a.Name(), Detail: a.Type.String(), Description: a.Description()}, a.Type})
+		}
+	case frameObject:
+		if obj, ok := f.typ.(*graphql.InputObject); ok {
+			for name, field := range obj.Fields() {
+				ins = append(ins, input{Item{Kind: ItemArgument, Name: name, Parent: obj.Name(), Detail: field.Type.String(), Description: field.Description()}, field.Type})
+			}
+		}
+	}
+	sort.Slice(ins, func(i, j int) bool { return ins[i].Name < ins[j].Name })
+	return ins
+}
+
+// candidates lists what may be typed at the walker's position.
+func (w *walker) candidates() []Item {
+	if w.prev.Kind == lexer.DOLLAR {
+		return nil // Variable name
+	}
+	if w.prev.Kind == lexer.AT {
+		var items []Item
+		for _, d := range w.schema.Directives() {
+			items = append(items, Item{Kind: ItemDirective, Name: d.Name, Description: d.Description})
+		}
+		return items
+	}
+
+	f := w.top()
+	if f == nil {
+		if w.typeCond {
+			return w.typeItems(isComposite)
+		}
+		if w.prev.Kind == 0 || w.prev.Kind == lexer.BRACE_R {
+			return keywords("query", "mutation", "subscription", "fragment")
+		}
+		return nil
+	}
+
+	switch f.kind {
+	case frameSelection:
+		switch {
+		case w.prev.Kind == lexer.SPREAD:
+			return keywords("on")
+		case w.typeCond:
+			return w.conditionItems(f.typ)
+		}
+		return fieldItems(f.typ)
+	case frameVariables:
+		if f.inValue {
+			return w.typeItems(isInput)
+		}
+		return nil
+	}
+
+	if f.kind != frameList && !f.inValue {
+		var items []Item
+		for _, in := range w.inputs(f) {
+			if !f.used[in.Name] {
+				items = append(items, in.Item)
+			}
+		}
+		return items
+	}
+	return valueItems(w.valueType(f))
+}
+
+// describe resolves name at the walker's position.
+func (w *walker) describe(name string) *Item {
+	if w.prev.Kind == lexer.DOLLAR {
+		return nil
+	}
+	if w.prev.Kind == lexer.AT {
+		if d := w.schema.Directive(name); d != nil {
+			return &Item{Kind: ItemDirective, Name: d.Name, Description: d.Description}
+		}
+		return nil
+	}
+
+	f := w.top()
+	switch {
+	case f == nil && w.typeCond,
+		f != nil && f.kind == frameSelection && w.typeCond,
+		f != nil && f.kind == frameVariables && f.inValue:
+		return typeItem(w.schema.Type(name))
+	case f == nil, f.kind == frameVariables:
+		return nil
+	case f.kind == frameSelection:
+		for _, item := range fieldItems(f.typ) {
+			if item.Name == name && w.prev.Kind != lexer.SPREAD {
+				return &item
+			}
+		}
+		return nil
+	case f.kind != frameList && !f.inValue:
+		for _, in := range w.inputs(f) {
+			if in.Name == name {
+				return &in.Item
+			}
+		}
+		return nil
+	}
+	for _, item := range valueItems(w.valueType(f)) {
+		if item.Name == name {
+			return &item
+		}
+	}
+	return nil
+}
+
+// named unwraps list and non-null wrappers.
+func named(t graphql.Type) graphql.Type {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func fieldDef(parent graphql.Type, name string) *graphql.FieldDefinition {
+	switch t := parent.(type) {
+	case *graphql.Object:
+		return t.Fields()[name]
+	case *graphql.Interface:
+		return t.Fields()[name]
+	}
+	return nil
+}
+
+// fieldItems lists the fields selectable on parent, including __typename.
+func fieldItems(parent graphql.Type) []Item {
+	var fields graphql.FieldDefinitionMap
+	switch t := parent.(type) {
+	case *graphql.Object:
+		fields = t.Fields()
+	case *graphql.Interface:
+		fields = t.Fields()
+	case *graphql.Union:
+	default:
+		return nil
+	}
+
+	var items []Item
+	for name, field := range fields {
+		items = append(items, Item{
+			Kind:        ItemField,
+			Name:        name,
+			Parent:      parent.Name(),
+			Detail:      signature(field),
+			Description: field.Description,
+			Deprecation: field.DeprecationReason,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return append(items, Item{Kind: ItemField, Name: "__typename", Parent: parent.Name(), Detail: "String!", Description: "The name of the object type"})
+}
+
+// signature renders a field's arguments and type, e.g. (id: ID!): User.
+func signature(field *graphql.FieldDefinition) string {
+	if len(field.Args) == 0 {
+		return field.Type.String()
+	}
+	args := make([]string, len(field.Args))
+	for i, a := range field.Args {
+		args[i] = a.Name() + ": " + a.Type.String()
+	}
+	return "(" + strings.Join(args, ", ") + "): " + field.Type.String()
+}
+
+// valueItems lists the literal values of an input type that are worth completing.
+func valueItems(t graphql.Type) []Item {
+	switch named := named(t).(type) {
+	case *graphql.Enum:
+		var items []Item
+		for _, v := range named.Values() {
+			items = append(items, Item{Kind: ItemEnumValue, Name: v.Name, Parent: named.Name(), Detail: named.Name(), Description: v.Description, Deprecation: v.DeprecationReason})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+		return items
+	case *graphql.Scalar:
+		if named.Name() == "Boolean" {
+			return keywords("true", "false")
+		}
+	}
+	return nil
+}
+
+// conditionItems lists the types a fragment inside a selection on parent may target.
+func (w *walker) conditionItems(parent graphql.Type) []Item {
+	var items []Item
+	if item := typeItem(parent); item != nil {
+		items = append(items, *item)
+	}
+	if graphql.IsAbstractType(parent) {
+		for _, obj := range w.schema.PossibleTypes(parent.(graphql.Abstract)) {
+			items = append(items, *typeItem(obj))
+		}
+	}
+	return items
+}
+
+// typeItems lists the schema's own types accepted by keep.
+func (w *walker) typeItems(keep func(graphql.Type) bool) []Item {
+	var items []Item
+	for name, t := range w.schema.TypeMap() {
+		if !strings.HasPrefix(name, "__") && keep(t) {
+			items = append(items, *typeItem(t))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+func isComposite(t graphql.Type) bool {
+	switch t.(type) {
+	case *graphql.Object, *graphql.Interface, *graphql.Union:
+		return true
+	}
+	return false
+}
+
+func isInput(t graphql.Type) bool {
+	switch t.(type) {
+	case *graphql.Scalar, *graphql.Enum, *graphql.InputObject:
+		return true
+	}
+	return false
+}
+
+func typeItem(t graphql.Type) *Item {
+	if t == nil {
+		return nil
+	}
+	kind := ""
+	switch t.(type) {
+	case *graphql.Object:
+		kind = "type"
+	case *graphql.Interface:
+		kind = "interface"
+	case *graphql.Union:
+		kind = "union"
+	case *graphql.Enum:
+		kind = "enum"
+	case *graphql.InputObject:
+		kind = "input"
+	case *graphql.Scalar:
+		kind = "scalar"
+	}
+	return &Item{Kind: ItemType, Name: t.Name(), Detail: kind, Description: t.Description()}
+}
+
+func keywords(words ...string) []Item {
+	items := make([]Item, len(words))
+	for i, word := range words {
+		items[i] = Item{Kind: ItemKeyword, Name: word}
+	}
+	return items
+}
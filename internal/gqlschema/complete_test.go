@@ -0,0 +1,105 @@
+package gqlschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func names(items []Item) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.Name
+	}
+	return out
+}
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := FromSDL(testSDL)
+	if err != nil {
+		t.Fatalf("FromSDL failed: %v", err)
+	}
+	return &Schema{Schema: s}
+}
+
+func TestComplete(t *testing.T) {
+	s := testSchema(t)
+
+	tests := []struct {
+		name  string
+		query string // | marks the cursor
+		want  []string
+	}{
+		{"root fields", "{ |", []string{"node", "now", "search", "user", "__typename"}},
+		{"prefix", "query { us|", []string{"user"}},
+		{"nested fields", `query { user(id: "1") { n| } }`, []string{"name"}},
+		{"mutation root", "mutation { |", []string{"createPost", "__typename"}},
+		{"arguments", "{ user(id: 1) { posts(|", []string{"first", "status"}},
+		{"used arguments skipped", "{ user(id: 1) { posts(first: 5, |", []string{"status"}},
+		{"enum values", "{ user(id: 1) { posts(status: |", []string{"DRAFT", "PUBLISHED"}},
+		{"input object fields", "mutation { createPost(input: { t|", []string{"title"}},
+		{"enum in input object", "mutation { createPost(input: { title: \"x\", status: P|", []string{"PUBLISHED"}},
+		{"type condition", "{ search(term: \"a\") { ... on |", []string{"SearchResult", "User", "Post"}},
+		{"inline fragment fields", "{ search(term: \"a\") { ... on Post { t|", []string{"title"}},
+		{"union has only __typename", "{ search(term: \"a\") { |", []string{"__typename"}},
+		{"variable types", "query Q($status: S|", []string{"Status", "String"}},
+		{"directives", "{ user(id: 1) @|", []string{"include", "skip", "deprecated", "cached"}},
+		{"directive arguments", "{ now @cached(|", []string{"ttl"}},
+		{"fragment definition", "fragment F on User { p|", []string{"posts"}},
+		{"after a closed selection", "{ user(id: 1) { id } |", []string{"node", "now", "search", "user", "__typename"}},
+		{"variable name", "{ user(id: $|", nil},
+		{"inside a string", `{ search(term: "ab|c") }`, nil},
+		{"keywords", "|", []string{"query", "mutation", "subscription", "fragment"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.query, "|")
+			query := strings.Replace(tt.query, "|", "", 1)
+			got := names(s.Complete(query, offset))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Complete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	s := testSchema(t)
+
+	query := `query Q($s: Status) {
+  user(id: "1") {
+    name
+    legacyId
+    posts(status: PUBLISHED) { title }
+  }
+}`
+	at := func(word string) int { return strings.Index(query, word) + 1 }
+
+	name := s.Describe(query, at("name"))
+	if name == nil || name.Kind != ItemField || name.Parent != "User" || name.Detail != "String" || name.Description != "Display name" {
+		t.Fatalf("Describe(name) = %+v", name)
+	}
+	if query[name.Start:name.End] != "name" {
+		t.Errorf("span = %q, want name", query[name.Start:name.End])
+	}
+
+	if legacy := s.Describe(query, at("legacyId")); legacy == nil || legacy.Deprecation != "Use id" {
+		t.Errorf("Describe(legacyId) = %+v", legacy)
+	}
+	if user := s.Describe(query, at("user")); user == nil || user.Detail != "(id: ID!): User" {
+		t.Errorf("Describe(user) = %+v", user)
+	}
+	if arg := s.Describe(query, at("status")); arg == nil || arg.Kind != ItemArgument || arg.Detail != "Status" {
+		t.Errorf("Describe(status) = %+v", arg)
+	}
+	if value := s.Describe(query, at("PUBLISHED")); value == nil || value.Kind != ItemEnumValue || value.Deprecation == "" {
+		t.Errorf("Describe(PUBLISHED) = %+v", value)
+	}
+	if typ := s.Describe(query, at("Status")); typ == nil || typ.Kind != ItemType || typ.Detail != "enum" {
+		t.Errorf("Describe(Status) = %+v", typ)
+	}
+	if got := s.Describe(query, at("Q(")); got != nil {
+		t.Errorf("expected no description for the operation name, got %+v", got)
+	}
+}
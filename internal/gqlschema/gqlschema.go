@@ -0,0 +1,465 @@
+// Package gqlschema builds GraphQL schemas from SDL files or introspection results
+// so queries can be checked and completed before they are sent.
+package gqlschema
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Schema is a GraphQL schema together with where it was loaded from.
+type Schema struct {
+	*graphql.Schema
+	Origin string // SDL or introspection file path, or the endpoint URL for cached introspection
+	Cached bool   // True if the schema came from the introspection cache
+}
+
+// maxBuiltSchemas bounds how many built schemas are kept in memory.
+const maxBuiltSchemas = 16
+
+// built memoizes recently used schemas by SDL digest, since the LSP reloads on every
+// keystroke. Editing a schema file produces a new digest each time, so the least
+// recently used schemas are evicted.
+var built = &schemaMemo{entries: make(map[[sha256.Size]byte]*graphql.Schema)}
+
+// schemaMemo is a small LRU cache of built schemas.
+type schemaMemo struct {
+	mu      sync.Mutex
+	order   [][sha256.Size]byte // Least recently used first
+	entries map[[sha256.Size]byte]*graphql.Schema
+}
+
+func (m *schemaMemo) get(digest [sha256.Size]byte) (*graphql.Schema, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.entries[digest]
+	if ok {
+		m.touch(digest)
+	}
+	return s, ok
+}
+
+func (m *schemaMemo) put(digest [sha256.Size]byte, s *graphql.Schema) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[digest]; ok {
+		m.touch(digest)
+		return
+	}
+	if len(m.order) >= maxBuiltSchemas {
+		delete(m.entries, m.order[0])
+		m.order = m.order[1:]
+	}
+	m.entries[digest] = s
+	m.order = append(m.order, digest)
+}
+
+// touch marks digest as the most recently used. The caller holds m.mu.
+func (m *schemaMemo) touch(digest [sha256.Size]byte) {
+	i := slices.Index(m.order, digest)
+	m.order = append(slices.Delete(m.order, i, i+1), digest)
+}
+
+// Load returns the schema for a GraphQL request. If ref is set it names an SDL file
+// (or an introspection result if it ends in .json), resolved against baseDir.
+// Otherwise the introspection cached for endpoint is used.
+// Returns nil with no error when no schema is available.
+func Load(ref, baseDir, endpoint string, store *Store) (*Schema, error) {
+	if ref != "" {
+		path := ref
+		if !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+		data, err := os.ReadFile(path) // #nosec G304 -- schema is a user-provided path
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema '%s': %w", ref, err)
+		}
+		sdl := string(data)
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			if sdl, err = SDLFromIntrospection(data); err != nil {
+				return nil, fmt.Errorf("schema '%s': %w", ref, err)
+			}
+		}
+		s, err := FromSDL(sdl)
+		if err != nil {
+			return nil, fmt.Errorf("schema '%s': %w", ref, err)
+		}
+		return &Schema{Schema: s, Origin: path}, nil
+	}
+
+	if store == nil || endpoint == "" {
+		return nil, nil
+	}
+	sdl, err := store.Load(endpoint)
+	if err != nil || sdl == "" {
+		return nil, err
+	}
+	s, err := FromSDL(sdl)
+	if err != nil {
+		return nil, fmt.Errorf("cached schema for %s: %w", endpoint, err)
+	}
+	return &Schema{Schema: s, Origin: endpoint, Cached: true}, nil
+}
+
+// FromSDL builds a schema from GraphQL SDL. Types, fields, arguments, enum values,
+// descriptions, deprecations and custom directives are kept; resolvers are not.
+func FromSDL(sdl string) (*graphql.Schema, error) {
+	digest := sha256.Sum256([]byte(sdl))
+	if s, ok := built.get(digest); ok {
+		return s, nil
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: sdl})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		defs:       make(map[string]ast.Node),
+		extensions: make(map[string][]*ast.FieldDefinition),
+		types:      make(map[string]graphql.Type),
+	}
+	s, err := b.build(doc)
+	if err != nil {
+		return nil, err
+	}
+	built.put(digest, s)
+	return s, nil
+}
+
+// builder converts SDL definitions to graphql-go types. Fields, interfaces and union
+// members are thunks, so types may reference each other in any order.
+type builder struct {
+	defs       map[string]ast.Node
+	order      []string
+	extensions map[string][]*ast.FieldDefinition
+	types      map[string]graphql.Type
+	errs       []error
+}
+
+func (b *builder) build(doc *ast.Document) (*graphql.Schema, error) {
+	roots := map[string]string{
+		ast.OperationTypeQuery:        "Query",
+		ast.OperationTypeMutation:     "Mutation",
+		ast.OperationTypeSubscription: "Subscription",
+	}
+	var directiveDefs []*ast.DirectiveDefinition
+
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.SchemaDefinition:
+			for _, op := range d.OperationTypes {
+				roots[op.Operation] = op.Type.Name.Value
+			}
+		case *ast.TypeExtensionDefinition:
+			name := d.Definition.Name.Value
+			b.extensions[name] = append(b.extensions[name], d.Definition.Fields...)
+		case *ast.DirectiveDefinition:
+			directiveDefs = append(directiveDefs, d)
+		case ast.TypeDefinition:
+			name := typeDefName(d)
+			if name == "" {
+				continue
+			}
+			if _, dup := b.defs[name]; dup {
+				return nil, fmt.Errorf("type %s is defined more than once", name)
+			}
+			b.defs[name] = d
+			b.order = append(b.order, name)
+		}
+	}
+
+	for _, name := range b.order {
+		b.types[name] = b.namedType(b.defs[name])
+	}
+
+	query, _ := b.types[roots[ast.OperationTypeQuery]].(*graphql.Object)
+	if query == nil {
+		return nil, errors.New("schema has no query type")
+	}
+	config := graphql.SchemaConfig{Query: query, Directives: b.directives(directiveDefs)}
+	if t, ok := b.types[roots[ast.OperationTypeMutation]].(*graphql.Object); ok {
+		config.Mutation = t
+	}
+	if t, ok := b.types[roots[ast.OperationTypeSubscription]].(*graphql.Object); ok {
+		config.Subscription = t
+	}
+	for _, name := range b.order {
+		config.Types = append(config.Types, b.types[name])
+	}
+
+	s, err := graphql.NewSchema(config)
+	if len(b.errs) > 0 {
+		return nil, b.errs[0]
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (b *builder) namedType(def ast.Node) graphql.Type {
+	switch d := def.(type) {
+	case *ast.ScalarDefinition:
+		if t := builtinScalar(d.Name.Value); t != nil {
+			return t
+		}
+		return graphql.NewScalar(graphql.ScalarConfig{
+			Name:         d.Name.Value,
+			Description:  description(d.Description),
+			Serialize:    func(v any) any { return v },
+			ParseValue:   func(v any) any { return v },
+			ParseLiteral: func(v ast.Value) any { return v }, // Accept any literal for custom scalars
+		})
+	case *ast.EnumDefinition:
+		values := graphql.EnumValueConfigMap{}
+		for _, v := range d.Values {
+			values[v.Name.Value] = &graphql.EnumValueConfig{
+				Value:             v.Name.Value,
+				Description:       description(v.Description),
+				DeprecationReason: deprecation(v.Directives),
+			}
+		}
+		return graphql.NewEnum(graphql.EnumConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Values:      values,
+		})
+	case *ast.ObjectDefinition:
+		fields := append(d.Fields, b.extensions[d.Name.Value]...)
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Fields:      graphql.FieldsThunk(func() graphql.Fields { return b.fields(fields) }),
+			Interfaces: graphql.InterfacesThunk(func() []*graphql.Interface {
+				var ifaces []*graphql.Interface
+				for _, n := range d.Interfaces {
+					if iface, ok := b.lookup(n.Name.Value).(*graphql.Interface); ok {
+						ifaces = append(ifaces, iface)
+					} else {
+						b.errs = append(b.errs, fmt.Errorf("type %s implements %s, which is not an interface", d.Name.Value, n.Name.Value))
+					}
+				}
+				return ifaces
+			}),
+		})
+	case *ast.InterfaceDefinition:
+		return graphql.NewInterface(graphql.InterfaceConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Fields:      graphql.FieldsThunk(func() graphql.Fields { return b.fields(d.Fields) }),
+			ResolveType: func(graphql.ResolveTypeParams) *graphql.Object { return nil },
+		})
+	case *ast.UnionDefinition:
+		return graphql.NewUnion(graphql.UnionConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Types: graphql.UnionTypesThunk(func() []*graphql.Object {
+				var members []*graphql.Object
+				for _, n := range d.Types {
+					if obj, ok := b.lookup(n.Name.Value).(*graphql.Object); ok {
+						members = append(members, obj)
+					} else {
+						b.errs = append(b.errs, fmt.Errorf("union %s member %s is not an object type", d.Name.Value, n.Name.Value))
+					}
+				}
+				return members
+			}),
+			ResolveType: func(graphql.ResolveTypeParams) *graphql.Object { return nil },
+		})
+	case *ast.InputObjectDefinition:
+		return graphql.NewInputObject(graphql.InputObjectConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Fields: graphql.InputObjectConfigFieldMapThunk(func() graphql.InputObjectConfigFieldMap {
+				fields := graphql.InputObjectConfigFieldMap{}
+				for _, f := range d.Fields {
+					fields[f.Name.Value] = &graphql.InputObjectFieldConfig{
+						Type:         b.typeRef(f.Type),
+						DefaultValue: defaultValue(f.DefaultValue),
+						Description:  description(f.Description),
+					}
+				}
+				return fields
+			}),
+		})
+	}
+	return nil
+}
+
+func (b *builder) fields(defs []*ast.FieldDefinition) graphql.Fields {
+	fields := graphql.Fields{}
+	for _, f := range defs {
+		fields[f.Name.Value] = &graphql.Field{
+			Type:              b.typeRef(f.Type),
+			Args:              b.args(f.Arguments),
+			Description:       description(f.Description),
+			DeprecationReason: deprecation(f.Directives),
+		}
+	}
+	return fields
+}
+
+func (b *builder) args(defs []*ast.InputValueDefinition) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for _, a := range defs {
+		args[a.Name.Value] = &graphql.ArgumentConfig{
+			Type:         b.typeRef(a.Type),
+			DefaultValue: defaultValue(a.DefaultValue),
+			Description:  description(a.Description),
+		}
+	}
+	return args
+}
+
+func (b *builder) directives(defs []*ast.DirectiveDefinition) []*graphql.Directive {
+	directives := append([]*graphql.Directive{}, graphql.SpecifiedDirectives...)
+	for _, d := range defs {
+		if isSpecifiedDirective(d.Name.Value) {
+			continue
+		}
+		var locations []string
+		for _, l := range d.Locations {
+			locations = append(locations, l.Value)
+		}
+		directives = append(directives, graphql.NewDirective(graphql.DirectiveConfig{
+			Name:        d.Name.Value,
+			Description: description(d.Description),
+			Locations:   locations,
+			Args:        b.args(d.Arguments),
+		}))
+	}
+	return directives
+}
+
+// typeRef resolves a type reference such as [ID!]! against the named types.
+func (b *builder) typeRef(t ast.Type) graphql.Type {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return graphql.NewNonNull(b.typeRef(t.Type))
+	case *ast.List:
+		return graphql.NewList(b.typeRef(t.Type))
+	case *ast.Named:
+		return b.lookup(t.Name.Value)
+	}
+	return nil
+}
+
+func (b *builder) lookup(name string) graphql.Type {
+	if t, ok := b.types[name]; ok {
+		return t
+	}
+	if t := builtinScalar(name); t != nil {
+		return t
+	}
+	b.errs = append(b.errs, fmt.Errorf("unknown type %s", name))
+	return graphql.String // Placeholder so schema construction can finish and report the error
+}
+
+func typeDefName(def ast.Node) string {
+	switch d := def.(type) {
+	case *ast.ScalarDefinition:
+		return d.Name.Value
+	case *ast.ObjectDefinition:
+		return d.Name.Value
+	case *ast.InterfaceDefinition:
+		return d.Name.Value
+	case *ast.UnionDefinition:
+		return d.Name.Value
+	case *ast.EnumDefinition:
+		return d.Name.Value
+	case *ast.InputObjectDefinition:
+		return d.Name.Value
+	}
+	return ""
+}
+
+func builtinScalar(name string) *graphql.Scalar {
+	switch name {
+	case "String":
+		return graphql.String
+	case "Int":
+		return graphql.Int
+	case "Float":
+		return graphql.Float
+	case "Boolean":
+		return graphql.Boolean
+	case "ID":
+		return graphql.ID
+	}
+	return nil
+}
+
+func isSpecifiedDirective(name string) bool {
+	for _, d := range graphql.SpecifiedDirectives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func description(s *ast.StringValue) string {
+	if s == nil {
+		return ""
+	}
+	return s.Value
+}
+
+// deprecation returns the @deprecated reason, or "" if the element is not deprecated.
+func deprecation(directives []*ast.Directive) string {
+	for _, d := range directives {
+		if d.Name.Value != "deprecated" {
+			continue
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name.Value == "reason" {
+				if s, ok := arg.Value.(*ast.StringValue); ok {
+					return s.Value
+				}
+			}
+		}
+		return graphql.DefaultDeprecationReason
+	}
+	return ""
+}
+
+// defaultValue keeps default values in their GraphQL source form for display.
+func defaultValue(v ast.Value) any {
+	if v == nil {
+		return nil
+	}
+	return printValue(v)
+}
+
+func printValue(v ast.Value) string {
+	switch v := v.(type) {
+	case *ast.StringValue:
+		return fmt.Sprintf("%q", v.Value)
+	case *ast.ListValue:
+		parts := make([]string, len(v.Values))
+		for i, item := range v.Values {
+			parts[i] = printValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *ast.ObjectValue:
+		parts := make([]string, len(v.Fields))
+		for i, f := range v.Fields {
+			parts[i] = f.Name.Value + ": " + printValue(f.Value)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *ast.Variable:
+		return "$" + v.Name.Value
+	}
+	return fmt.Sprintf("%v", v.GetValue())
+}
@@ -0,0 +1,239 @@
+package gqlschema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+const testSDL = `
+schema { query: Query mutation: Mutation }
+
+"An account holder"
+type User implements Node {
+  id: ID!
+  "Display name"
+  name: String
+  posts(first: Int = 10, status: Status): [Post!]!
+  legacyId: Int @deprecated(reason: "Use id")
+}
+
+type Post implements Node {
+  id: ID!
+  title: String!
+  author: User
+}
+
+interface Node { id: ID! }
+
+union SearchResult = User | Post
+
+enum Status { DRAFT PUBLISHED @deprecated }
+
+input NewPost { title: String!, status: Status = DRAFT }
+
+scalar DateTime
+
+directive @cached(ttl: Int) on FIELD
+
+type Query {
+  user(id: ID!): User
+  search(term: String!): [SearchResult!]!
+  now: DateTime
+}
+
+extend type Query { node(id: ID!): Node }
+
+type Mutation { createPost(input: NewPost!): Post }
+`
+
+func TestFromSDL(t *testing.T) {
+	s, err := FromSDL(testSDL)
+	if err != nil {
+		t.Fatalf("FromSDL failed: %v", err)
+	}
+
+	if s.MutationType() == nil || s.MutationType().Name() != "Mutation" {
+		t.Errorf("expected Mutation root type")
+	}
+	user, ok := s.Type("User").(*graphql.Object)
+	if !ok {
+		t.Fatalf("expected User object type")
+	}
+	if user.Description() != "An account holder" {
+		t.Errorf("User description = %q", user.Description())
+	}
+	fields := user.Fields()
+	if fields["name"].Description != "Display name" {
+		t.Errorf("name description = %q", fields["name"].Description)
+	}
+	if fields["legacyId"].DeprecationReason != "Use id" {
+		t.Errorf("legacyId deprecation = %q", fields["legacyId"].DeprecationReason)
+	}
+	if got := fields["posts"].Type.String(); got != "[Post!]!" {
+		t.Errorf("posts type = %s, want [Post!]!", got)
+	}
+	if _, ok := s.QueryType().Fields()["node"]; !ok {
+		t.Errorf("expected extend type Query to add node field")
+	}
+	if s.Directive("cached") == nil {
+		t.Errorf("expected custom directive @cached")
+	}
+}
+
+func TestFromSDL_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sdl     string
+		wantErr string
+	}{
+		{"unknown type", "type Query { a: Missing }", "unknown type Missing"},
+		{"no query type", "type Foo { a: Int }", "no query type"},
+		{"duplicate type", "type Query { a: Int } type Query { b: Int }", "defined more than once"},
+		{"syntax error", "type Query {", "Syntax Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromSDL(tt.sdl)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSDLFromIntrospection_RoundTrip(t *testing.T) {
+	s, err := FromSDL(testSDL)
+	if err != nil {
+		t.Fatalf("FromSDL failed: %v", err)
+	}
+	res := graphql.Do(graphql.Params{Schema: *s, RequestString: IntrospectionQuery})
+	if len(res.Errors) > 0 {
+		t.Fatalf("introspection failed: %v", res.Errors)
+	}
+	data, _ := json.Marshal(res)
+
+	sdl, err := SDLFromIntrospection(data)
+	if err != nil {
+		t.Fatalf("SDLFromIntrospection failed: %v", err)
+	}
+	rebuilt, err := FromSDL(sdl)
+	if err != nil {
+		t.Fatalf("rebuilding introspected SDL failed: %v\n%s", err, sdl)
+	}
+
+	for _, name := range []string{"User", "Post", "Node", "SearchResult", "Status", "NewPost", "DateTime", "Mutation"} {
+		if rebuilt.Type(name) == nil {
+			t.Errorf("type %s lost in round trip", name)
+		}
+	}
+	posts := rebuilt.Type("User").(*graphql.Object).Fields()["posts"]
+	if len(posts.Args) != 2 {
+		t.Errorf("posts args = %d, want 2", len(posts.Args))
+	}
+	if rebuilt.Directive("cached") == nil {
+		t.Errorf("directive @cached lost in round trip")
+	}
+}
+
+func TestSDLFromIntrospection_Errors(t *testing.T) {
+	_, err := SDLFromIntrospection([]byte(`{"errors":[{"message":"introspection disabled"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "introspection disabled") {
+		t.Errorf("expected server error to be reported, got %v", err)
+	}
+	if _, err := SDLFromIntrospection([]byte(`{"data":{}}`)); err == nil {
+		t.Errorf("expected error for missing __schema")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte(testSDL), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	store := NewStore(filepath.Join(dir, "cache"))
+
+	s, err := Load("schema.graphql", dir, "https://api.example.com/graphql", store)
+	if err != nil || s == nil {
+		t.Fatalf("Load from file failed: %v", err)
+	}
+	if s.Cached {
+		t.Errorf("expected schema file not to be reported as cached")
+	}
+
+	s, err = Load("", dir, "https://api.example.com/graphql", store)
+	if err != nil || s != nil {
+		t.Fatalf("expected no schema before introspection, got %v, %v", s, err)
+	}
+
+	if err := store.Save("https://api.example.com/graphql", testSDL); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	s, err = Load("", dir, "https://api.example.com/graphql", store)
+	if err != nil || s == nil || !s.Cached {
+		t.Fatalf("expected cached schema, got %v, %v", s, err)
+	}
+
+	if _, err := Load("missing.graphql", dir, "", store); err == nil {
+		t.Errorf("expected error for missing schema file")
+	}
+}
+
+func TestFromSDL_MemoIsBounded(t *testing.T) {
+	sdl := func(i int) string { return "type Query { field" + strings.Repeat("x", i) + ": String }" }
+	first, err := FromSDL(sdl(0))
+	if err != nil {
+		t.Fatalf("FromSDL failed: %v", err)
+	}
+	for i := 1; i < maxBuiltSchemas*2; i++ {
+		if _, err := FromSDL(sdl(i)); err != nil {
+			t.Fatalf("FromSDL failed: %v", err)
+		}
+	}
+	if n := len(built.entries); n > maxBuiltSchemas {
+		t.Errorf("memo holds %d schemas, want at most %d", n, maxBuiltSchemas)
+	}
+
+	// The most recent schema is reused; an evicted one is rebuilt
+	last, _ := FromSDL(sdl(maxBuiltSchemas*2 - 1))
+	if again, _ := FromSDL(sdl(maxBuiltSchemas*2 - 1)); again != last {
+		t.Errorf("expected the memoized schema to be reused")
+	}
+	if again, _ := FromSDL(sdl(0)); again == first {
+		t.Errorf("expected the least recently used schema to be evicted")
+	}
+}
+
+func TestOperationType(t *testing.T) {
+	query := `query A { a } subscription B { b }`
+	tests := []struct {
+		query, name, want string
+	}{
+		{query, "", "query"},
+		{query, "B", "subscription"},
+		{query, "C", ""},
+		{"{ a }", "", "query"},
+		{"mutation { a }", "", "mutation"},
+		{"query {", "", ""},
+	}
+	for _, tt := range tests {
+		if got := OperationType(tt.query, tt.name); got != tt.want {
+			t.Errorf("OperationType(%q, %q) = %q, want %q", tt.query, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOperationNames(t *testing.T) {
+	got := OperationNames(`query A { a } fragment F on T { f } { b } mutation C { c }`)
+	if strings.Join(got, ",") != "A,,C" {
+		t.Errorf("OperationNames() = %q, want [A  C]", got)
+	}
+	if got := OperationNames("query {"); got != nil {
+		t.Errorf("OperationNames() = %q for an invalid query, want nil", got)
+	}
+}
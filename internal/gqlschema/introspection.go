@@ -0,0 +1,277 @@
+package gqlschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IntrospectionQuery fetches everything needed to rebuild a schema as SDL.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+type introspectionResponse struct {
+	Data   *introspectionData   `json:"data"`
+	Schema *introspectionSchema `json:"__schema"` // Bare result without the response envelope
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type introspectionData struct {
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	QueryType        *typeRef                 `json:"queryType"`
+	MutationType     *typeRef                 `json:"mutationType"`
+	SubscriptionType *typeRef                 `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
+}
+
+type introspectionType struct {
+	Kind          string               `json:"kind"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	Fields        []introspectionField `json:"fields"`
+	InputFields   []inputValue         `json:"inputFields"`
+	Interfaces    []typeRef            `json:"interfaces"`
+	EnumValues    []enumValue          `json:"enumValues"`
+	PossibleTypes []typeRef            `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Args              []inputValue `json:"args"`
+	Type              typeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason string       `json:"deprecationReason"`
+}
+
+type inputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         typeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type enumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+type introspectionDirective struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Locations   []string     `json:"locations"`
+	Args        []inputValue `json:"args"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (t typeRef) String() string {
+	switch t.Kind {
+	case "NON_NULL":
+		if t.OfType != nil {
+			return t.OfType.String() + "!"
+		}
+	case "LIST":
+		if t.OfType != nil {
+			return "[" + t.OfType.String() + "]"
+		}
+	}
+	return t.Name
+}
+
+// SDLFromIntrospection renders an introspection result as SDL. It accepts either the
+// full response ({"data": {"__schema": ...}}) or the bare {"__schema": ...} object.
+func SDLFromIntrospection(data []byte) (string, error) {
+	var resp introspectionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("invalid introspection result: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return "", fmt.Errorf("introspection failed: %s", resp.Errors[0].Message)
+	}
+	schema := resp.Schema
+	if resp.Data != nil && resp.Data.Schema != nil {
+		schema = resp.Data.Schema
+	}
+	if schema == nil {
+		return "", errors.New("introspection result has no __schema")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("schema {\n")
+	for _, root := range []struct {
+		op  string
+		ref *typeRef
+	}{{"query", schema.QueryType}, {"mutation", schema.MutationType}, {"subscription", schema.SubscriptionType}} {
+		if root.ref != nil && root.ref.Name != "" {
+			fmt.Fprintf(&sb, "  %s: %s\n", root.op, root.ref.Name)
+		}
+	}
+	sb.WriteString("}\n")
+
+	for _, d := range schema.Directives {
+		if isSpecifiedDirective(d.Name) || len(d.Locations) == 0 {
+			continue
+		}
+		sb.WriteString("\n")
+		writeDescription(&sb, "", d.Description)
+		fmt.Fprintf(&sb, "directive @%s%s on %s\n", d.Name, argsSDL(d.Args), strings.Join(d.Locations, " | "))
+	}
+
+	for _, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") || builtinScalar(t.Name) != nil {
+			continue
+		}
+		sb.WriteString("\n")
+		writeDescription(&sb, "", t.Description)
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&sb, "scalar %s\n", t.Name)
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&sb, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, len(t.Interfaces))
+				for i, iface := range t.Interfaces {
+					names[i] = iface.Name
+				}
+				sb.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			sb.WriteString(" {\n")
+			for _, f := range t.Fields {
+				writeDescription(&sb, "  ", f.Description)
+				fmt.Fprintf(&sb, "  %s%s: %s%s\n", f.Name, argsSDL(f.Args), f.Type, deprecatedSDL(f.IsDeprecated, f.DeprecationReason))
+			}
+			sb.WriteString("}\n")
+		case "UNION":
+			names := make([]string, len(t.PossibleTypes))
+			for i, p := range t.PossibleTypes {
+				names[i] = p.Name
+			}
+			fmt.Fprintf(&sb, "union %s = %s\n", t.Name, strings.Join(names, " | "))
+		case "ENUM":
+			fmt.Fprintf(&sb, "enum %s {\n", t.Name)
+			for _, v := range t.EnumValues {
+				writeDescription(&sb, "  ", v.Description)
+				fmt.Fprintf(&sb, "  %s%s\n", v.Name, deprecatedSDL(v.IsDeprecated, v.DeprecationReason))
+			}
+			sb.WriteString("}\n")
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&sb, "input %s {\n", t.Name)
+			for _, f := range t.InputFields {
+				writeDescription(&sb, "  ", f.Description)
+				fmt.Fprintf(&sb, "  %s\n", inputValueSDL(f))
+			}
+			sb.WriteString("}\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func argsSDL(args []inputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, a := range args {
+		parts[i] = inputValueSDL(a)
+		if a.Description != "" {
+			parts[i] = quote(a.Description) + " " + parts[i]
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func inputValueSDL(v inputValue) string {
+	s := v.Name + ": " + v.Type.String()
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func deprecatedSDL(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + quote(reason) + ")"
+}
+
+func writeDescription(sb *strings.Builder, indent, desc string) {
+	if desc != "" {
+		sb.WriteString(indent + quote(desc) + "\n")
+	}
+}
+
+// quote renders s as a GraphQL string literal; JSON string escapes are valid GraphQL.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
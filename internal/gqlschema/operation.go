@@ -0,0 +1,48 @@
+package gqlschema
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// OperationType returns the type (query, mutation or subscription) of the operation
+// named name in query, or of its first operation if name is empty. It returns "" if
+// the query does not parse or has no such operation.
+func OperationType(query, name string) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return ""
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" || (op.Name != nil && op.Name.Value == name) {
+			return op.Operation
+		}
+	}
+	return ""
+}
+
+// OperationNames returns the names of the operations in query, in document order,
+// with "" for anonymous ones. It returns nil if the query does not parse.
+func OperationNames(query string) []string {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		name := ""
+		if op.Name != nil {
+			name = op.Name.Value
+		}
+		names = append(names, name)
+	}
+	return names
+}
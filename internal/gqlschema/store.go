@@ -0,0 +1,63 @@
+package gqlschema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"yapi.run/cli/internal/httpcache"
+)
+
+// DefaultDir keeps introspected schemas alongside cached responses
+var DefaultDir = filepath.Join(httpcache.DefaultDir, "graphql")
+
+// Store caches introspected schemas as SDL, one file per endpoint URL.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns a schema store in dir, falling back to DefaultDir.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{Dir: dir}
+}
+
+// Key returns the cache key for an endpoint.
+func Key(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the file that holds the cached schema for endpoint.
+func (s *Store) Path(endpoint string) string {
+	return filepath.Join(s.Dir, Key(endpoint)+".graphql")
+}
+
+// Load returns the cached SDL for endpoint, or "" if there is none.
+func (s *Store) Load(endpoint string) (string, error) {
+	data, err := os.ReadFile(s.Path(endpoint))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read cached schema: %w", err)
+	}
+	return string(data), nil
+}
+
+// Save stores the SDL for endpoint.
+func (s *Store) Save(endpoint, sdl string) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create schema cache directory: %w", err)
+	}
+	header := fmt.Sprintf("# Introspected from %s\n", endpoint)
+	if err := os.WriteFile(s.Path(endpoint), []byte(header+sdl), 0600); err != nil {
+		return fmt.Errorf("failed to write cached schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package httpcache stores HTTP response validators so later runs can send conditional requests.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDir is the directory used for cached responses
+var DefaultDir = filepath.Join(os.Getenv("HOME"), ".yapi", "cache")
+
+// Entry is a cached response together with its validators.
+type Entry struct {
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	StatusCode   int               `json:"status_code"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         []byte            `json:"body"`
+	StoredAt     time.Time         `json:"stored_at"`
+	Vary         []string          `json:"vary,omitempty"`        // Request headers named by the response's Vary header
+	VaryValues   map[string]string `json:"vary_values,omitempty"` // Their values in the request that was stored
+}
+
+// Store is a file-backed cache with one JSON file per method and URL. A response
+// with a Vary header is stored once per combination of the headers it names, and
+// the entry for the method and URL only records which headers those are.
+type Store struct {
+	Dir string
+}
+
+// NewStore creates a store rooted at dir, or DefaultDir if dir is empty.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{Dir: dir}
+}
+
+// Key returns the cache key for a request. varyValues holds the request header
+// values selected by the response's Vary header, nil if it does not vary.
+func Key(method, url string, varyValues map[string]string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(method) + " " + url)
+	names := make([]string, 0, len(varyValues))
+	for name := range varyValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n" + name + ": " + varyValues[name])
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) path(method, url string, varyValues map[string]string) string {
+	return filepath.Join(s.Dir, Key(method, url, varyValues)+".json")
+}
+
+// Load returns the cached entry for a request with the given headers, or nil if
+// there is none.
+func (s *Store) Load(method, url string, headers map[string]string) (*Entry, error) {
+	e, err := s.load(s.path(method, url, nil))
+	if err != nil || e == nil || len(e.Vary) == 0 {
+		return e, err
+	}
+	return s.load(s.path(method, url, SelectVary(e.Vary, headers)))
+}
+
+func (s *Store) load(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		// A corrupt entry is treated as a miss and overwritten on the next store
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// Save writes an entry to the store.
+func (s *Store) Save(e *Entry) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if len(e.Vary) == 0 {
+		return s.write(s.path(e.Method, e.URL, nil), e)
+	}
+	// Record which headers select the variant, then store the variant itself
+	index := &Entry{Method: e.Method, URL: e.URL, Vary: e.Vary, StoredAt: e.StoredAt}
+	if err := s.write(s.path(e.Method, e.URL, nil), index); err != nil {
+		return err
+	}
+	return s.write(s.path(e.Method, e.URL, e.VaryValues), e)
+}
+
+func (s *Store) write(path string, e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// ConditionalHeaders returns the If-None-Match / If-Modified-Since headers for revalidating e.
+func (e *Entry) ConditionalHeaders() map[string]string {
+	h := make(map[string]string)
+	if e.ETag != "" {
+		h["If-None-Match"] = e.ETag
+	}
+	if e.LastModified != "" {
+		h["If-Modified-Since"] = e.LastModified
+	}
+	return h
+}
+
+// Storable reports whether a response with these headers may be cached:
+// it must carry a validator, must not be marked no-store and must not vary on
+// everything (Vary: *). A response to a request with an Authorization header is
+// only stored if it is marked public, so it is never served to another user.
+func Storable(reqHeaders, respHeaders map[string]string) bool {
+	if respHeaders["Etag"] == "" && respHeaders["Last-Modified"] == "" {
+		return false
+	}
+	directives := ParseCacheControl(respHeaders["Cache-Control"])
+	if _, noStore := directives["no-store"]; noStore {
+		return false
+	}
+	if slices.Contains(ParseVary(respHeaders["Vary"]), "*") {
+		return false
+	}
+	if headerValue(reqHeaders, "Authorization") != "" {
+		_, public := directives["public"]
+		return public
+	}
+	return true
+}
+
+// ParseVary returns the canonical header names listed in a Vary header.
+func ParseVary(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+		}
+	}
+	return names
+}
+
+// SelectVary returns the values of the named request headers; a missing header
+// selects the empty value.
+func SelectVary(names []string, headers map[string]string) map[string]string {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = headerValue(headers, name)
+	}
+	return values
+}
+
+// headerValue looks up a header by name, ignoring case.
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// ParseCacheControl parses a Cache-Control header into its directives.
+// Directives without a value map to true, numeric values to int64 and
+// everything else to the (unquoted) string value. Names are lowercased.
+func ParseCacheControl(value string) map[string]any {
+	directives := make(map[string]any)
+	for _, part := range splitDirectives(value) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, val, hasVal := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !hasVal {
+			directives[name] = true
+			continue
+		}
+		val = strings.TrimSpace(val)
+		if unquoted, err := strconv.Unquote(val); err == nil && strings.HasPrefix(val, `"`) {
+			directives[name] = unquoted
+			continue
+		}
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			directives[name] = n
+			continue
+		}
+		directives[name] = val
+	}
+	return directives
+}
+
+// splitDirectives splits on commas that are not inside a quoted string,
+// e.g. `no-cache="Set-Cookie, X-Id", max-age=60`.
+func splitDirectives(value string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, value[start:])
+}
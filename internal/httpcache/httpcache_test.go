@@ -0,0 +1,127 @@
+package httpcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]any
+	}{
+		{"", map[string]any{}},
+		{"no-store", map[string]any{"no-store": true}},
+		{"public, max-age=60, s-maxage=300", map[string]any{"public": true, "max-age": int64(60), "s-maxage": int64(300)}},
+		{"Max-Age=0, Must-Revalidate", map[string]any{"max-age": int64(0), "must-revalidate": true}},
+		{`private="Set-Cookie, X-Id", stale-while-revalidate=30`, map[string]any{"private": "Set-Cookie, X-Id", "stale-while-revalidate": int64(30)}},
+		{"max-age=abc", map[string]any{"max-age": "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := ParseCacheControl(tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCacheControl(%q) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorable(t *testing.T) {
+	auth := map[string]string{"authorization": "Bearer a"}
+	tests := []struct {
+		name    string
+		req     map[string]string
+		headers map[string]string
+		want    bool
+	}{
+		{"etag", nil, map[string]string{"Etag": `"v1"`}, true},
+		{"last-modified", nil, map[string]string{"Last-Modified": "Wed, 21 Oct 2015 07:28:00 GMT"}, true},
+		{"no validator", nil, map[string]string{"Cache-Control": "max-age=60"}, false},
+		{"no-store", nil, map[string]string{"Etag": `"v1"`, "Cache-Control": "no-store"}, false},
+		{"vary star", nil, map[string]string{"Etag": `"v1"`, "Vary": "*"}, false},
+		{"authorized", auth, map[string]string{"Etag": `"v1"`, "Cache-Control": "max-age=60"}, false},
+		{"authorized public", auth, map[string]string{"Etag": `"v1"`, "Cache-Control": "public, max-age=60"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Storable(tt.req, tt.headers); got != tt.want {
+				t.Errorf("Storable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if e, err := store.Load("GET", "https://example.com/a", nil); err != nil || e != nil {
+		t.Fatalf("expected miss on empty store, got %v, %v", e, err)
+	}
+
+	entry := &Entry{
+		Method:       "GET",
+		URL:          "https://example.com/a",
+		ETag:         `"abc"`,
+		LastModified: "Wed, 21 Oct 2015 07:28:00 GMT",
+		StatusCode:   200,
+		Headers:      map[string]string{"Content-Type": "application/json"},
+		Body:         []byte(`{"ok":true}`),
+		StoredAt:     time.Unix(1700000000, 0).UTC(),
+	}
+	if err := store.Save(entry); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := store.Load("get", "https://example.com/a", nil)
+	if err != nil || got == nil {
+		t.Fatalf("expected hit, got %v, %v", got, err)
+	}
+	if !reflect.DeepEqual(got, entry) {
+		t.Errorf("Load() = %+v, want %+v", got, entry)
+	}
+
+	if e, _ := store.Load("GET", "https://example.com/b", nil); e != nil {
+		t.Errorf("expected miss for different URL, got %+v", e)
+	}
+
+	want := map[string]string{"If-None-Match": `"abc"`, "If-Modified-Since": "Wed, 21 Oct 2015 07:28:00 GMT"}
+	if h := got.ConditionalHeaders(); !reflect.DeepEqual(h, want) {
+		t.Errorf("ConditionalHeaders() = %v, want %v", h, want)
+	}
+}
+
+func TestStore_Vary(t *testing.T) {
+	store := NewStore(t.TempDir())
+	const url = "https://example.com/me"
+	for _, user := range []string{"alice", "bob"} {
+		entry := &Entry{
+			Method:     "GET",
+			URL:        url,
+			ETag:       `"` + user + `"`,
+			StatusCode: 200,
+			Body:       []byte(user),
+			Vary:       ParseVary("authorization, Accept-Language"),
+			VaryValues: SelectVary([]string{"Authorization", "Accept-Language"}, map[string]string{"Authorization": "Bearer " + user}),
+		}
+		if err := store.Save(entry); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	for _, user := range []string{"alice", "bob"} {
+		got, err := store.Load("GET", url, map[string]string{"authorization": "Bearer " + user})
+		if err != nil || got == nil || string(got.Body) != user {
+			t.Errorf("Load for %s = %+v, %v; want its own variant", user, got, err)
+		}
+	}
+	if got, _ := store.Load("GET", url, map[string]string{"Authorization": "Bearer eve"}); got != nil {
+		t.Errorf("expected miss for another user, got %+v", got)
+	}
+	if got, _ := store.Load("GET", url, map[string]string{"Authorization": "Bearer alice", "Accept-Language": "fr"}); got != nil {
+		t.Errorf("expected miss for another language, got %+v", got)
+	}
+}
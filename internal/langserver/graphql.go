@@ -0,0 +1,178 @@
+package langserver
+
+import (
+	"fmt"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/gqlschema"
+	"yapi.run/cli/internal/validation"
+)
+
+// loadGraphQLSchema loads the schema used for GraphQL completion: the request's
+// `schema` file or the introspection cached for its URL. Load errors are already
+// reported as diagnostics, so they just disable completion.
+func loadGraphQLSchema(req *domain.Request, dir string) *gqlschema.Schema {
+	if req.Metadata["graphql_query"] == "" {
+		return nil
+	}
+	schema, err := gqlschema.Load(req.Metadata["graphql_schema"], dir, req.URL, gqlschema.NewStore(""))
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+// graphqlBlock is the block-scalar query of a document, with the indentation
+// stripped so document positions can be mapped to query offsets and back.
+type graphqlBlock struct {
+	line   int      // Document line of the first query line
+	indent int      // Block indentation
+	lines  []string // Query lines without indentation
+}
+
+func findGraphQLBlock(text string) *graphqlBlock {
+	start, indent := validation.GraphQLQueryStart(text)
+	if start < 0 || indent < 0 {
+		return nil // No query, or an inline one
+	}
+	docLines := strings.Split(text, "\n")
+	b := &graphqlBlock{line: start, indent: indent}
+	for _, l := range docLines[start:] {
+		if strings.TrimSpace(l) == "" {
+			b.lines = append(b.lines, "")
+			continue
+		}
+		if len(l)-len(strings.TrimLeft(l, " \t")) < indent {
+			break
+		}
+		b.lines = append(b.lines, l[indent:])
+	}
+	return b
+}
+
+func (b *graphqlBlock) query() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// offset maps a document position to an offset in the query.
+func (b *graphqlBlock) offset(line, char int) (int, bool) {
+	i := line - b.line
+	if i < 0 || i >= len(b.lines) {
+		return 0, false
+	}
+	offset := 0
+	for _, l := range b.lines[:i] {
+		offset += len(l) + 1
+	}
+	col := min(max(char-b.indent, 0), len(b.lines[i]))
+	return offset + col, true
+}
+
+// position maps a query offset back to a document position.
+func (b *graphqlBlock) position(offset int) protocol.Position {
+	line := 0
+	for line < len(b.lines)-1 && offset > len(b.lines[line]) {
+		offset -= len(b.lines[line]) + 1
+		line++
+	}
+	return protocol.Position{Line: protocol.UInteger(b.line + line), Character: protocol.UInteger(b.indent + offset)}
+}
+
+// completeGraphQL completes fields, arguments, enum values and types inside the
+// `graphql:` block. inQuery reports whether the position is inside the query at all.
+func completeGraphQL(doc *document, line, char int) (items []protocol.CompletionItem, inQuery bool) {
+	block := findGraphQLBlock(doc.Text)
+	if block == nil {
+		return nil, false
+	}
+	offset, ok := block.offset(line, char)
+	if !ok {
+		return nil, false
+	}
+	if doc.Schema == nil {
+		return nil, true
+	}
+
+	for _, item := range doc.Schema.Complete(block.query(), offset) {
+		ci := protocol.CompletionItem{
+			Label:      item.Name,
+			Kind:       ptr(completionKind(item.Kind)),
+			InsertText: ptr(item.Name),
+		}
+		if item.Detail != "" {
+			ci.Detail = ptr(item.Detail)
+		}
+		if item.Description != "" {
+			ci.Documentation = item.Description
+		}
+		if item.Kind == gqlschema.ItemArgument {
+			ci.InsertText = ptr(item.Name + ": ")
+		}
+		if item.Deprecation != "" {
+			ci.Tags = []protocol.CompletionItemTag{protocol.CompletionItemTagDeprecated}
+		}
+		items = append(items, ci)
+	}
+	return items, true
+}
+
+func completionKind(k gqlschema.ItemKind) protocol.CompletionItemKind {
+	switch k {
+	case gqlschema.ItemField:
+		return protocol.CompletionItemKindField
+	case gqlschema.ItemArgument:
+		return protocol.CompletionItemKindProperty
+	case gqlschema.ItemType:
+		return protocol.CompletionItemKindClass
+	case gqlschema.ItemEnumValue:
+		return protocol.CompletionItemKindEnumMember
+	}
+	return protocol.CompletionItemKindKeyword
+}
+
+// hoverGraphQL describes the field, argument, type or enum value under the cursor.
+func hoverGraphQL(doc *document, line, char int) *protocol.Hover {
+	if doc.Schema == nil {
+		return nil
+	}
+	block := findGraphQLBlock(doc.Text)
+	if block == nil {
+		return nil
+	}
+	offset, ok := block.offset(line, char)
+	if !ok {
+		return nil
+	}
+	item := doc.Schema.Describe(block.query(), offset)
+	if item == nil {
+		return nil
+	}
+
+	name := item.Name
+	if item.Parent != "" && item.Kind != gqlschema.ItemEnumValue {
+		name = item.Parent + "." + name
+	}
+	content := fmt.Sprintf("**%s: `%s`**", item.Kind, name)
+	if item.Detail != "" {
+		content += fmt.Sprintf("\n\n`%s`", item.Detail)
+	}
+	if item.Description != "" {
+		content += "\n\n" + item.Description
+	}
+	if item.Deprecation != "" {
+		content += "\n\n_Deprecated: " + item.Deprecation + "_"
+	}
+
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.MarkupKindMarkdown,
+			Value: content,
+		},
+		Range: &protocol.Range{
+			Start: block.position(item.Start),
+			End:   block.position(item.End),
+		},
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"yapi.run/cli/internal/compiler"
 	"yapi.run/cli/internal/config"
 	"yapi.run/cli/internal/constants"
+	"yapi.run/cli/internal/gqlschema"
 	"yapi.run/cli/internal/utils"
 	"yapi.run/cli/internal/validation"
 	"yapi.run/cli/internal/vars"
@@ -35,6 +36,7 @@ type document struct {
 	Text        string
 	ProjectRoot string                  // Path to project root (if found)
 	Project     *config.ProjectConfigV1 // Project config (if found)
+	Schema      *gqlschema.Schema       // GraphQL schema for the query (if any)
 }
 
 // Run starts the yapi language server over stdio.
@@ -72,7 +74,7 @@ func initialize(ctx *glsp.Context, params *protocol.InitializeParams) (any, erro
 	}
 
 	capabilities.CompletionProvider = &protocol.CompletionOptions{
-		TriggerCharacters: []string{":", " ", "\n"},
+		TriggerCharacters: []string{":", " ", "\n", "{", "(", "@"},
 	}
 
 	capabilities.HoverProvider = true
@@ -193,16 +195,25 @@ func validateAndNotify(ctx *glsp.Context, uri protocol.DocumentUri, text string)
 	var analysis *validation.Analysis
 	var err error
 
+	// Relative paths (e.g. a GraphQL schema file) resolve against the document's directory
+	dir := ""
+	if filePath := uriToPath(uri); filePath != "" {
+		dir = filepath.Dir(filePath)
+	}
+
 	// Use project-aware validation if available
 	if ok && doc.Project != nil {
-		analysis, err = validation.AnalyzeConfigStringWithProject(text, doc.Project, doc.ProjectRoot)
+		analysis, err = validation.AnalyzeConfigStringInDir(text, doc.Project, doc.ProjectRoot, dir)
 	} else {
-		analysis, err = validation.AnalyzeConfigString(text)
+		analysis, err = validation.AnalyzeConfigStringInDir(text, nil, "", dir)
 	}
 
 	if err != nil || analysis == nil {
 		analysis, err = validation.AnalyzeConfigString(text)
 	}
+	if ok && err == nil && analysis.Request != nil {
+		doc.Schema = loadGraphQLSchema(analysis.Request, dir)
+	}
 	if err != nil {
 		// Catastrophic error - send one diagnostic and bail
 		ctx.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
@@ -426,7 +437,7 @@ var topLevelKeys = []struct {
 }{
 	{"url", "The target URL (required)"},
 	{"path", "URL path to append"},
-	{"method", "HTTP method or protocol (GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, grpc, tcp)"},
+	{"method", "HTTP method or protocol (GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, grpc, tcp, udp)"},
 	{"headers", "HTTP headers as key-value pairs"},
 	{"content_type", "Content-Type header value"},
 	{"body", "Request body as key-value pairs"},
@@ -434,18 +445,48 @@ var topLevelKeys = []struct {
 	{"query", "Query parameters as key-value pairs"},
 	{"graphql", "GraphQL query or mutation (multiline string)"},
 	{"variables", "GraphQL variables as key-value pairs"},
+	{"schema", "GraphQL SDL or introspection JSON file used to validate the query"},
+	{"events", "Number of GraphQL subscription events to collect before unsubscribing"},
+	{"uploads", "GraphQL Upload variables mapped to local files, sent as a multipart request"},
+	{"persisted_query", "Send the GraphQL query as an Automatic Persisted Query hash first"},
+	{"operation_name", "GraphQL operation to run when the document defines several"},
+	{"batch", "Batched GraphQL operations: list of name / operation_name / variables; results are keyed by name"},
 	{"service", "gRPC service name"},
 	{"rpc", "gRPC method name"},
 	{"proto", "Path to .proto file"},
 	{"proto_path", "Import path for proto files"},
-	{"data", "Raw data for TCP requests"},
+	{"data", "Raw data for TCP/UDP requests"},
 	{"encoding", "Data encoding (text, hex, base64)"},
 	{"jq_filter", "JQ filter to apply to response"},
 	{"insecure", "Skip TLS verification for HTTP/GraphQL; use insecure transport for gRPC (boolean)"},
 	{"plaintext", "Use plaintext gRPC (boolean)"},
-	{"read_timeout", "TCP read timeout in seconds"},
-	{"close_after_send", "Close TCP connection after sending (boolean)"},
+	{"read_timeout", "TCP/UDP read timeout in seconds"},
+	{"close_after_send", "Close TCP connection after sending; for UDP, don't wait for a reply (boolean)"},
+	{"tls", "TLS settings for tls:// and tcps:// URLs (server_name, ca_cert, client_cert, client_key, alpn)"},
+	{"conversation", "Scripted TCP exchanges: list of send / expect_regex / expect_literal / expect_bytes / timeout steps"},
+	{"framing", "TCP response framing (type: delimiter, fixed, length_prefix with prefix_bytes and byte_order)"},
+	{"datagrams", "Number of UDP reply datagrams to await (default 1)"},
 	{"delay", "Wait before executing this step (e.g. 5s, 500ms)"},
+	{"depends_on", "Chain steps this step waits for, besides those it references (list of step names)"},
+	{"when", "Run this chain step only if the jq condition is true (e.g. .lookup.status == 404)"},
+	{"skip_if", "Skip this chain step if the jq condition is true"},
+	{"wait_until", "Re-run this chain step until a jq condition on its response is true (condition, interval, backoff, max_interval, timeout)"},
+	{"capture", "Name values from this chain step's response with jq (name: expression), referenced as ${step.name}"},
+	{"chain_outputs", "Values reported as the chain's result (name: reference, e.g. ${create.user_id})"},
+	{"call", "Run another yapi file as this chain step, relative to this file; a called chain's chain_outputs become the step's response"},
+	{"with", "Parameters for a `call` step (name: value), read by the called file as ${name}"},
+	{"continue_on_error", "Keep running the chain, and let it succeed, when this step fails"},
+	{"finally", "Teardown steps that run in order after the chain, even when it failed"},
+	{"chain_timeout", "Time limit for the whole chain (e.g., 2m); running steps are cancelled and finally steps still run"},
+	{"matrix", "Run the request or chain once per row: a list of rows, or a .csv, .json or .jsonl file; row fields are ${field}"},
+	{"foreach", "Run this chain step once per item of a jq array (e.g. .list.body.items) or a list; use ${item} and ${index}"},
+	{"parallel", "Run up to this many independent chain steps, or foreach iterations, at once (default 1: in order)"},
+	{"output_file", "Save the response body to a file (streamed to disk)"},
+	{"output_resume", "Resume a partial output_file download with an HTTP Range request (boolean)"},
+	{"output_sha256", "Expected SHA-256 hex digest of the downloaded output_file"},
+	{"response_encoding", "Expose the response body as a hex or base64 string for assertions (hex, base64)"},
+	{"cache", "Cache ETag/Last-Modified and send conditional requests on later runs (boolean)"},
+	{"auth", "Request signing (type: aws_sigv4 with region and service, or hmac with secret and template)"},
 }
 
 var methodValues = []valDesc{
@@ -483,6 +524,10 @@ func textDocumentCompletion(ctx *glsp.Context, params *protocol.CompletionParams
 		return nil, nil
 	}
 
+	if items, inQuery := completeGraphQL(doc, int(line), int(char)); inQuery {
+		return items, nil
+	}
+
 	currentLine := lines[line]
 	textBeforeCursor := ""
 	if int(char) <= len(currentLine) {
@@ -587,7 +632,7 @@ func textDocumentHover(ctx *glsp.Context, params *protocol.HoverParams) (*protoc
 		}
 	}
 
-	return nil, nil
+	return hoverGraphQL(doc, line, char), nil
 }
 
 func textDocumentDefinition(ctx *glsp.Context, params *protocol.DefinitionParams) (any, error) {
@@ -597,14 +642,19 @@ func textDocumentDefinition(ctx *glsp.Context, params *protocol.DefinitionParams
 		return nil, nil
 	}
 
+	line := int(params.Position.Line)
+	char := int(params.Position.Character)
+
+	// A chain step's `call` value jumps to the called file
+	if location := findCallDefinition(uri, doc.Text, line); location != nil {
+		return location, nil
+	}
+
 	// No project context - can't find definitions
 	if doc.Project == nil {
 		return nil, nil
 	}
 
-	line := int(params.Position.Line)
-	char := int(params.Position.Character)
-
 	// Find all env var references in the document
 	refs := validation.FindEnvVarRefs(doc.Text)
 
@@ -629,6 +679,36 @@ func textDocumentDefinition(ctx *glsp.Context, params *protocol.DefinitionParams
 	return nil, nil
 }
 
+// findCallDefinition returns the start of the file called on the given line, if the
+// line is a chain step's `call`. Paths resolve against the document's directory.
+func findCallDefinition(uri protocol.DocumentUri, text string, line int) *protocol.Location {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return nil
+	}
+	trimmed := strings.TrimPrefix(strings.TrimSpace(lines[line]), "- ")
+	value, ok := strings.CutPrefix(trimmed, "call:")
+	if !ok {
+		return nil
+	}
+	target := strings.Trim(strings.TrimSpace(value), `"'`)
+	if target == "" {
+		return nil
+	}
+
+	docPath := uriToPath(uri)
+	if !filepath.IsAbs(target) && docPath != "" {
+		target = filepath.Join(filepath.Dir(docPath), target)
+	}
+	if _, err := os.Stat(target); err != nil {
+		return nil
+	}
+	return &protocol.Location{
+		URI:   protocol.DocumentUri("file://" + target),
+		Range: protocol.Range{},
+	}
+}
+
 // findVariableDefinition locates where a variable is defined
 // Returns location in yapi.config.yml or .env file
 func findVariableDefinition(varName string, project *config.ProjectConfigV1, projectRoot string) (*protocol.Location, error) {
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hexDumpWidth is the number of bytes rendered per hex dump line.
+const hexDumpWidth = 16
+
+// HexDump renders data in xxd style: an offset column, the bytes as hex pairs
+// grouped two at a time, and an ASCII gutter with non-printable bytes shown as '.'.
+// If limit > 0, only the first limit bytes are rendered and a trailing line
+// reports how many bytes were omitted.
+func HexDump(data []byte, limit int) string {
+	omitted := 0
+	if limit > 0 && len(data) > limit {
+		omitted = len(data) - limit
+		data = data[:limit]
+	}
+
+	var sb strings.Builder
+	for off := 0; off < len(data); off += hexDumpWidth {
+		line := data[off:min(off+hexDumpWidth, len(data))]
+
+		fmt.Fprintf(&sb, "%08x: ", off)
+		for i := 0; i < hexDumpWidth; i++ {
+			if i < len(line) {
+				fmt.Fprintf(&sb, "%02x", line[i])
+			} else {
+				sb.WriteString("  ")
+			}
+			if i%2 == 1 {
+				sb.WriteByte(' ')
+			}
+		}
+
+		sb.WriteByte(' ')
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7f {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "... %d more bytes\n", omitted)
+	}
+	return sb.String()
+}
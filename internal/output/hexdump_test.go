@@ -0,0 +1,43 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		limit    int
+		expected string
+	}{
+		{
+			name:     "empty",
+			data:     nil,
+			expected: "",
+		},
+		{
+			name: "full and partial lines match xxd",
+			data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDRabc"),
+			expected: "00000000: 8950 4e47 0d0a 1a0a 0000 000d 4948 4452  .PNG........IHDR\n" +
+				"00000010: 6162 63                                  abc\n",
+		},
+		{
+			name:  "limit truncates and reports omitted bytes",
+			data:  []byte(strings.Repeat("A", 40)),
+			limit: 16,
+			expected: "00000000: 4141 4141 4141 4141 4141 4141 4141 4141  AAAAAAAAAAAAAAAA\n" +
+				"... 24 more bytes\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HexDump(tt.data, tt.limit)
+			if got != tt.expected {
+				t.Errorf("HexDump() =\n%q\nwant\n%q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"yapi.run/cli/internal/constants"
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/httpcache"
+)
+
+// Cache statuses reported in Result.Cache
+const (
+	CacheMiss        = "miss"        // No usable entry; the response was fetched in full
+	CacheRevalidated = "revalidated" // Server answered 304 and the cached body was used
+	CacheModified    = "modified"    // Entry existed but the server sent a new representation
+)
+
+// CacheInfo describes how the response cache handled a request
+type CacheInfo struct {
+	Status string // miss, revalidated or modified
+	Stored bool   // True if the response was written to the cache
+}
+
+// responseCache tracks the cache state of a single request.
+type responseCache struct {
+	store *httpcache.Store
+	entry *httpcache.Entry
+	info  CacheInfo
+}
+
+// openCache loads the cache entry for req and adds conditional headers.
+// Returns nil if caching is disabled or does not apply to the request.
+func openCache(req *domain.Request, dir string) (*responseCache, error) {
+	if req.Metadata["cache"] != "true" || req.Metadata["transport"] != constants.TransportHTTP {
+		return nil, nil
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, nil
+	}
+
+	c := &responseCache{store: httpcache.NewStore(dir)}
+	entry, err := c.store.Load(req.Method, req.URL, req.Headers)
+	if err != nil {
+		return nil, err
+	}
+	c.entry = entry
+
+	if entry != nil {
+		// Explicit conditional headers in the config take precedence
+		for k, v := range entry.ConditionalHeaders() {
+			if _, ok := req.Headers[k]; !ok {
+				req.SetHeader(k, v)
+			}
+		}
+	}
+	return c, nil
+}
+
+// serveNotModified replaces the empty body of a 304 response with the cached body.
+func (c *responseCache) serveNotModified(resp *domain.Response) {
+	if c.entry == nil || resp.StatusCode != http.StatusNotModified {
+		return
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(c.entry.Body))
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]string)
+	}
+	if resp.Headers["Content-Type"] == "" {
+		resp.Headers["Content-Type"] = c.entry.Headers["Content-Type"]
+	}
+	c.info.Status = CacheRevalidated
+}
+
+// update stores a fresh response, or refreshes the entry after a 304.
+// body is the raw response body, nil if it was too large to buffer.
+func (c *responseCache) update(req *domain.Request, resp *domain.Response, body []byte) (*CacheInfo, error) {
+	if c.info.Status == CacheRevalidated {
+		// Headers on a 304 update the stored response (RFC 9111, section 4.3.4)
+		if c.entry.Headers == nil {
+			c.entry.Headers = make(map[string]string)
+		}
+		for k, v := range resp.Headers {
+			c.entry.Headers[k] = v
+		}
+		c.entry.StoredAt = time.Now()
+		if err := c.store.Save(c.entry); err != nil {
+			return nil, err
+		}
+		c.info.Stored = true
+		return &c.info, nil
+	}
+
+	c.info.Status = CacheMiss
+	if c.entry != nil {
+		c.info.Status = CacheModified
+	}
+
+	if resp.StatusCode != http.StatusOK || body == nil || !httpcache.Storable(req.Headers, resp.Headers) {
+		return &c.info, nil
+	}
+
+	entry := &httpcache.Entry{
+		Method:       req.Method,
+		URL:          req.URL,
+		ETag:         resp.Headers["Etag"],
+		LastModified: resp.Headers["Last-Modified"],
+		StatusCode:   resp.StatusCode,
+		Headers:      resp.Headers,
+		Body:         body,
+		StoredAt:     time.Now(),
+	}
+	if vary := httpcache.ParseVary(resp.Headers["Vary"]); len(vary) > 0 {
+		entry.Vary, entry.VaryValues = vary, httpcache.SelectVary(vary, req.Headers)
+	}
+	if err := c.store.Save(entry); err != nil {
+		return nil, err
+	}
+	c.info.Stored = true
+	return &c.info, nil
+}
+
+// cacheAssertionInput builds the JSON document that cache assertions run against.
+func cacheAssertionInput(result *Result) ([]byte, error) {
+	status := "bypass"
+	stored := false
+	if result.Cache != nil {
+		status = result.Cache.Status
+		stored = result.Cache.Stored
+	}
+
+	var age any
+	if n, err := strconv.Atoi(result.Headers["Age"]); err == nil {
+		age = n
+	}
+
+	return json.Marshal(map[string]any{
+		"status":        status,
+		"revalidated":   status == CacheRevalidated,
+		"stored":        stored,
+		"etag":          result.Headers["Etag"],
+		"last_modified": result.Headers["Last-Modified"],
+		"age":           age,
+		"cache_control": httpcache.ParseCacheControl(result.Headers["Cache-Control"]),
+	})
+}
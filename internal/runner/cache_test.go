@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/executor"
+)
+
+func TestRun_CacheRevalidation(t *testing.T) {
+	version := "v1"
+	var conditional []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		etag := `"` + version + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"` + version + `"}`))
+	}))
+	defer srv.Close()
+
+	opts := Options{CacheDir: t.TempDir()}
+	run := func() *Result {
+		t.Helper()
+		req := &domain.Request{
+			URL:      srv.URL + "/asset",
+			Method:   "GET",
+			Metadata: map[string]string{"transport": "http", "cache": "true"},
+		}
+		result, err := Run(context.Background(), executor.HTTPTransport(&http.Client{}), req, nil, opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result
+	}
+
+	first := run()
+	if first.StatusCode != 200 || first.Cache == nil || first.Cache.Status != CacheMiss || !first.Cache.Stored {
+		t.Fatalf("first run: status %d, cache %+v; want 200 miss stored", first.StatusCode, first.Cache)
+	}
+
+	second := run()
+	if second.StatusCode != http.StatusNotModified || second.Cache.Status != CacheRevalidated {
+		t.Fatalf("second run: status %d, cache %+v; want 304 revalidated", second.StatusCode, second.Cache)
+	}
+	if second.Body != `{"version":"v1"}` {
+		t.Errorf("second run body = %q, want cached body", second.Body)
+	}
+	if second.ContentType != "application/json" {
+		t.Errorf("second run content type = %q, want cached application/json", second.ContentType)
+	}
+
+	version = "v2"
+	third := run()
+	if third.StatusCode != 200 || third.Cache.Status != CacheModified || third.Body != `{"version":"v2"}` {
+		t.Errorf("third run: status %d, cache %+v, body %q; want 200 modified v2", third.StatusCode, third.Cache, third.Body)
+	}
+
+	want := []string{"", `"v1"`, `"v1"`}
+	if strings.Join(conditional, ",") != strings.Join(want, ",") {
+		t.Errorf("If-None-Match sent = %q, want %q", conditional, want)
+	}
+}
+
+func TestRun_CacheSkipsNoStore(t *testing.T) {
+	var conditional string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = r.Header.Get("If-None-Match")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	opts := Options{CacheDir: t.TempDir()}
+	for i := 0; i < 2; i++ {
+		req := &domain.Request{
+			URL:      srv.URL,
+			Method:   "GET",
+			Metadata: map[string]string{"transport": "http", "cache": "true"},
+		}
+		result, err := Run(context.Background(), executor.HTTPTransport(&http.Client{}), req, nil, opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Cache.Stored {
+			t.Errorf("run %d: no-store response was cached", i+1)
+		}
+	}
+	if conditional != "" {
+		t.Errorf("expected no conditional request for no-store response, got If-None-Match %q", conditional)
+	}
+}
+
+func TestRun_CacheKeepsUsersApart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		etag := `"` + user + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Vary", "Authorization")
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(user))
+	}))
+	defer srv.Close()
+
+	opts := Options{CacheDir: t.TempDir()}
+	run := func(path, user string) *Result {
+		t.Helper()
+		req := &domain.Request{
+			URL:      srv.URL + path,
+			Method:   "GET",
+			Headers:  map[string]string{"Authorization": "Bearer " + user},
+			Metadata: map[string]string{"transport": "http", "cache": "true"},
+		}
+		result, err := Run(context.Background(), executor.HTTPTransport(&http.Client{}), req, nil, opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result
+	}
+
+	// Private responses to authorized requests are never stored
+	if res := run("/private", "alice"); res.Cache.Stored {
+		t.Errorf("private response to an authorized request was cached")
+	}
+
+	// Public responses are stored per Vary: Authorization value
+	run("/public", "alice")
+	if res := run("/public", "bob"); res.Cache.Status != CacheMiss || res.Body != "bob" {
+		t.Errorf("bob: cache %+v, body %q; want a miss with his own body", res.Cache, res.Body)
+	}
+	if res := run("/public", "alice"); res.Cache.Status != CacheRevalidated || res.Body != "alice" {
+		t.Errorf("alice: cache %+v, body %q; want her cached body revalidated", res.Cache, res.Body)
+	}
+}
+
+func TestCheckExpectations_CacheAssertions(t *testing.T) {
+	result := &Result{
+		StatusCode: 304,
+		Headers: map[string]string{
+			"Etag":          `"abc"`,
+			"Cache-Control": "public, max-age=60, must-revalidate",
+			"Age":           "12",
+		},
+		Cache: &CacheInfo{Status: CacheRevalidated, Stored: true},
+	}
+
+	tests := []struct {
+		name      string
+		assertion string
+		wantPass  bool
+	}{
+		{"revalidated", ".revalidated == true", true},
+		{"status", `.status == "revalidated"`, true},
+		{"etag", `.etag == "\"abc\""`, true},
+		{"max-age", `.cache_control["max-age"] >= 60`, true},
+		{"must-revalidate", `.cache_control["must-revalidate"] == true`, true},
+		{"no-store absent", `.cache_control["no-store"] == null`, true},
+		{"age", ".age < 60", true},
+		{"wrong status", `.status == "miss"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect := config.Expectation{Assert: config.AssertionSet{Cache: []string{tt.assertion}}}
+			res := CheckExpectationsWithEnv(expect, result, nil)
+			if res.AllPassed() != tt.wantPass {
+				t.Errorf("assertion %q passed = %v, want %v (err: %v)", tt.assertion, res.AllPassed(), tt.wantPass, res.Error)
+			}
+		})
+	}
+}
+
+func TestCheckExpectations_CacheAssertionsWithoutCache(t *testing.T) {
+	result := &Result{StatusCode: 200, Headers: map[string]string{"Cache-Control": "no-cache"}}
+	expect := config.Expectation{Assert: config.AssertionSet{Cache: []string{`.status == "bypass"`, `.cache_control["no-cache"] == true`}}}
+	if res := CheckExpectationsWithEnv(expect, result, nil); !res.AllPassed() {
+		t.Errorf("expected cache assertions to pass without caching: %v", res.Error)
+	}
+}
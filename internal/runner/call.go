@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"yapi.run/cli/internal/config"
+)
+
+// runCall runs the yapi file named by a step's `call` as a nested chain, with the
+// step's `with` parameters as variables. A called request's response is the step's
+// result; a called chain's result is a JSON object of its chain_outputs, with the
+// status of its last step.
+func runCall(ctx context.Context, factory ExecutorFactory, i int, step config.ChainStep, chainCtx *ChainContext, opts Options) stepOutcome {
+	path := step.Call
+	if !filepath.IsAbs(path) && opts.ConfigPath != "" {
+		path = filepath.Join(filepath.Dir(opts.ConfigPath), path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' call: %w", step.Name, err)}
+	}
+
+	stack := opts.callStack
+	if stack == nil && opts.ConfigPath != "" {
+		if root, err := filepath.Abs(opts.ConfigPath); err == nil {
+			stack = []string{root}
+		}
+	}
+	if slices.Contains(stack, abs) {
+		return stepOutcome{err: fmt.Errorf("step '%s': call cycle: %s", step.Name, callCycle(stack, abs))}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' call: %w", step.Name, err)}
+	}
+	defaults, err := envDefaults(opts)
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' call: %w", step.Name, err)}
+	}
+	base, steps, err := config.LoadCallTarget(string(data), step.Name, defaults)
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' call %s: %w", step.Name, step.Call, err)}
+	}
+
+	params, err := chainCtx.resolveNamed(step.With, "with")
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' %w", step.Name, err)}
+	}
+
+	// Parameters are also visible to the called file's assertions as $NAME
+	callOpts := opts
+	callOpts.ConfigPath = path
+	callOpts.callStack = append(slices.Clone(stack), abs)
+	callOpts.EnvOverrides = make(map[string]string, len(opts.EnvOverrides)+len(params))
+	for k, v := range opts.EnvOverrides {
+		callOpts.EnvOverrides[k] = v
+	}
+	for k, v := range params {
+		callOpts.EnvOverrides[k] = valueString(v)
+	}
+
+	fmt.Fprintf(os.Stderr, "Running step %d: %s (call %s)...\n", i+1, step.Name, step.Call)
+	called, err := runChain(ctx, factory, base, steps, callOpts, params)
+	if err != nil {
+		return stepOutcome{called: called, err: fmt.Errorf("step '%s' call %s: %w", step.Name, step.Call, err)}
+	}
+
+	var outcome stepOutcome
+	if len(base.Chain) == 0 {
+		// A single request ran as a one-step chain under this step's name
+		outcome = stepOutcome{result: called.Results[0], expect: called.ExpectationResults[0]}
+	} else {
+		result, err := outputsResult(called)
+		if err != nil {
+			return stepOutcome{called: called, err: fmt.Errorf("step '%s': %w", step.Name, err)}
+		}
+		outcome = stepOutcome{result: result, called: called}
+	}
+
+	if hasExpectations(step.Expect) {
+		expect, err := expandExpectStatus(chainCtx, step.Expect)
+		if err != nil {
+			outcome.err = fmt.Errorf("step '%s' expect: %w", step.Name, err)
+			return outcome
+		}
+		outcome.expect = CheckExpectationsWithEnv(expect, outcome.result, opts.EnvOverrides)
+		if outcome.expect.Error != nil {
+			outcome.err = fmt.Errorf("step '%s' assertion failed: %w", step.Name, outcome.expect.Error)
+		}
+	}
+	return outcome
+}
+
+// envDefaults returns the defaults of the project environment being run, which
+// called files merge like the file that calls them. It is nil outside a project.
+func envDefaults(opts Options) (*config.ConfigV1, error) {
+	if opts.ProjectRoot == "" || opts.ProjectEnv == "" {
+		return nil, nil
+	}
+	project, err := config.LoadProject(opts.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+	env, err := project.GetEnvironment(opts.ProjectEnv)
+	if err != nil {
+		return nil, err
+	}
+	return &env.ConfigV1, nil
+}
+
+// outputsResult presents a called chain as a single response: its chain_outputs as
+// a JSON body, the status and headers of its last step, and its total duration.
+func outputsResult(called *ChainResult) (*Result, error) {
+	outputs := called.Outputs
+	if outputs == nil {
+		outputs = map[string]any{}
+	}
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chain_outputs: %w", err)
+	}
+	body := string(data)
+
+	result := &Result{
+		Body:        body,
+		ContentType: "application/json",
+		BodyLines:   strings.Count(body, "\n") + 1,
+		BodyChars:   len(body),
+		BodyBytes:   len(body),
+	}
+	var total time.Duration
+	for _, res := range called.Results {
+		total += res.Duration
+	}
+	result.Duration = total
+	if n := len(called.Results); n > 0 {
+		result.StatusCode = called.Results[n-1].StatusCode
+		result.Headers = called.Results[n-1].Headers
+	}
+	return result, nil
+}
+
+// hasExpectations reports whether an expect block checks anything.
+func hasExpectations(expect config.Expectation) bool {
+	return expect.Status != nil || len(expect.Assert.Body) > 0 || len(expect.Assert.Headers) > 0 || len(expect.Assert.Cache) > 0 || len(expect.Assert.TLS) > 0
+}
+
+// callCycle describes the chain of calls that leads back to path.
+func callCycle(stack []string, path string) string {
+	start := slices.Index(stack, path)
+	names := make([]string, 0, len(stack)-start+1)
+	for _, p := range stack[start:] {
+		names = append(names, filepath.Base(p))
+	}
+	return strings.Join(append(names, filepath.Base(path)), " -> ")
+}
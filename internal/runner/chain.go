@@ -0,0 +1,379 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/vars"
+)
+
+// stepOutcome is the result of running one chain step.
+type stepOutcome struct {
+	result     *Result
+	expect     *ExpectationResult
+	iterations []*stepOutcome // Per-item outcomes of a foreach step
+	called     *ChainResult   // Steps of the chain a call step ran
+	skipped    bool
+	reason     string // Why the step was skipped
+	err        error
+	tolerated  bool // err does not fail the chain, as the step has continue_on_error
+	cancelled  bool // The run was cancelled or timed out before or while the step ran
+}
+
+// runChainStep runs chain step i, unless its condition skips it, and records its
+// result and captures for later steps.
+func runChainStep(ctx context.Context, factory ExecutorFactory, base *config.ConfigV1, i int, step config.ChainStep, chainCtx *ChainContext, opts Options) stepOutcome {
+	if ctx.Err() != nil {
+		return stepOutcome{cancelled: true, err: fmt.Errorf("step '%s' cancelled: %w", step.Name, context.Cause(ctx))}
+	}
+
+	var outcome stepOutcome
+	if run, reason, err := stepCondition(chainCtx, step); err != nil {
+		outcome.err = err
+	} else if !run {
+		fmt.Fprintf(os.Stderr, "Skipping step %d: %s (%s)\n", i+1, step.Name, reason)
+		chainCtx.AddSkipped(step.Name)
+		return stepOutcome{skipped: true, reason: reason}
+	} else {
+		switch {
+		case step.Call != "":
+			outcome = runCall(ctx, factory, i, step, chainCtx, opts)
+			if outcome.result != nil {
+				chainCtx.AddResult(step.Name, outcome.result)
+			}
+		case step.Foreach != nil:
+			outcome = runForeach(ctx, factory, base, i, step, chainCtx, opts)
+		default:
+			fmt.Fprintf(os.Stderr, "Running step %d: %s...\n", i+1, step.Name)
+			result, expectRes, err := runStep(ctx, factory, base, step, chainCtx, opts)
+			if result != nil {
+				chainCtx.AddResult(step.Name, result)
+			}
+			outcome = stepOutcome{result: result, expect: expectRes, err: err}
+		}
+		if outcome.err == nil {
+			outcome.err = captureValues(chainCtx, step)
+		}
+	}
+
+	// Whatever error a cancelled request ended with, cancellation is the cause
+	if outcome.err != nil && ctx.Err() != nil {
+		outcome.cancelled = true
+		outcome.err = fmt.Errorf("step '%s' cancelled: %w", step.Name, context.Cause(ctx))
+		return outcome
+	}
+	if outcome.err != nil && step.ContinueOnError {
+		fmt.Fprintf(os.Stderr, "[WARN] %v (continuing: continue_on_error)\n", outcome.err)
+		outcome.tolerated = true
+	}
+	return outcome
+}
+
+// addStep adds a step's results and its report to the chain result. It returns the
+// step's error, unless the step may fail without failing the chain.
+func (r *ChainResult) addStep(step config.ChainStep, outcome *stepOutcome, finally bool) error {
+	report := StepReport{Name: step.Name, Finally: finally}
+	switch {
+	case outcome == nil:
+		report.Status = StepNotRun
+		r.Steps = append(r.Steps, report)
+		return nil
+	case outcome.skipped:
+		report.Status, report.Reason = StepSkipped, outcome.reason
+		r.Skipped = append(r.Skipped, step.Name)
+		r.Steps = append(r.Steps, report)
+		return nil
+	}
+
+	if called := outcome.called; called != nil {
+		for j, res := range called.Results {
+			r.Results = append(r.Results, res)
+			r.StepNames = append(r.StepNames, step.Name+"/"+called.StepNames[j])
+			r.ExpectationResults = append(r.ExpectationResults, called.ExpectationResults[j])
+		}
+		for _, name := range called.Skipped {
+			r.Skipped = append(r.Skipped, step.Name+"/"+name)
+		}
+	}
+	for j, it := range outcome.iterations {
+		if it != nil && it.result != nil {
+			r.Results = append(r.Results, it.result)
+			r.StepNames = append(r.StepNames, fmt.Sprintf("%s[%d]", step.Name, j))
+			r.ExpectationResults = append(r.ExpectationResults, it.expect)
+		}
+	}
+	if outcome.result != nil {
+		r.Results = append(r.Results, outcome.result)
+		r.StepNames = append(r.StepNames, step.Name)
+		r.ExpectationResults = append(r.ExpectationResults, outcome.expect)
+	}
+
+	switch {
+	case outcome.cancelled:
+		report.Status = StepCancelled
+		if outcome.err != nil {
+			report.Reason = outcome.err.Error()
+		}
+	case outcome.err != nil:
+		report.Status, report.Reason = StepFailed, outcome.err.Error()
+	default:
+		report.Status = StepPassed
+	}
+	r.Steps = append(r.Steps, report)
+	if outcome.tolerated {
+		return nil
+	}
+	return outcome.err
+}
+
+// stepCondition evaluates a step's `when` and `skip_if` conditions and reports
+// whether it should run, or else why it is skipped.
+func stepCondition(chainCtx *ChainContext, step config.ChainStep) (bool, string, error) {
+	if step.When != "" {
+		ok, err := chainCtx.EvalCondition(step.When)
+		if err != nil {
+			return false, "", fmt.Errorf("step '%s' when: %w", step.Name, err)
+		}
+		if !ok {
+			return false, "when: " + step.When, nil
+		}
+	}
+	if step.SkipIf != "" {
+		skip, err := chainCtx.EvalCondition(step.SkipIf)
+		if err != nil {
+			return false, "", fmt.Errorf("step '%s' skip_if: %w", step.Name, err)
+		}
+		if skip {
+			return false, "skip_if: " + step.SkipIf, nil
+		}
+	}
+	return true, "", nil
+}
+
+// captureValues evaluates a step's capture expressions, like jq references to the
+// step, and stores the values under the step's name.
+func captureValues(chainCtx *ChainContext, step config.ChainStep) error {
+	if len(step.Capture) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(step.Capture))
+	for name := range step.Capture {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]any, len(names))
+	for _, name := range names {
+		val, err := chainCtx.evalJQRef(step.Name, step.Capture[name])
+		if err != nil {
+			return fmt.Errorf("step '%s' capture '%s': %w", step.Name, name, err)
+		}
+		values[name] = val
+	}
+	chainCtx.AddCaptures(step.Name, values)
+	return nil
+}
+
+// runForeach runs a foreach step once per item, up to the step's `parallel`
+// iterations at a time, and stores the results as a list under the step name.
+func runForeach(ctx context.Context, factory ExecutorFactory, base *config.ConfigV1, i int, step config.ChainStep, chainCtx *ChainContext, opts Options) stepOutcome {
+	items, err := foreachItems(chainCtx, step.Foreach)
+	if err != nil {
+		return stepOutcome{err: fmt.Errorf("step '%s' foreach: %w", step.Name, err)}
+	}
+	fmt.Fprintf(os.Stderr, "Running step %d: %s (%d items)...\n", i+1, step.Name, len(items))
+
+	iterations := scheduleSteps(len(items), nil, step.Parallel, func(j int) stepOutcome {
+		iter := step
+		iter.Name = fmt.Sprintf("%s[%d]", step.Name, j)
+		result, expectRes, err := runStep(ctx, factory, base, iter, chainCtx.forItem(items[j], j), opts)
+		return stepOutcome{result: result, expect: expectRes, err: err}
+	})
+
+	outcome := stepOutcome{iterations: iterations}
+	results := make([]*Result, 0, len(iterations))
+	for _, it := range iterations {
+		if it == nil {
+			continue
+		}
+		if it.err != nil && outcome.err == nil {
+			outcome.err = it.err
+		}
+		results = append(results, it.result)
+	}
+	if outcome.err == nil {
+		chainCtx.AddIterations(step.Name, results)
+	}
+	return outcome
+}
+
+// foreachItems returns the items a foreach step iterates over: the array a jq
+// expression evaluates to, or a literal list whose strings may reference earlier steps.
+func foreachItems(chainCtx *ChainContext, foreach any) ([]any, error) {
+	switch v := foreach.(type) {
+	case string:
+		return chainCtx.EvalItems(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			if s, ok := item.(string); ok {
+				expanded, err := chainCtx.ExpandVariables(s)
+				if err != nil {
+					return nil, fmt.Errorf("item %d: %w", i, err)
+				}
+				item = expanded
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("expected a jq expression or a list, got %T", foreach)
+	}
+}
+
+// chainDependencies returns, for each step, the indexes of the earlier steps it must
+// wait for: those named in `depends_on`, those its fields and `with` parameters
+// reference as ${step.field}, and those its when/skip_if conditions and foreach
+// expression read as .step.
+func chainDependencies(base *config.ConfigV1, steps []config.ChainStep) ([][]int, error) {
+	index := make(map[string]int, len(steps))
+	refs := make(map[string]*regexp.Regexp, len(steps))
+	deps := make([][]int, len(steps))
+
+	for i, step := range steps {
+		seen := make(map[int]bool)
+		add := func(name string) {
+			if j, ok := index[name]; ok && !seen[j] {
+				seen[j] = true
+				deps[i] = append(deps[i], j)
+			}
+		}
+
+		for _, name := range step.DependsOn {
+			if _, ok := index[name]; !ok {
+				return nil, fmt.Errorf("step '%s' depends on '%s', which is not an earlier step", step.Name, name)
+			}
+			add(name)
+		}
+
+		// Scan every field of the merged config, a literal foreach list and call
+		// parameters for chain references
+		merged := base.Merge(step)
+		merged.Chain = nil
+		data, err := yaml.Marshal(&merged)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+		}
+		expr, _ := step.Foreach.(string)
+		if list, ok := step.Foreach.([]any); ok {
+			items, err := yaml.Marshal(list)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+			}
+			data = append(data, items...)
+		}
+		if len(step.With) > 0 {
+			with, err := yaml.Marshal(step.With)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+			}
+			data = append(data, with...)
+		}
+		for _, match := range vars.Expansion.FindAllStringSubmatch(string(data), -1) {
+			key := match[1]
+			if key == "" {
+				key = match[2]
+			}
+			if name, _, _, ok := vars.ChainRef(key); ok {
+				add(name)
+			}
+		}
+
+		// Conditions and foreach expressions address earlier steps as .name
+		for name, ref := range refs {
+			if ref.MatchString(step.When) || ref.MatchString(step.SkipIf) || ref.MatchString(expr) {
+				add(name)
+			}
+		}
+
+		index[step.Name] = i
+		refs[step.Name] = stepRefPattern(step.Name)
+	}
+	for i := range deps {
+		sort.Ints(deps[i])
+	}
+	return deps, nil
+}
+
+// stepRefPattern matches a jq path that starts at the root with step name, as .name or
+// .["name"], but not a deeper path such as .login.name.
+func stepRefPattern(name string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`(?:^|[^\w\]).")?$])\.(?:` + quoted + `\b|\["` + quoted + `"\])`)
+}
+
+// scheduleSteps runs n steps with run, at most parallel at a time (at least one),
+// starting each once the steps it depends on have succeeded or failed with
+// continue_on_error. Once another step fails no further steps are started; steps
+// already running finish. The outcome of steps that never started is nil.
+func scheduleSteps(n int, deps [][]int, parallel int, run func(i int) stepOutcome) []*stepOutcome {
+	if parallel < 1 {
+		parallel = 1
+	}
+	// Without parallelism every step waits for the previous one, as written
+	if parallel == 1 {
+		deps = make([][]int, n)
+		for i := 1; i < n; i++ {
+			deps[i] = []int{i - 1}
+		}
+	}
+
+	pending := make([]int, n)
+	dependents := make([][]int, n)
+	for i, ds := range deps {
+		pending[i] = len(ds)
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+
+	type finished struct {
+		i       int
+		outcome stepOutcome
+	}
+	outcomes := make([]*stepOutcome, n)
+	started := make([]bool, n)
+	done := make(chan finished)
+	running, failed := 0, false
+
+	for {
+		// Start ready steps in chain order
+		for i := 0; i < n && running < parallel && !failed; i++ {
+			if started[i] || pending[i] > 0 {
+				continue
+			}
+			started[i] = true
+			running++
+			go func(i int) { done <- finished{i, run(i)} }(i)
+		}
+		if running == 0 {
+			return outcomes
+		}
+
+		f := <-done
+		running--
+		outcomes[f.i] = &f.outcome
+		if f.outcome.err != nil && !f.outcome.tolerated {
+			failed = true
+			continue
+		}
+		for _, d := range dependents[f.i] {
+			pending[d]--
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/domain"
+)
+
+func TestChainDependencies(t *testing.T) {
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "login"},
+		{Name: "users", ConfigV1: config.ConfigV1{Headers: map[string]string{"Authorization": "Bearer ${login.token}"}}},
+		{Name: "orders", ConfigV1: config.ConfigV1{Path: "/orders/$login.id", Body: map[string]any{"user": "${users.items}"}}},
+		{Name: "audit", DependsOn: []string{"users"}},
+		{Name: "health"},
+	}
+
+	deps, err := chainDependencies(base, steps)
+	if err != nil {
+		t.Fatalf("chainDependencies() error: %v", err)
+	}
+	want := [][]int{nil, {0}, {0, 1}, {1}, nil}
+	if fmt.Sprint(deps) != fmt.Sprint(want) {
+		t.Errorf("chainDependencies() = %v, want %v", deps, want)
+	}
+
+	steps[0].DependsOn = []string{"health"}
+	if _, err := chainDependencies(base, steps); err == nil || !strings.Contains(err.Error(), "not an earlier step") {
+		t.Errorf("expected error for a dependency on a later step, got %v", err)
+	}
+}
+
+func TestChainDependencies_Conditions(t *testing.T) {
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "status"},
+		{Name: "login"},
+		{Name: "body"},
+		{Name: "check", When: ".login.body.status == 200"},
+		{Name: "poll", SkipIf: `(.status | length) > 0 or .["body"] == null`},
+	}
+
+	deps, err := chainDependencies(base, steps)
+	if err != nil {
+		t.Fatalf("chainDependencies() error: %v", err)
+	}
+	// check reads login's body, not the steps named body or status
+	want := [][]int{nil, nil, nil, {1}, {0, 2}}
+	if fmt.Sprint(deps) != fmt.Sprint(want) {
+		t.Errorf("chainDependencies() = %v, want %v", deps, want)
+	}
+}
+
+func TestScheduleSteps(t *testing.T) {
+	// 0 and 1 are independent; 2 needs both; 3 needs 0
+	deps := [][]int{nil, nil, {0, 1}, {0}}
+
+	var mu sync.Mutex
+	var order []int
+	var running, peak int32
+	run := func(i int) stepOutcome {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		mu.Lock()
+		order = append(order, i)
+		mu.Unlock()
+		return stepOutcome{}
+	}
+
+	outcomes := scheduleSteps(4, deps, 4, run)
+	for i, o := range outcomes {
+		if o == nil {
+			t.Errorf("step %d did not run", i)
+		}
+	}
+	if peak < 2 {
+		t.Errorf("independent steps did not run concurrently (peak %d)", peak)
+	}
+	pos := make(map[int]int)
+	for p, i := range order {
+		pos[i] = p
+	}
+	if pos[2] < pos[0] || pos[2] < pos[1] || pos[3] < pos[0] {
+		t.Errorf("steps ran before their dependencies: %v", order)
+	}
+
+	peak, order = 0, nil
+	scheduleSteps(4, deps, 1, run)
+	if peak != 1 || fmt.Sprint(order) != "[0 1 2 3]" {
+		t.Errorf("parallel 1 should run in chain order, got %v (peak %d)", order, peak)
+	}
+}
+
+func TestScheduleSteps_StopsAfterFailure(t *testing.T) {
+	deps := [][]int{nil, {0}, nil}
+	outcomes := scheduleSteps(3, deps, 1, func(i int) stepOutcome {
+		if i == 0 {
+			return stepOutcome{err: fmt.Errorf("boom")}
+		}
+		return stepOutcome{}
+	})
+	if outcomes[0] == nil || outcomes[1] != nil || outcomes[2] != nil {
+		t.Errorf("no step should start after a failure: %v", outcomes)
+	}
+}
+
+func TestRunChain_Parallel(t *testing.T) {
+	var calls int32
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		body := `{"token":"abc"}`
+		if strings.Contains(req.URL, "/svc") {
+			body = fmt.Sprintf(`{"auth":%q}`, req.Headers["Authorization"])
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com", Parallel: 4}
+	steps := []config.ChainStep{{Name: "login", ConfigV1: config.ConfigV1{Path: "/login"}}}
+	for i := 1; i <= 4; i++ {
+		steps = append(steps, config.ChainStep{
+			Name: fmt.Sprintf("svc%d", i),
+			ConfigV1: config.ConfigV1{
+				Path:    fmt.Sprintf("/svc%d", i),
+				Headers: map[string]string{"Authorization": "Bearer ${login.token}"},
+				Expect:  config.Expectation{Assert: config.AssertionSet{Body: []string{`.auth == "Bearer abc"`}}},
+			},
+		})
+	}
+
+	start := time.Now()
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("got %d requests, want 5", calls)
+	}
+	// login, then the four services together: about 2 round trips instead of 5
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("services did not run in parallel (%v)", elapsed)
+	}
+	if strings.Join(result.StepNames, ",") != "login,svc1,svc2,svc3,svc4" {
+		t.Errorf("results not in chain order: %v", result.StepNames)
+	}
+}
@@ -2,8 +2,16 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -487,6 +495,79 @@ func TestRunChain_Delay(t *testing.T) {
 	}
 }
 
+func TestRunChain_GraphQLBatchVariables(t *testing.T) {
+	var batch string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		body := `{"token":"t1","id":7}`
+		if req.Metadata["graphql_batch"] != "" {
+			batch = req.Metadata["graphql_batch"]
+			body = `{"GetUser":{"data":{}}}`
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "login", ConfigV1: config.ConfigV1{Method: "POST", Path: "/login"}},
+		{Name: "users", ConfigV1: config.ConfigV1{
+			Path:    "/graphql",
+			Graphql: "query GetUser($id: Int, $token: String) { user(id: $id) { name } }",
+			Batch: []config.GraphQLOperation{
+				{OperationName: "GetUser", Variables: map[string]any{"id": "${login.id}", "token": "Bearer ${login.token}"}},
+			},
+		}},
+	}
+
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err != nil {
+		t.Fatalf("RunChain() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(batch, `"id":7`) || !strings.Contains(batch, `"token":"Bearer t1"`) {
+		t.Errorf("batch variables not interpolated from the chain: %s", batch)
+	}
+	if steps[1].Batch[0].Variables["token"] != "Bearer ${login.token}" {
+		t.Errorf("step config was modified: %v", steps[1].Batch[0].Variables)
+	}
+}
+
+func TestRunChain_ConversationExpectRegex(t *testing.T) {
+	var conversation string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		body := `{"token":"a.b+c"}`
+		if req.Metadata["conversation"] != "" {
+			conversation = req.Metadata["conversation"]
+			body = "OK a.b+c"
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "login", ConfigV1: config.ConfigV1{Method: "POST", Path: "/login"}},
+		{Name: "session", ConfigV1: config.ConfigV1{
+			URL: "tcp://example.com:6379",
+			Conversation: []config.ConversationStep{
+				{Send: "AUTH ${login.token}\r\n", ExpectRegex: `^OK ${login.token}\s*$`},
+			},
+		}},
+	}
+
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err != nil {
+		t.Fatalf("RunChain() returned unexpected error: %v", err)
+	}
+	// The token is matched literally: its regex metacharacters are escaped
+	if !strings.Contains(conversation, `"expect_regex":"^OK a\\.b\\+c\\s*$"`) {
+		t.Errorf("expect_regex not expanded and quoted: %s", conversation)
+	}
+}
+
 func TestRunChain_DelayInvalidDuration(t *testing.T) {
 	// Test error handling for invalid delay duration format
 	base := &config.ConfigV1{URL: "http://example.com"}
@@ -601,6 +682,606 @@ func TestRunChain_NegativeDelay(t *testing.T) {
 	}
 }
 
+func TestRunChain_Conditions(t *testing.T) {
+	t.Setenv("YAPI_TEST_MODE", "seed")
+	var paths []string
+	var mu sync.Mutex
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		mu.Lock()
+		paths = append(paths, req.URL)
+		mu.Unlock()
+		status, body := 200, `{"id":1}`
+		if strings.HasSuffix(req.URL, "/lookup") {
+			status, body = 404, `{"error":"not found"}`
+		}
+		return &domain.Response{
+			StatusCode: status,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "lookup", ConfigV1: config.ConfigV1{Path: "/lookup"}},
+		{Name: "create", When: ".lookup.status == 404", ConfigV1: config.ConfigV1{Path: "/create"}},
+		{Name: "update", When: ".lookup.status == 200", ConfigV1: config.ConfigV1{Path: "/update"}},
+		{Name: "seed", SkipIf: `env.YAPI_TEST_MODE != "seed"`, ConfigV1: config.ConfigV1{Path: "/seed"}},
+		{Name: "cleanup", SkipIf: `.lookup.body.error == "not found"`, ConfigV1: config.ConfigV1{Path: "/cleanup"}},
+	}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	if strings.Join(result.StepNames, ",") != "lookup,create,seed" {
+		t.Errorf("ran steps %v, want lookup,create,seed", result.StepNames)
+	}
+	if strings.Join(result.Skipped, ",") != "update,cleanup" {
+		t.Errorf("skipped %v, want update,cleanup", result.Skipped)
+	}
+
+	// A reference to a skipped step explains why it has no result
+	steps = append(steps, config.ChainStep{Name: "after", ConfigV1: config.ConfigV1{Path: "/after/${update.id}"}})
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err == nil || !strings.Contains(err.Error(), "step 'update' was skipped") {
+		t.Errorf("expected skipped step error, got %v", err)
+	}
+
+	steps = []config.ChainStep{{Name: "bad", When: ".x | length"}}
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err == nil || !strings.Contains(err.Error(), "step 'bad' when") {
+		t.Errorf("expected error for a non-boolean condition, got %v", err)
+	}
+}
+
+func TestRunChain_Foreach(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var bodies []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		mu.Lock()
+		paths = append(paths, req.URL)
+		if req.Body != nil {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+		}
+		mu.Unlock()
+		body := `{"ok":true}`
+		if strings.HasSuffix(req.URL, "/users") {
+			body = `{"items":[{"id":7},{"id":8},{"id":9}]}`
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"url":%q,"data":%s}`, req.URL, body))),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "list", ConfigV1: config.ConfigV1{Path: "/users"}},
+		{
+			Name:     "cleanup",
+			Foreach:  ".list.body.data.items",
+			ConfigV1: config.ConfigV1{Path: "/users/${item.id}", Method: "DELETE", Parallel: 3},
+		},
+		{
+			Name:     "tag",
+			Foreach:  []any{"red", "${cleanup.2.url}"},
+			ConfigV1: config.ConfigV1{Path: "/tags", Method: "POST", Body: map[string]any{"name": "${item}", "position": "${index}"}},
+		},
+		{Name: "after", When: "(.cleanup | length) == 3", ConfigV1: config.ConfigV1{Path: "/after/${cleanup.0.data.ok}"}},
+	}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+
+	want := "list,cleanup[0],cleanup[1],cleanup[2],tag[0],tag[1],after"
+	if got := strings.Join(result.StepNames, ","); got != want {
+		t.Errorf("step names = %s, want %s", got, want)
+	}
+	for _, p := range []string{"/users/7", "/users/8", "/users/9", "/after/true"} {
+		found := false
+		for _, got := range paths {
+			found = found || strings.HasSuffix(got, p)
+		}
+		if !found {
+			t.Errorf("no request to %s in %v", p, paths)
+		}
+	}
+	wantBodies := []string{`{"name":"red","position":0}`, `{"name":"http://example.com/users/9","position":1}`}
+	if strings.Join(bodies, "\n") != strings.Join(wantBodies, "\n") {
+		t.Errorf("tag bodies = %v, want %v", bodies, wantBodies)
+	}
+}
+
+func TestRunChain_ForeachNotArray(t *testing.T) {
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		return &domain.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"count":3}`))}, nil
+	}
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "list"},
+		{Name: "each", Foreach: ".list.body.count"},
+	}
+	_, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "step 'each' foreach: expected an array, got 3") {
+		t.Errorf("expected a foreach error, got %v", err)
+	}
+}
+
+func TestRunChain_WaitUntil(t *testing.T) {
+	var polls atomic.Int32
+	var bodies []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		body := `{"id":"job-1"}`
+		if strings.HasSuffix(req.URL, "/poll") {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+			body = `{"status":"pending"}`
+			if polls.Add(1) >= 3 {
+				body = `{"status":"done"}`
+			}
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{
+		{Name: "submit", ConfigV1: config.ConfigV1{Path: "/submit"}},
+		{
+			Name:      "wait",
+			WaitUntil: &config.WaitUntil{Condition: `.status == "done" and $status == 200`, Interval: "1ms", Backoff: 2},
+			ConfigV1:  config.ConfigV1{Path: "/poll", Method: "POST", Body: map[string]any{"id": "${submit.id}"}},
+		},
+	}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	attempts := result.Results[1].Attempts
+	if len(attempts) != 3 || attempts[0].Met || !attempts[2].Met {
+		t.Errorf("unexpected attempts: %+v", attempts)
+	}
+	for _, b := range bodies {
+		if b != `{"id":"job-1"}` {
+			t.Errorf("each attempt should resend the body, got %q", b)
+		}
+	}
+
+	// Never done: the step fails once the timeout is reached
+	polls.Store(-100)
+	steps[1].WaitUntil = &config.WaitUntil{Condition: `.status == "done"`, Interval: "5ms", Timeout: "30ms"}
+	result, err = RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "step 'wait' wait_until: condition not met after") {
+		t.Fatalf("expected a wait_until timeout, got %v", err)
+	}
+	if result == nil || len(result.Results) != 2 || len(result.Results[1].Attempts) < 2 {
+		t.Errorf("expected the last attempt to be reported, got %+v", result)
+	}
+}
+
+func TestRunChain_WaitUntilRetriesTransportErrors(t *testing.T) {
+	var calls atomic.Int32
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(`{"ready":true}`)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{{
+		Name:      "health",
+		WaitUntil: &config.WaitUntil{Condition: `.ready`, Interval: "1ms", Timeout: "1s"},
+		ConfigV1:  config.ConfigV1{Path: "/health"},
+	}}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	attempts := result.Results[0].Attempts
+	if len(attempts) != 2 || attempts[0].Error == nil || !strings.Contains(attempts[0].Error.Error(), "connection refused") || !attempts[1].Met {
+		t.Errorf("expected a failed attempt followed by a met one, got %+v", attempts)
+	}
+}
+
+func TestRunChain_CaptureAndOutputs(t *testing.T) {
+	var urls []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		urls = append(urls, req.URL)
+		body := `{"ok":true}`
+		headers := map[string]string{"Content-Type": "application/json"}
+		if strings.HasSuffix(req.URL, "/users") {
+			body = `{"data":{"user":{"id":42,"roles":["admin","dev"]}}}`
+			headers["Location"] = "/users/42"
+		}
+		return &domain.Response{
+			StatusCode: 201,
+			Headers:    headers,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{
+		URL: "http://example.com",
+		ChainOutputs: map[string]string{
+			"user_id":  "${create.user_id}",
+			"location": "${create.location}",
+			"summary":  "user ${create.user_id} has ${create | .data.user.roles | length} roles",
+		},
+	}
+	steps := []config.ChainStep{
+		{
+			Name: "create",
+			Capture: map[string]string{
+				"user_id":  ".data.user.id",
+				"location": `$headers["Location"]`,
+				"created":  "$status == 201",
+			},
+			ConfigV1: config.ConfigV1{Path: "/users"},
+		},
+		{Name: "fetch", When: ".create.capture.created", ConfigV1: config.ConfigV1{Path: "/users/${create.user_id}"}},
+	}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	if len(urls) != 2 || urls[1] != "http://example.com/users/42" {
+		t.Errorf("captured value was not used: %v", urls)
+	}
+	want := map[string]any{"user_id": 42, "location": "/users/42", "summary": "user 42 has 2 roles"}
+	for k, v := range want {
+		if result.Outputs[k] != v {
+			t.Errorf("output %s = %v (%T), want %v (%T)", k, result.Outputs[k], result.Outputs[k], v, v)
+		}
+	}
+
+	// A capture that fails fails its step
+	steps[0].Capture = map[string]string{"user_id": ".data.user.id | error"}
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err == nil || !strings.Contains(err.Error(), "step 'create' capture 'user_id'") {
+		t.Errorf("expected a capture error, got %v", err)
+	}
+}
+
+func TestRunChain_Call(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("login.yapi.yml", `yapi: v1
+url: http://auth.example.com
+chain_outputs:
+  token: ${token.access_token}
+chain:
+  - name: token
+    path: /token/${username}
+    expect:
+      status: 200
+`)
+	write("me.yapi.yml", `yapi: v1
+url: http://example.com/me
+headers:
+  Authorization: Bearer ${token}
+`)
+
+	var mu sync.Mutex
+	var requests []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		mu.Lock()
+		requests = append(requests, req.URL+" "+req.Headers["Authorization"])
+		mu.Unlock()
+		body := `{"name":"ada"}`
+		if strings.Contains(req.URL, "/token/") {
+			body = `{"access_token":"tok-` + strings.TrimPrefix(req.URL, "http://auth.example.com/token/") + `"}`
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{}
+	steps := []config.ChainStep{
+		{Name: "login", Call: "login.yapi.yml", With: map[string]string{"username": "ada"}},
+		{
+			Name:     "me",
+			Call:     "me.yapi.yml",
+			With:     map[string]string{"token": "${login.token}"},
+			ConfigV1: config.ConfigV1{Expect: config.Expectation{Assert: config.AssertionSet{Body: []string{`.name == "ada"`}}}},
+		},
+	}
+	opts := Options{ConfigPath: filepath.Join(dir, "main.yapi.yml")}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, opts)
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	wantRequests := []string{"http://auth.example.com/token/ada ", "http://example.com/me Bearer tok-ada"}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+	wantNames := []string{"login/token", "login", "me"}
+	if !reflect.DeepEqual(result.StepNames, wantNames) {
+		t.Errorf("step names = %v, want %v", result.StepNames, wantNames)
+	}
+	if result.Results[1].Body != `{"token":"tok-ada"}` || result.Results[1].StatusCode != 200 {
+		t.Errorf("called chain result = %d %s", result.Results[1].StatusCode, result.Results[1].Body)
+	}
+
+	// A file that calls itself, directly or not, is a cycle
+	write("a.yapi.yml", "yapi: v1\nchain:\n  - name: b\n    call: b.yapi.yml\n")
+	write("b.yapi.yml", "yapi: v1\nchain:\n  - name: a\n    call: a.yapi.yml\n")
+	steps = []config.ChainStep{{Name: "a", Call: "a.yapi.yml"}}
+	_, err = RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, opts)
+	if err == nil || !strings.Contains(err.Error(), "call cycle: a.yapi.yml -> b.yapi.yml -> a.yapi.yml") {
+		t.Errorf("expected a call cycle error, got %v", err)
+	}
+}
+
+func TestRunChain_CallUsesEnvironmentDefaults(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("yapi.config.yml", `yapi: v1
+kind: project
+environments:
+  staging:
+    url: http://staging.example.com
+    headers:
+      X-Env: staging
+`)
+	write("health.yapi.yml", `yapi: v1
+path: /health
+`)
+
+	var got string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		got = req.URL + " " + req.Headers["X-Env"]
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+		}, nil
+	}
+
+	steps := []config.ChainStep{{Name: "health", Call: "health.yapi.yml"}}
+	opts := Options{ConfigPath: filepath.Join(dir, "main.yapi.yml"), ProjectRoot: dir, ProjectEnv: "staging"}
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, &config.ConfigV1{}, steps, opts); err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	if got != "http://staging.example.com/health staging" {
+		t.Errorf("called file did not get the environment defaults: %q", got)
+	}
+}
+
+func TestRunChain_ContinueOnErrorAndFinally(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		mu.Lock()
+		requests = append(requests, req.Method+" "+req.URL)
+		mu.Unlock()
+		status := 200
+		if strings.HasSuffix(req.URL, "/missing") {
+			status = 404
+		}
+		return &domain.Response{
+			StatusCode: status,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"r1"}`)),
+		}, nil
+	}
+	expectOK := config.Expectation{Status: 200}
+
+	base := &config.ConfigV1{
+		URL: "http://example.com",
+		Finally: []config.ChainStep{
+			{Name: "cleanup", ConfigV1: config.ConfigV1{Method: "DELETE", Path: "/records/${create.id}"}},
+			{Name: "audit", SkipIf: "true", ConfigV1: config.ConfigV1{Path: "/audit"}},
+		},
+	}
+	steps := []config.ChainStep{
+		{Name: "create", ConfigV1: config.ConfigV1{Method: "POST", Path: "/records"}},
+		{Name: "optional", ContinueOnError: true, ConfigV1: config.ConfigV1{Path: "/missing", Expect: expectOK}},
+		{Name: "verify", ConfigV1: config.ConfigV1{Path: "/missing", Expect: expectOK}},
+		{Name: "after", ConfigV1: config.ConfigV1{Path: "/after"}},
+	}
+
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "step 'verify' assertion failed") {
+		t.Fatalf("expected verify to fail the chain, got %v", err)
+	}
+	wantRequests := []string{
+		"POST http://example.com/records",
+		"GET http://example.com/missing",
+		"GET http://example.com/missing",
+		"DELETE http://example.com/records/r1",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+
+	var got []string
+	for _, s := range result.Steps {
+		got = append(got, fmt.Sprintf("%s:%s:%v", s.Name, s.Status, s.Finally))
+	}
+	want := []string{
+		"create:passed:false",
+		"optional:failed:false",
+		"verify:failed:false",
+		"after:not run:false",
+		"cleanup:passed:true",
+		"audit:skipped:true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+
+	// A chain whose only failures may be ignored succeeds
+	steps = steps[:2]
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err != nil {
+		t.Errorf("continue_on_error should not fail the chain: %v", err)
+	}
+
+	// A failing finally step fails the chain, after the others have run
+	base.Finally[0].Expect = expectOK
+	base.Finally[0].Path = "/missing"
+	base.Finally[1].SkipIf = ""
+	requests = nil
+	_, err = RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "step 'cleanup'") || requests[len(requests)-1] != "GET http://example.com/audit" {
+		t.Errorf("expected cleanup to fail after audit ran, got %v %v", err, requests)
+	}
+}
+
+func TestRunChain_Vars(t *testing.T) {
+	var urls []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		urls = append(urls, req.URL)
+		return &domain.Response{
+			StatusCode: 400,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(`{"error":"invalid email"}`)),
+		}, nil
+	}
+
+	t.Setenv("email", "from-env")
+	base := &config.ConfigV1{URL: "http://example.com"}
+	steps := []config.ChainStep{{
+		Name: "signup",
+		ConfigV1: config.ConfigV1{
+			Path: "/signup?email=${email}",
+			Expect: config.Expectation{
+				Status: "${status}",
+				Assert: config.AssertionSet{Body: []string{".error == env.error"}},
+			},
+		},
+	}}
+	row := map[string]string{"email": "bad", "status": "400", "error": "invalid email"}
+
+	_, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}.WithVars(row))
+	if err != nil {
+		t.Fatalf("RunChain() error: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "http://example.com/signup?email=bad" {
+		t.Errorf("vars should take precedence over the environment: %v", urls)
+	}
+
+	row["status"] = "201"
+	_, err = RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}.WithVars(row))
+	if err == nil || !strings.Contains(err.Error(), "expected status 201, got 400") {
+		t.Errorf("expected a status mismatch, got %v", err)
+	}
+}
+
+func TestRunChain_ChainTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		mu.Lock()
+		requests = append(requests, req.URL)
+		mu.Unlock()
+		if strings.HasSuffix(req.URL, "/slow") {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &domain.Response{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"r1"}`)),
+		}, nil
+	}
+
+	base := &config.ConfigV1{
+		URL:          "http://example.com",
+		ChainTimeout: "50ms",
+		Finally: []config.ChainStep{
+			{Name: "cleanup", ConfigV1: config.ConfigV1{Method: "DELETE", Path: "/records/${create.id}"}},
+		},
+	}
+	steps := []config.ChainStep{
+		{Name: "create", ConfigV1: config.ConfigV1{Method: "POST", Path: "/records"}},
+		{Name: "wait", ContinueOnError: true, ConfigV1: config.ConfigV1{Path: "/slow"}},
+		{Name: "after", ConfigV1: config.ConfigV1{Path: "/after"}},
+	}
+
+	start := time.Now()
+	result, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "chain_timeout of 50ms exceeded") {
+		t.Fatalf("expected the chain timeout to fail the chain, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("chain took %v to stop", elapsed)
+	}
+	wantRequests := []string{
+		"http://example.com/records",
+		"http://example.com/slow",
+		"http://example.com/records/r1",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+
+	var got []string
+	for _, s := range result.Steps {
+		got = append(got, fmt.Sprintf("%s:%s:%v", s.Name, s.Status, s.Finally))
+	}
+	want := []string{
+		"create:passed:false",
+		"wait:cancelled:false",
+		"after:cancelled:false",
+		"cleanup:passed:true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+
+	// A cancelled parent context (Ctrl-C) starts no steps, but teardown still runs
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	base.ChainTimeout = ""
+	base.Finally[0].Path = "/records"
+	requests = nil
+	result, err = RunChain(ctx, &mockExecutorFactory{transport: transport}, base, steps, Options{})
+	if err == nil || !strings.Contains(err.Error(), "step 'create' cancelled") {
+		t.Errorf("expected create to be cancelled, got %v", err)
+	}
+	got = nil
+	for _, s := range result.Steps {
+		got = append(got, fmt.Sprintf("%s:%s", s.Name, s.Status))
+	}
+	want = []string{"create:cancelled", "wait:cancelled", "after:cancelled", "cleanup:passed"}
+	if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(requests, []string{"http://example.com/records"}) {
+		t.Errorf("steps = %v, requests = %v, want %v and only the teardown request", got, requests, want)
+	}
+
+	base.ChainTimeout = "soon"
+	if _, err := RunChain(context.Background(), &mockExecutorFactory{transport: transport}, base, steps, Options{}); err == nil || !strings.Contains(err.Error(), "invalid chain_timeout 'soon'") {
+		t.Errorf("expected an invalid chain_timeout error, got %v", err)
+	}
+}
+
 func TestFormatAssertionError(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1177,3 +1858,132 @@ func TestCheckExpectations_OnlyHeadersNoBody(t *testing.T) {
 		t.Errorf("AssertionsPassed = %d, want 2", res.AssertionsPassed)
 	}
 }
+
+func TestCheckExpectations_TLSAssertions(t *testing.T) {
+	result := &Result{
+		TLS: &domain.TLSInfo{
+			Version:            "TLS 1.3",
+			CipherSuite:        "TLS_AES_128_GCM_SHA256",
+			NegotiatedProtocol: "redis",
+			ServerName:         "redis.example.com",
+			PeerCertificates: []domain.CertificateInfo{
+				{Subject: "CN=redis.example.com", Issuer: "CN=Example CA", NotAfter: time.Now().Add(90 * 24 * time.Hour)},
+				{Subject: "CN=Example CA", Issuer: "CN=Example CA"},
+			},
+		},
+	}
+
+	tests := []struct {
+		assertion string
+		wantPass  bool
+	}{
+		{`.version == "TLS 1.3"`, true},
+		{`.negotiated_protocol == "redis"`, true},
+		{`.peer_certificates | length == 2`, true},
+		{`.peer_certificates[0].subject == "CN=redis.example.com"`, true},
+		{`.peer_certificates[-1].issuer == "CN=Example CA"`, true},
+		{`.version == "TLS 1.2"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			res := CheckExpectations(config.Expectation{Assert: config.AssertionSet{TLS: []string{tt.assertion}}}, result)
+			if res.AllPassed() != tt.wantPass {
+				t.Errorf("passed = %v, want %v (err: %v)", res.AllPassed(), tt.wantPass, res.Error)
+			}
+		})
+	}
+
+	// Plaintext connections expose null
+	res := CheckExpectations(config.Expectation{Assert: config.AssertionSet{TLS: []string{`. == null`}}}, &Result{})
+	if !res.AllPassed() {
+		t.Errorf("expected null TLS info for plaintext result: %v", res.Error)
+	}
+}
+
+func TestRun_ResolvesTLSPathsAgainstConfigDir(t *testing.T) {
+	configDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(configDir, "certs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"ca.pem", "client.pem", "client.key"} {
+		if err := os.WriteFile(filepath.Join(configDir, "certs", name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(t.TempDir())
+
+	got := map[string]string{}
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		for _, key := range []string{"tls_ca_cert", "tls_client_cert", "tls_client_key"} {
+			data, err := os.ReadFile(req.Metadata[key])
+			if err != nil {
+				return nil, err
+			}
+			got[key] = string(data)
+		}
+		return &domain.Response{StatusCode: 200, Headers: map[string]string{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	req := &domain.Request{
+		URL: "tls://example.com:443",
+		Metadata: map[string]string{
+			"transport":       "tcp",
+			"tls_ca_cert":     "certs/ca.pem",
+			"tls_client_cert": "certs/client.pem",
+			"tls_client_key":  "certs/client.key",
+		},
+	}
+
+	opts := Options{ConfigPath: filepath.Join(configDir, "redis.yapi.yml")}
+	if _, err := Run(context.Background(), transport, req, nil, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got["tls_ca_cert"] != "ca.pem" || got["tls_client_cert"] != "client.pem" || got["tls_client_key"] != "client.key" {
+		t.Errorf("read %v, want the files next to the config", got)
+	}
+}
+
+func TestRun_ResolvesUploadPathsAgainstConfigDir(t *testing.T) {
+	configDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(configDir, "avatar.png"), []byte("png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	absolute := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(absolute, []byte("pdf"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	got := map[string]string{}
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		var uploads map[string]string
+		if err := json.Unmarshal([]byte(req.Metadata["graphql_uploads"]), &uploads); err != nil {
+			return nil, err
+		}
+		for name, path := range uploads {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			got[name] = string(data)
+		}
+		return &domain.Response{StatusCode: 200, Headers: map[string]string{}, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	req := &domain.Request{
+		Method: "POST",
+		URL:    "http://example.com/graphql",
+		Metadata: map[string]string{
+			"transport":       "graphql",
+			"graphql_query":   "mutation($a: Upload!, $d: Upload!) { upload(a: $a, d: $d) }",
+			"graphql_uploads": `{"a":"./avatar.png","d":"` + filepath.ToSlash(absolute) + `"}`,
+		},
+	}
+
+	opts := Options{ConfigPath: filepath.Join(configDir, "upload.yapi.yml")}
+	if _, err := Run(context.Background(), transport, req, nil, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got["a"] != "png" || got["d"] != "pdf" {
+		t.Errorf("read %v, want the relative upload from the config dir and the absolute one as is", got)
+	}
+}
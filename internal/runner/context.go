@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"yapi.run/cli/internal/filter"
 	"yapi.run/cli/internal/vars"
 )
 
@@ -16,12 +20,19 @@ type StepResult struct {
 	BodyJSON   map[string]any
 	Headers    map[string]string
 	StatusCode int
+	Iterations []StepResult   // Per-item results of a foreach step
+	Captured   map[string]any // Values named by the step's capture expressions
 }
 
 // ChainContext tracks results from chain steps for variable interpolation.
+// It is safe for concurrent use by parallel steps.
 type ChainContext struct {
 	Results      map[string]StepResult
 	EnvOverrides map[string]string // Environment variables from project config
+
+	mu      sync.RWMutex
+	skipped map[string]bool
+	locals  map[string]any // item and index in a foreach iteration, and the parameters of a called file
 }
 
 // NewChainContext creates a new chain context for tracking step results.
@@ -29,11 +40,71 @@ func NewChainContext(envOverrides map[string]string) *ChainContext {
 	return &ChainContext{
 		Results:      make(map[string]StepResult),
 		EnvOverrides: envOverrides,
+		skipped:      make(map[string]bool),
 	}
 }
 
 // AddResult stores a step result for later variable interpolation.
 func (c *ChainContext) AddResult(name string, result *Result) {
+	sr := newStepResult(result)
+	c.mu.Lock()
+	c.Results[name] = sr
+	c.mu.Unlock()
+}
+
+// AddIterations stores the per-item results of a foreach step, which are referenced
+// by index: ${step.0.id}.
+func (c *ChainContext) AddIterations(name string, results []*Result) {
+	sr := StepResult{Iterations: make([]StepResult, len(results))}
+	for i, result := range results {
+		sr.Iterations[i] = newStepResult(result)
+	}
+	c.mu.Lock()
+	c.Results[name] = sr
+	c.mu.Unlock()
+}
+
+// AddCaptures stores a step's captured values, which references to the step read
+// before its body: ${step.name}.
+func (c *ChainContext) AddCaptures(name string, values map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.Results[name]
+	res.Captured = values
+	c.Results[name] = res
+}
+
+// Outputs resolves the chain_outputs section once the chain has run. Values that
+// are a single reference keep their JSON type.
+func (c *ChainContext) Outputs(outputs map[string]string) (map[string]any, error) {
+	return c.resolveNamed(outputs, "output")
+}
+
+// resolveNamed resolves a map of named values, like chain_outputs or a call's
+// `with` parameters. kind names a value in errors.
+func (c *ChainContext) resolveNamed(outputs map[string]string, kind string) (map[string]any, error) {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]any, len(outputs))
+	for _, name := range names {
+		if val, ok := c.ResolveVariableRaw(outputs[name]); ok {
+			values[name] = val
+			continue
+		}
+		val, err := c.ExpandVariables(outputs[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s '%s': %w", kind, name, err)
+		}
+		values[name] = val
+	}
+	return values, nil
+}
+
+func newStepResult(result *Result) StepResult {
 	sr := StepResult{
 		BodyRaw:    result.Body,
 		Headers:    make(map[string]string),
@@ -50,11 +121,212 @@ func (c *ChainContext) AddResult(name string, result *Result) {
 	if err := json.Unmarshal([]byte(result.Body), &data); err == nil {
 		sr.BodyJSON = data
 	}
-	c.Results[name] = sr
+	return sr
+}
+
+// forItem returns a snapshot of the context for one foreach iteration, in which
+// ${item} and ${index} resolve to the current item and its position.
+func (c *ChainContext) forItem(item any, index int) *ChainContext {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	iter := &ChainContext{
+		Results:      make(map[string]StepResult, len(c.Results)),
+		EnvOverrides: c.EnvOverrides,
+		skipped:      make(map[string]bool, len(c.skipped)),
+		locals:       make(map[string]any, len(c.locals)+2),
+	}
+	for name, val := range c.locals {
+		iter.locals[name] = val
+	}
+	iter.locals["item"] = item
+	iter.locals["index"] = float64(index)
+	for name, res := range c.Results {
+		iter.Results[name] = res
+	}
+	for name := range c.skipped {
+		iter.skipped[name] = true
+	}
+	return iter
+}
+
+// AddSkipped records a step skipped by its condition, so references to it can say so.
+func (c *ChainContext) AddSkipped(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.skipped == nil {
+		c.skipped = make(map[string]bool)
+	}
+	c.skipped[name] = true
+}
+
+// EvalCondition evaluates a `when`/`skip_if` jq expression. Its input is an object
+// mapping each finished step to its status, headers and body (parsed JSON, or the raw
+// text), or to a list of those for a foreach step; env.NAME reads the environment,
+// like in assertions.
+func (c *ChainContext) EvalCondition(expr string) (bool, error) {
+	input, env, err := c.jqInput()
+	if err != nil {
+		return false, err
+	}
+	expr = strings.ReplaceAll(expr, "env.", "$env.")
+	ok, _, err := filter.EvalJQBoolWithDetailAndVars(input, expr, map[string]any{"env": env})
+	return ok, err
+}
+
+// EvalItems evaluates a foreach jq expression, with the same input as EvalCondition,
+// to the list of items to iterate over.
+func (c *ChainContext) EvalItems(expr string) ([]any, error) {
+	input, env, err := c.jqInput()
+	if err != nil {
+		return nil, err
+	}
+	expr = strings.ReplaceAll(expr, "env.", "$env.")
+	out, err := filter.EvalJQValue(input, expr, map[string]any{"env": env})
+	if err != nil {
+		return nil, err
+	}
+	items, ok := out.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array, got %s", valueString(out))
+	}
+	return items, nil
+}
+
+// jqInput builds the input and $env variable for condition and foreach expressions.
+func (c *ChainContext) jqInput() (string, map[string]any, error) {
+	c.mu.RLock()
+	steps := make(map[string]any, len(c.Results))
+	for name, res := range c.Results {
+		if res.Iterations != nil {
+			items := make([]any, len(res.Iterations))
+			for i, it := range res.Iterations {
+				items[i] = stepValue(it)
+			}
+			steps[name] = items
+			continue
+		}
+		steps[name] = stepValue(res)
+	}
+	c.mu.RUnlock()
+
+	input, err := json.Marshal(steps)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode chain context: %w", err)
+	}
+	return string(input), c.jqEnv(), nil
+}
+
+// jqEnv returns the environment for $env in jq expressions: the environment
+// overrides, overlaid by the OS environment.
+func (c *ChainContext) jqEnv() map[string]any {
+	env := make(map[string]any)
+	for k, v := range c.EnvOverrides {
+		env[k] = v
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// evalJQRef evaluates a jq reference, ${step | expr}, against the step's body (JSON,
+// or else the raw text) with $status and $headers set. A foreach step's input is the
+// list of its iterations, as in conditions, and during an iteration ${item | expr}
+// reads the current item.
+func (c *ChainContext) evalJQRef(stepName, expr string) (any, error) {
+	variables := map[string]any{"env": c.jqEnv()}
+	var input []byte
+	if local, ok := c.locals[stepName]; ok {
+		data, err := json.Marshal(local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", stepName, err)
+		}
+		input = data
+	} else {
+		res, ok := c.result(stepName)
+		if !ok {
+			if c.wasSkipped(stepName) {
+				return nil, fmt.Errorf("step '%s' was skipped", stepName)
+			}
+			return nil, fmt.Errorf("step '%s' not found (or hasn't run yet)", stepName)
+		}
+		input = jqRefInput(res)
+		if res.Iterations == nil {
+			headers := make(map[string]any, len(res.Headers))
+			for k, v := range res.Headers {
+				headers[k] = v
+			}
+			variables["status"] = res.StatusCode
+			variables["headers"] = headers
+		}
+	}
+	expr = strings.ReplaceAll(expr, "env.", "$env.")
+	return filter.EvalJQValue(string(input), expr, variables)
+}
+
+// jqRefInput returns the JSON input of a jq reference to a step. A JSON body is
+// used as is, so large numbers keep their precision.
+func jqRefInput(res StepResult) []byte {
+	if res.Iterations != nil {
+		items := make([]any, len(res.Iterations))
+		for i, it := range res.Iterations {
+			items[i] = stepValue(it)
+		}
+		data, _ := json.Marshal(items)
+		return data
+	}
+	if json.Valid([]byte(res.BodyRaw)) {
+		return []byte(res.BodyRaw)
+	}
+	data, _ := json.Marshal(res.BodyRaw)
+	return data
+}
+
+func stepValue(res StepResult) map[string]any {
+	var body any = res.BodyRaw
+	if res.BodyJSON != nil {
+		body = res.BodyJSON
+	}
+	value := map[string]any{
+		"status":  res.StatusCode,
+		"headers": res.Headers,
+		"body":    body,
+	}
+	if res.Captured != nil {
+		value["capture"] = res.Captured
+	}
+	return value
+}
+
+func (c *ChainContext) wasSkipped(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.skipped[name]
+}
+
+// result returns the stored result of a step.
+func (c *ChainContext) result(name string) (StepResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res, ok := c.Results[name]
+	return res, ok
 }
 
 // ExpandVariables replaces $var and ${var} with values from Env or Chain Context.
 func (c *ChainContext) ExpandVariables(input string) (string, error) {
+	return c.expand(input, func(val string) string { return val })
+}
+
+// ExpandRegex expands variables in a regular expression, quoting the substituted
+// values so they match literally.
+func (c *ChainContext) ExpandRegex(input string) (string, error) {
+	return c.expand(input, regexp.QuoteMeta)
+}
+
+// expand replaces $var and ${var}, passing each substituted value through quote.
+func (c *ChainContext) expand(input string, quote func(string) string) (string, error) {
 	var capturedErr error
 
 	result := vars.Expansion.ReplaceAllStringFunc(input, func(match string) string {
@@ -67,19 +339,40 @@ func (c *ChainContext) ExpandVariables(input string) (string, error) {
 			key = match[1:]
 		}
 
+		// 0. Foreach item and index
+		if val, ok, err := c.lookupLocal(key); ok {
+			if err != nil {
+				if capturedErr == nil {
+					capturedErr = err
+				}
+				return match
+			}
+			return quote(val)
+		}
+
 		// 1. Check OS Environment (highest priority)
 		if val, ok := os.LookupEnv(key); ok {
-			return val
+			return quote(val)
 		}
 
 		// 2. Check Environment Overrides from project config
 		if c.EnvOverrides != nil {
 			if val, ok := c.EnvOverrides[key]; ok {
-				return val
+				return quote(val)
 			}
 		}
 
-		// 3. Check Chain Context (must contain dot)
+		// 3. Check Chain Context (must contain a dot, or a pipe for jq)
+		if step, expr, isJQ, ok := vars.ChainRef(key); ok && isJQ {
+			val, err := c.evalJQRef(step, expr)
+			if err != nil {
+				if capturedErr == nil {
+					capturedErr = err
+				}
+				return match
+			}
+			return quote(valueString(val))
+		}
 		if strings.Contains(key, ".") {
 			val, err := c.resolveChainVar(key)
 			if err != nil {
@@ -88,7 +381,7 @@ func (c *ChainContext) ExpandVariables(input string) (string, error) {
 				}
 				return match // Return original on error
 			}
-			return val
+			return quote(val)
 		}
 
 		// Not found: return as is (or could error if strict mode)
@@ -110,10 +403,45 @@ func (c *ChainContext) resolveChainVar(key string) (string, error) {
 	stepName := parts[0]
 	path := parts[1:]
 
-	res, ok := c.Results[stepName]
+	res, ok := c.result(stepName)
 	if !ok {
+		if c.wasSkipped(stepName) {
+			return "", fmt.Errorf("step '%s' was skipped", stepName)
+		}
 		return "", fmt.Errorf("step '%s' not found (or hasn't run yet)", stepName)
 	}
+	return resolveStepPath(stepName, res, path, key)
+}
+
+// lookupLocal resolves ${item}, ${item.field} and ${index} during a foreach iteration,
+// and the parameters a called file was given.
+func (c *ChainContext) lookupLocal(key string) (string, bool, error) {
+	parts := strings.Split(key, ".")
+	local, ok := c.locals[parts[0]]
+	if !ok {
+		return "", false, nil
+	}
+	val, err := jsonPathLookup(local, parts[1:])
+	return val, true, err
+}
+
+func resolveStepPath(stepName string, res StepResult, path []string, key string) (string, error) {
+	// Captured values come first, so they hide body fields of the same name
+	if val, ok := res.Captured[path[0]]; ok {
+		return jsonPathLookup(val, path[1:])
+	}
+
+	// 0. Foreach steps are indexed by iteration
+	if res.Iterations != nil {
+		idx, err := strconv.Atoi(path[0])
+		if err != nil || idx < 0 || idx >= len(res.Iterations) {
+			return "", fmt.Errorf("step '%s' ran %d iterations; reference one by index (e.g. %s.0.%s)", stepName, len(res.Iterations), stepName, path[0])
+		}
+		if len(path) == 1 {
+			return res.Iterations[idx].BodyRaw, nil
+		}
+		return resolveStepPath(stepName+"."+path[0], res.Iterations[idx], path[1:], key)
+	}
 
 	// 1. Reserved Keywords
 	if len(path) == 1 {
@@ -169,31 +497,42 @@ func jsonPathLookup(data any, path []string) (string, error) {
 				return "", fmt.Errorf("key '%s' not found at path '%s'", key, strings.Join(path[:i+1], "."))
 			}
 			current = val
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", fmt.Errorf("index '%s' out of range at path '%s'", key, strings.Join(path[:i+1], "."))
+			}
+			current = v[idx]
 		default:
 			return "", fmt.Errorf("path segment '%s' is not an object", strings.Join(path[:i], "."))
 		}
 	}
-	// Convert final value to string
-	switch v := current.(type) {
+	return valueString(current), nil
+}
+
+// valueString formats a referenced value for interpolation: strings as is, numbers
+// without a trailing .0, and objects and arrays as JSON.
+func valueString(value any) string {
+	switch v := value.(type) {
 	case string:
-		return v, nil
+		return v
 	case float64:
 		// Check if it's actually an integer
 		if v == float64(int(v)) {
-			return strconv.Itoa(int(v)), nil
+			return strconv.Itoa(int(v))
 		}
-		return fmt.Sprintf("%v", v), nil
+		return fmt.Sprintf("%v", v)
 	case bool:
-		return strconv.FormatBool(v), nil
+		return strconv.FormatBool(v)
 	case nil:
-		return "null", nil
+		return "null"
 	default:
 		// For complex types, marshal to JSON
 		jsonBytes, err := json.Marshal(v)
 		if err != nil {
-			return fmt.Sprintf("%v", v), nil
+			return fmt.Sprintf("%v", v)
 		}
-		return string(jsonBytes), nil
+		return string(jsonBytes)
 	}
 }
 
@@ -222,12 +561,21 @@ func (c *ChainContext) ResolveVariableRaw(input string) (any, bool) {
 		key = match[2]
 	}
 
-	// Must contain a dot to be a chain reference
-	if !strings.Contains(key, ".") {
-		return nil, false
+	// jq references keep the type of their result
+	if step, expr, isJQ, ok := vars.ChainRef(key); ok && isJQ {
+		val, err := c.evalJQRef(step, expr)
+		return val, err == nil
 	}
 
 	parts := strings.Split(key, ".")
+
+	// Foreach item and index keep their type
+	if local, ok := c.locals[parts[0]]; ok {
+		val, err := jsonPathLookupRaw(local, parts[1:])
+		return val, err == nil
+	}
+
+	// Must contain a dot to be a chain reference
 	if len(parts) < 2 {
 		return nil, false
 	}
@@ -235,10 +583,21 @@ func (c *ChainContext) ResolveVariableRaw(input string) (any, bool) {
 	stepName := parts[0]
 	path := parts[1:]
 
-	res, ok := c.Results[stepName]
+	res, ok := c.result(stepName)
 	if !ok {
 		return nil, false
 	}
+	if val, ok := res.Captured[path[0]]; ok {
+		val, err := jsonPathLookupRaw(val, path[1:])
+		return val, err == nil
+	}
+	for res.Iterations != nil && len(path) > 1 {
+		idx, err := strconv.Atoi(path[0])
+		if err != nil || idx < 0 || idx >= len(res.Iterations) {
+			return nil, false
+		}
+		res, path = res.Iterations[idx], path[1:]
+	}
 
 	// JSON Path lookup returning raw value
 	if res.BodyJSON == nil {
@@ -264,6 +623,12 @@ func jsonPathLookupRaw(data any, path []string) (any, error) {
 				return nil, fmt.Errorf("key '%s' not found at path '%s'", key, strings.Join(path[:i+1], "."))
 			}
 			current = val
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, fmt.Errorf("index '%s' out of range at path '%s'", key, strings.Join(path[:i+1], "."))
+			}
+			current = v[idx]
 		default:
 			return nil, fmt.Errorf("path segment '%s' is not an object", strings.Join(path[:i], "."))
 		}
@@ -115,6 +115,26 @@ func TestChainContext_ExpandVariables_ChainRefs(t *testing.T) {
 			input:    "Bearer ${login.access_token}",
 			expected: "Bearer abc123",
 		},
+		{
+			name:     "jq reference",
+			input:    "${login | .user.name | ascii_upcase}",
+			expected: "TEST",
+		},
+		{
+			name:     "jq reference with status and headers",
+			input:    `${login | "\($status) \($headers["X-Custom"])"}`,
+			expected: "200 custom-value",
+		},
+		{
+			name:     "jq reference to an object",
+			input:    "${login | .user}",
+			expected: `{"id":42,"name":"test"}`,
+		},
+		{
+			name:    "jq reference with several results",
+			input:   "${login | .user[]}",
+			wantErr: true,
+		},
 		{
 			name:    "reference undefined step",
 			input:   "${undefined.token}",
@@ -199,6 +219,7 @@ func TestJsonPathLookup(t *testing.T) {
 		"nested": map[string]any{
 			"deep": "nested_value",
 		},
+		"items": []any{map[string]any{"id": "a"}, map[string]any{"id": "b"}},
 	}
 
 	tests := []struct {
@@ -227,6 +248,16 @@ func TestJsonPathLookup(t *testing.T) {
 			path:     []string{"nested", "deep"},
 			expected: "nested_value",
 		},
+		{
+			name:     "array index",
+			path:     []string{"items", "1", "id"},
+			expected: "b",
+		},
+		{
+			name:    "array index out of range",
+			path:    []string{"items", "2", "id"},
+			wantErr: true,
+		},
 		{
 			name:    "non-existent key",
 			path:    []string{"nonexistent"},
@@ -252,3 +283,39 @@ func TestJsonPathLookup(t *testing.T) {
 		})
 	}
 }
+
+func TestChainContext_JQReferences(t *testing.T) {
+	ctx := NewChainContext(nil)
+	ctx.Results["list"] = StepResult{
+		BodyRaw:    `{"items":[{"id":9007199254740993,"active":false},{"id":2,"active":true}]}`,
+		StatusCode: 200,
+	}
+	ctx.Results["tags"] = StepResult{BodyRaw: `["a","b","c"]`}
+	ctx.AddIterations("cleanup", []*Result{{StatusCode: 204}, {StatusCode: 404}})
+
+	got, err := ctx.ExpandVariables("/items/${list | .items[0].id}?active=${list | [.items[] | select(.active).id] | first}")
+	if err != nil {
+		t.Fatalf("ExpandVariables() error: %v", err)
+	}
+	if got != "/items/9007199254740993?active=2" {
+		t.Errorf("ExpandVariables() = %s", got)
+	}
+
+	raw := map[string]any{
+		"${list | .items[1].active}":                        true,
+		"${tags | length}":                                  3,
+		"${tags | .[1]}":                                    "b",
+		"${cleanup | map(select(.status == 404)) | length}": 1,
+	}
+	for input, want := range raw {
+		val, ok := ctx.ResolveVariableRaw(input)
+		if !ok || val != want {
+			t.Errorf("ResolveVariableRaw(%s) = %v (%T), %v; want %v (%T)", input, val, val, ok, want, want)
+		}
+	}
+
+	item := ctx.forItem(map[string]any{"id": "x1", "tags": []any{"a"}}, 0)
+	if got, err := item.ExpandVariables("${item | .tags | length}-${item.id}"); err != nil || got != "1-x1" {
+		t.Errorf("item reference = %q, %v", got, err)
+	}
+}
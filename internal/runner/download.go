@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"yapi.run/cli/internal/domain"
+)
+
+// maxBufferedDownload is the largest streamed response kept in memory for display,
+// assertions and chain references. Larger bodies are only written to disk.
+const maxBufferedDownload = 10 << 20
+
+// downloadResult describes a response body streamed to a file.
+type downloadResult struct {
+	Body    []byte // Response body, nil if it exceeded maxBufferedDownload
+	Written int64  // Bytes written by this request (excludes resumed prefix)
+	Resumed bool   // True if the bytes were appended to a partial file
+}
+
+// prepareResume sets a Range header to continue a partial download and returns
+// the offset requested. Returns 0 if there is nothing to resume.
+func prepareResume(req *domain.Request, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 {
+		return 0
+	}
+	req.SetHeader("Range", fmt.Sprintf("bytes=%d-", info.Size()))
+	return info.Size()
+}
+
+// streamToFile copies the response body to path without buffering the whole body.
+// If offset > 0 the partial file is only replaced when the server answered 200 with
+// the full body. A 206 Partial Content starting at offset is appended, and a 416
+// reporting a length of offset means the file is already complete. Any other
+// status fails and leaves the partial file untouched.
+func streamToFile(resp *domain.Response, path string, offset int64, progress io.Writer) (*downloadResult, error) {
+	res := &downloadResult{}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		contentRange := resp.Headers["Content-Range"]
+		switch resp.StatusCode {
+		case http.StatusOK:
+			offset = 0 // Server ignored the Range header; start over
+		case http.StatusPartialContent:
+			if start, ok := contentRangeStart(contentRange); !ok || start != offset {
+				return nil, fmt.Errorf("cannot resume '%s': requested bytes from %d, got Content-Range '%s'", path, offset, contentRange)
+			}
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+			res.Resumed = true
+		case http.StatusRequestedRangeNotSatisfiable:
+			if contentRange != fmt.Sprintf("bytes */%d", offset) {
+				return nil, fmt.Errorf("cannot resume '%s': server rejected range from byte %d (Content-Range '%s')", path, offset, contentRange)
+			}
+			res.Resumed = true
+			return res, nil
+		default:
+			return nil, fmt.Errorf("cannot resume '%s': server returned status %d; partial file kept", path, resp.StatusCode)
+		}
+	}
+
+	f, err := os.OpenFile(path, flags, 0600) // #nosec G304 -- output_file is a user-provided path
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file '%s': %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := &cappedBuffer{limit: maxBufferedDownload}
+	var w io.Writer = io.MultiWriter(f, buf)
+
+	if progress != nil {
+		total, _ := strconv.ParseInt(resp.Headers["Content-Length"], 10, 64)
+		if total > 0 {
+			total += offset
+		}
+		pw := &progressWriter{out: progress, done: offset, total: total}
+		defer pw.finish()
+		w = io.MultiWriter(w, pw)
+	}
+
+	res.Written, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write output file '%s': %w", path, err)
+	}
+
+	if !buf.overflow {
+		res.Body = buf.Bytes()
+	}
+	return res, nil
+}
+
+// contentRangeStart returns the first byte position of a "bytes start-end/total"
+// Content-Range header.
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+// verifySHA256 checks that the file at path has the expected hex-encoded SHA-256 digest.
+func verifySHA256(path, expected string) error {
+	f, err := os.Open(path) // #nosec G304 -- output_file is a user-provided path
+	if err != nil {
+		return fmt.Errorf("failed to open '%s' for checksum: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read '%s' for checksum: %w", path, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch for '%s': expected sha256 %s, got %s", path, expected, actual)
+	}
+	return nil
+}
+
+// cappedBuffer buffers writes until limit is exceeded, then discards everything.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if b.Len()+len(p) > b.limit {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// progressWriter renders a single-line download progress indicator.
+type progressWriter struct {
+	out       io.Writer
+	done      int64
+	total     int64 // 0 if unknown
+	lastPrint time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if time.Since(p.lastPrint) >= 100*time.Millisecond {
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) print() {
+	p.lastPrint = time.Now()
+	if p.total > 0 {
+		pct := float64(p.done) / float64(p.total) * 100
+		fmt.Fprintf(p.out, "\rDownloading: %s / %s (%.0f%%)", formatSize(p.done), formatSize(p.total), pct)
+		return
+	}
+	fmt.Fprintf(p.out, "\rDownloading: %s", formatSize(p.done))
+}
+
+func (p *progressWriter) finish() {
+	p.print()
+	fmt.Fprintln(p.out)
+}
+
+// formatSize formats a byte count using SI units (e.g. 1.5 MB).
+func formatSize(b int64) string {
+	const unit = 1000
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,238 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/executor"
+)
+
+func TestStreamToFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     string
+		offset       int64
+		status       int
+		contentRange string
+		body         string
+		wantFile     string
+		wantResumed  bool
+		wantErr      string
+	}{
+		{
+			name:     "fresh download",
+			status:   200,
+			body:     "hello world",
+			wantFile: "hello world",
+		},
+		{
+			name:         "resume appends on 206",
+			existing:     "hello ",
+			offset:       6,
+			status:       206,
+			contentRange: "bytes 6-10/11",
+			body:         "world",
+			wantFile:     "hello world",
+			wantResumed:  true,
+		},
+		{
+			name:         "206 from the wrong offset",
+			existing:     "hello ",
+			offset:       6,
+			status:       206,
+			contentRange: "bytes 0-10/11",
+			body:         "hello world",
+			wantFile:     "hello ",
+			wantErr:      "got Content-Range 'bytes 0-10/11'",
+		},
+		{
+			name:     "error status keeps partial file",
+			existing: "hello ",
+			offset:   6,
+			status:   503,
+			body:     "unavailable",
+			wantFile: "hello ",
+			wantErr:  "server returned status 503",
+		},
+		{
+			name:     "server ignores range",
+			existing: "stale",
+			offset:   5,
+			status:   200,
+			body:     "hello world",
+			wantFile: "hello world",
+		},
+		{
+			name:         "already complete on 416",
+			existing:     "hello world",
+			offset:       11,
+			status:       416,
+			contentRange: "bytes */11",
+			wantFile:     "hello world",
+			wantResumed:  true,
+		},
+		{
+			name:         "416 for a different length",
+			existing:     "hello world",
+			offset:       11,
+			status:       416,
+			contentRange: "bytes */5",
+			wantFile:     "hello world",
+			wantErr:      "server rejected range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.bin")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0600); err != nil {
+					t.Fatalf("failed to seed file: %v", err)
+				}
+			}
+
+			headers := map[string]string{}
+			if tt.contentRange != "" {
+				headers["Content-Range"] = tt.contentRange
+			}
+			resp := &domain.Response{
+				StatusCode: tt.status,
+				Headers:    headers,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			dl, err := streamToFile(resp, path, tt.offset, nil)
+
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.wantFile {
+				t.Errorf("file content = %q, want %q", got, tt.wantFile)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("streamToFile failed: %v", err)
+			}
+			if dl.Resumed != tt.wantResumed {
+				t.Errorf("Resumed = %v, want %v", dl.Resumed, tt.wantResumed)
+			}
+			if string(dl.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", dl.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestStreamToFile_LargeBodyNotBuffered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.bin")
+	size := maxBufferedDownload + 1
+	resp := &domain.Response{
+		StatusCode: 200,
+		Headers:    map[string]string{},
+		Body:       io.NopCloser(io.LimitReader(zeroReader{}, int64(size))),
+	}
+
+	dl, err := streamToFile(resp, path, 0, io.Discard)
+	if err != nil {
+		t.Fatalf("streamToFile failed: %v", err)
+	}
+	if dl.Body != nil {
+		t.Errorf("expected body larger than %d bytes not to be buffered", maxBufferedDownload)
+	}
+	if dl.Written != int64(size) {
+		t.Errorf("Written = %d, want %d", dl.Written, size)
+	}
+}
+
+func TestVerifySHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("yapi"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	sum := sha256.Sum256([]byte("yapi"))
+	digest := hex.EncodeToString(sum[:])
+
+	if err := verifySHA256(path, digest); err != nil {
+		t.Errorf("expected checksum to match: %v", err)
+	}
+	if err := verifySHA256(path, strings.ToUpper(digest)); err != nil {
+		t.Errorf("expected checksum comparison to be case-insensitive: %v", err)
+	}
+	if err := verifySHA256(path, strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected checksum mismatch error, got %v", err)
+	}
+}
+
+func TestRun_ResumesOutputFile(t *testing.T) {
+	const content = "0123456789abcdef"
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		var start int
+		if _, err := fmt.Sscanf(gotRange, "bytes=%d-", &start); err == nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[start:]))
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "download.bin")
+	if err := os.WriteFile(path, []byte(content[:10]), 0600); err != nil {
+		t.Fatalf("failed to seed partial file: %v", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+
+	req := &domain.Request{
+		URL:    srv.URL,
+		Method: "GET",
+		Metadata: map[string]string{
+			"transport":     "http",
+			"output_file":   path,
+			"output_resume": "true",
+			"output_sha256": hex.EncodeToString(sum[:]),
+		},
+	}
+
+	result, err := Run(context.Background(), executor.HTTPTransport(&http.Client{}), req, nil, Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if gotRange != "bytes=10-" {
+		t.Errorf("Range header = %q, want bytes=10-", gotRange)
+	}
+	if result.StatusCode != http.StatusPartialContent {
+		t.Errorf("StatusCode = %d, want 206", result.StatusCode)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != content {
+		t.Errorf("file content = %q, want %q", got, content)
+	}
+	if result.OutputFile != path {
+		t.Errorf("OutputFile = %q, want %q", result.OutputFile, path)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// EncodedBody is the JSON body produced by response_encoding. Binary responses
+// become a hex or base64 string that jq filters and assertions can inspect.
+type EncodedBody struct {
+	Encoding    string `json:"encoding"`
+	Size        int    `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        string `json:"data"`
+}
+
+// encodeBody wraps raw response bytes per the response_encoding option.
+func encodeBody(b []byte, encoding, contentType string) (string, error) {
+	enc := EncodedBody{Encoding: encoding, Size: len(b), ContentType: contentType}
+	switch encoding {
+	case "hex":
+		enc.Data = hex.EncodeToString(b)
+	case "base64":
+		enc.Data = base64.StdEncoding.EncodeToString(b)
+	default:
+		return "", fmt.Errorf("unsupported response_encoding: %s (must be hex or base64)", encoding)
+	}
+
+	out, err := json.Marshal(enc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode response body: %w", err)
+	}
+	return string(out), nil
+}
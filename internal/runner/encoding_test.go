@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/executor"
+	"yapi.run/cli/internal/filter"
+)
+
+func TestEncodeBody(t *testing.T) {
+	tests := []struct {
+		encoding string
+		want     string
+	}{
+		{"hex", `{"encoding":"hex","size":4,"content_type":"image/png","data":"89504e47"}`},
+		{"base64", `{"encoding":"base64","size":4,"content_type":"image/png","data":"iVBORw=="}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			got, err := encodeBody([]byte("\x89PNG"), tt.encoding, "image/png")
+			if err != nil {
+				t.Fatalf("encodeBody failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("encodeBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := encodeBody([]byte("x"), "text", ""); err == nil {
+		t.Error("expected error for unsupported encoding")
+	}
+}
+
+func TestRun_ResponseEncodingMakesBinaryAssertable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte{0x00, 0x01, 0xfe, 0xff})
+	}))
+	defer srv.Close()
+
+	req := &domain.Request{
+		URL:    srv.URL,
+		Method: "GET",
+		Metadata: map[string]string{
+			"transport":         "http",
+			"response_encoding": "hex",
+		},
+	}
+
+	result, err := Run(context.Background(), executor.HTTPTransport(&http.Client{}), req, nil, Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ContentType != "application/json" {
+		t.Errorf("ContentType = %q, want application/json", result.ContentType)
+	}
+	got, err := filter.ApplyJQ(result.Body, `.data == "0001feff" and .size == 4`)
+	if err != nil {
+		t.Fatalf("jq on encoded body failed: %v", err)
+	}
+	if got != "true" {
+		t.Errorf("encoded body %s did not match assertion", result.Body)
+	}
+	if result.BodyBytes != 4 {
+		t.Errorf("BodyBytes = %d, want 4", result.BodyBytes)
+	}
+}
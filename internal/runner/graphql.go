@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphQLError is an entry of a GraphQL response's `errors` array.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitempty"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+
+	Operation string `json:"-"` // Batch result the error belongs to
+}
+
+// PathString renders the error path as user.posts[0].title, or "" if there is none.
+func (e GraphQLError) PathString() string {
+	var sb strings.Builder
+	for _, seg := range e.Path {
+		switch v := seg.(type) {
+		case float64:
+			fmt.Fprintf(&sb, "[%d]", int(v))
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			fmt.Fprint(&sb, v)
+		}
+	}
+	return sb.String()
+}
+
+// String renders the error prefixed by its path, or by its query location if it has
+// none, and by its operation for batched requests.
+func (e GraphQLError) String() string {
+	msg := e.Message
+	if path := e.PathString(); path != "" {
+		msg = path + ": " + msg
+	} else if len(e.Locations) > 0 {
+		msg = fmt.Sprintf("%d:%d: %s", e.Locations[0].Line, e.Locations[0].Column, msg)
+	}
+	if e.Operation != "" {
+		msg = e.Operation + ": " + msg
+	}
+	return msg
+}
+
+// graphqlErrors extracts the `errors` array of a GraphQL response body, of every
+// event collected from a subscription, or of every result of a batch (keyed by
+// operation). Bodies that are not GraphQL responses have no errors.
+func graphqlErrors(body []byte, batched bool) []GraphQLError {
+	var resp struct {
+		Errors []GraphQLError `json:"errors"`
+		Events []struct {
+			Errors []GraphQLError `json:"errors"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+	errs := resp.Errors
+	for _, event := range resp.Events {
+		errs = append(errs, event.Errors...)
+	}
+	if !batched || len(errs) > 0 {
+		return errs
+	}
+
+	var results map[string]struct {
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, e := range results[name].Errors {
+			e.Operation = name
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
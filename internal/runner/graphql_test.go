@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/executor"
+)
+
+func TestGraphQLError_String(t *testing.T) {
+	tests := []struct {
+		err  GraphQLError
+		want string
+	}{
+		{GraphQLError{Message: "boom"}, "boom"},
+		{GraphQLError{Message: "denied", Path: []any{"user", "posts", float64(0), "title"}}, "user.posts[0].title: denied"},
+	}
+	var located GraphQLError
+	if err := json.Unmarshal([]byte(`{"message":"bad field","locations":[{"line":1,"column":22}]}`), &located); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	tests = append(tests, struct {
+		err  GraphQLError
+		want string
+	}{located, "1:22: bad field"})
+
+	for _, tt := range tests {
+		if got := tt.err.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGraphQLErrors_SubscriptionEvents(t *testing.T) {
+	body := []byte(`{"count":2,"events":[{"data":{"tick":1}},{"errors":[{"message":"stream closed"}]}]}`)
+	errs := graphqlErrors(body, false)
+	if len(errs) != 1 || errs[0].Message != "stream closed" {
+		t.Errorf("graphqlErrors() = %+v, want the error of the second event", errs)
+	}
+}
+
+func TestGraphQLErrors_Batch(t *testing.T) {
+	body := []byte(`{"GetUser":{"data":{"user":null},"errors":[{"message":"not found","path":["user"]}]},"ListPosts":{"data":{"posts":[]}}}`)
+	if errs := graphqlErrors(body, false); len(errs) != 0 {
+		t.Errorf("unbatched body should have no errors, got %+v", errs)
+	}
+	errs := graphqlErrors(body, true)
+	if len(errs) != 1 || errs[0].String() != "GetUser: user: not found" {
+		t.Errorf("graphqlErrors() = %+v, want the GetUser error", errs)
+	}
+}
+
+func TestRun_GraphQLErrorsFailUnlessAllowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"user":{"name":"Ada","email":null}},"errors":[{"message":"Not authorized","path":["user","email"]}]}`))
+	}))
+	defer srv.Close()
+
+	req := &domain.Request{
+		URL:    srv.URL,
+		Method: "POST",
+		Metadata: map[string]string{
+			"transport":     "graphql",
+			"graphql_query": "{ user { name email } }",
+			"jq_filter":     ".data.user",
+		},
+	}
+
+	result, err := Run(context.Background(), executor.GraphQLTransport(&http.Client{}), req, nil, Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.GraphQLErrors) != 1 || result.GraphQLErrors[0].String() != "user.email: Not authorized" {
+		t.Fatalf("GraphQLErrors = %+v", result.GraphQLErrors)
+	}
+
+	res := CheckExpectationsWithEnv(config.Expectation{}, result, nil)
+	if res.Error == nil || !strings.Contains(res.Error.Error(), "Not authorized") {
+		t.Errorf("expected GraphQL errors to fail, got %v", res.Error)
+	}
+	if !res.GraphQLChecked || res.GraphQLPassed {
+		t.Errorf("expected failed GraphQL check, got %+v", res)
+	}
+
+	// Allowed errors leave the partial data assertable
+	allow := config.Expectation{AllowErrors: true, Assert: config.AssertionSet{Body: []string{`.name == "Ada"`}}}
+	if res := CheckExpectationsWithEnv(allow, result, nil); res.Error != nil {
+		t.Errorf("expected allowed errors to pass, got %v", res.Error)
+	}
+}
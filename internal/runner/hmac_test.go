@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"yapi.run/cli/internal/domain"
+)
+
+func TestSignHMAC(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name       string
+		meta       map[string]string
+		req        *domain.Request
+		wantHeader string
+		wantValue  string
+	}{
+		{
+			name: "default template, sha256 hex",
+			meta: map[string]string{"hmac_secret": "topsecret"},
+			req: &domain.Request{
+				Method: "POST",
+				URL:    "https://partner.example.com/v1/payments",
+				Body:   strings.NewReader(`{"amount":100}`),
+			},
+			wantHeader: "X-Signature",
+			wantValue:  "519503988ae92aca17ba7939a5eb0bfcbda1d36333144f34f26f6cc25696783a",
+		},
+		{
+			name: "custom template, sha512 base64 with prefix",
+			meta: map[string]string{
+				"hmac_secret":    "k",
+				"hmac_algorithm": "sha512",
+				"hmac_format":    "base64",
+				"hmac_template":  "{method} {path}?{query}",
+				"hmac_header":    "X-Partner-Signature",
+				"hmac_prefix":    "v1=",
+			},
+			req: &domain.Request{
+				Method: "GET",
+				URL:    "https://partner.example.com/orders?limit=5",
+			},
+			wantHeader: "X-Partner-Signature",
+			wantValue:  "v1=S2cPkVv5+WQc4IDHNjLyyHkkxRtoWHz1IYs9k38VWvUQawt0pU+oo4Z8ipsW54pZzxmswDBk1aL7eKRt+isYWQ==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Metadata = tt.meta
+			if err := signHMAC(tt.req, now); err != nil {
+				t.Fatalf("signHMAC failed: %v", err)
+			}
+			if got := tt.req.Headers[tt.wantHeader]; got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSignHMAC_PreservesBodyAndSetsTimestamp(t *testing.T) {
+	req := &domain.Request{
+		Method:   "POST",
+		URL:      "https://example.com/hook",
+		Body:     strings.NewReader("payload"),
+		Metadata: map[string]string{"hmac_secret": "s", "hmac_timestamp_header": "X-Timestamp"},
+	}
+
+	if err := signHMAC(req, time.Unix(42, 0)); err != nil {
+		t.Fatalf("signHMAC failed: %v", err)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "payload" {
+		t.Errorf("body not preserved after signing, got %q", string(body))
+	}
+	if req.Headers["X-Timestamp"] != "42" {
+		t.Errorf("X-Timestamp = %q, want 42", req.Headers["X-Timestamp"])
+	}
+}
+
+func TestSignHMAC_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    map[string]string
+		wantErr string
+	}{
+		{"missing secret", map[string]string{}, "requires `secret`"},
+		{"bad algorithm", map[string]string{"hmac_secret": "s", "hmac_algorithm": "md5"}, "unsupported hmac algorithm"},
+		{"bad format", map[string]string{"hmac_secret": "s", "hmac_format": "base32"}, "unsupported hmac format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signHMAC(&domain.Request{Method: "GET", URL: "https://example.com", Metadata: tt.meta}, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRun_HMACSignsFinalRequest(t *testing.T) {
+	var got *domain.Request
+	transport := func(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+		got = req
+		return &domain.Response{StatusCode: 200, Headers: map[string]string{}, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	}
+	req := &domain.Request{
+		Method: "GET",
+		URL:    "https://config.example.com/orders",
+		Metadata: map[string]string{
+			"transport":     "http",
+			"auth_type":     "hmac",
+			"hmac_secret":   "k",
+			"hmac_template": "{url}",
+		},
+	}
+
+	if _, err := Run(context.Background(), transport, req, nil, Options{URLOverride: "https://override.example.com/orders"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte("k"))
+	mac.Write([]byte("https://override.example.com/orders"))
+	if want := hex.EncodeToString(mac.Sum(nil)); got.Headers["X-Signature"] != want {
+		t.Errorf("X-Signature = %q, want the signature of the overridden URL %q", got.Headers["X-Signature"], want)
+	}
+}
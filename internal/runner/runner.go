@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/constants"
 	"yapi.run/cli/internal/domain"
 	"yapi.run/cli/internal/executor"
 	"yapi.run/cli/internal/filter"
@@ -18,16 +22,21 @@ import (
 
 // Result holds the output of a yapi execution
 type Result struct {
-	Body        string
-	ContentType string
-	StatusCode  int
-	Warnings    []string
-	RequestURL  string        // The full constructed URL (HTTP/GraphQL only)
-	Duration    time.Duration // Time taken for the request
-	BodyLines   int
-	BodyChars   int
-	BodyBytes   int
-	Headers     map[string]string // Response headers
+	Body          string
+	ContentType   string
+	StatusCode    int
+	Warnings      []string
+	RequestURL    string        // The full constructed URL (HTTP/GraphQL only)
+	Duration      time.Duration // Time taken for the request
+	BodyLines     int
+	BodyChars     int
+	BodyBytes     int
+	Headers       map[string]string // Response headers
+	OutputFile    string            // File the response was saved to, if any
+	Cache         *CacheInfo        // Response cache outcome, nil if caching was not used
+	TLS           *domain.TLSInfo   // Negotiated TLS session for tls:// and tcps:// requests
+	GraphQLErrors []GraphQLError    // Entries of a GraphQL response's `errors` array
+	Attempts      []Attempt         // Requests made by a wait_until step, the last one included
 }
 
 // Options for execution
@@ -39,6 +48,26 @@ type Options struct {
 	EnvOverrides map[string]string // Environment variables from project config
 	ProjectRoot  string            // Path to project root (for validation)
 	ProjectEnv   string            // Selected environment name (for validation)
+	CacheDir     string            // Response cache directory (default ~/.yapi/cache)
+	ConfigPath   string            // File being run; relative file paths in it resolve against its directory
+	Vars         map[string]string // Variables ahead of the environment, such as a matrix row
+
+	callStack []string // Files being run by enclosing call steps, outermost first
+}
+
+// WithVars returns the options with vars taking precedence over the environment.
+// Assertions also see them as env.NAME.
+func (o Options) WithVars(vars map[string]string) Options {
+	o.Vars = vars
+	env := make(map[string]string, len(o.EnvOverrides)+len(vars))
+	for k, v := range o.EnvOverrides {
+		env[k] = v
+	}
+	for k, v := range vars {
+		env[k] = v
+	}
+	o.EnvOverrides = env
+	return o
 }
 
 // Run executes a yapi request and returns the result.
@@ -55,19 +84,87 @@ func Run(ctx context.Context, exec executor.TransportFunc, req *domain.Request,
 		req.URL = opts.URLOverride
 	}
 
+	applyProjectCredentials(req, opts.EnvOverrides)
+	resolveConfigPaths(req, opts.ConfigPath)
+
+	// Stream output_file downloads straight to disk unless a jq filter needs the whole body
+	outputFile := req.Metadata["output_file"]
+	streamOutput := outputFile != "" && req.Metadata["jq_filter"] == ""
+
+	var resumeOffset int64
+	if streamOutput && req.Metadata["output_resume"] == "true" && req.Metadata["transport"] == constants.TransportHTTP {
+		resumeOffset = prepareResume(req, outputFile)
+	}
+
+	cache, err := openCache(req, opts.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign last so the signature covers the URL and headers actually sent
+	if req.Metadata["auth_type"] == constants.AuthHMAC {
+		if err := signHMAC(req, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Execute the request
 	resp, err := exec(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	if cache != nil {
+		cache.serveNotModified(resp)
+	}
 	defer func() { _ = resp.Body.Close() }()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	var bodyBytes []byte
+	var bodySize int
+	if streamOutput {
+		var progress io.Writer
+		if isatty.IsTerminal(os.Stderr.Fd()) {
+			progress = os.Stderr
+		}
+		dl, err := streamToFile(resp, outputFile, resumeOffset, progress)
+		if err != nil {
+			return nil, err
+		}
+		bodyBytes = dl.Body
+		bodySize = int(dl.Written)
+	} else {
+		bodyBytes, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		bodySize = len(bodyBytes)
 	}
 	body := string(bodyBytes)
 
+	var cacheInfo *CacheInfo
+	if cache != nil {
+		if cacheInfo, err = cache.update(req, resp, bodyBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Detect GraphQL errors before a jq filter can drop them
+	var gqlErrors []GraphQLError
+	if req.Metadata["transport"] == constants.TransportGraphQL && bodyBytes != nil {
+		gqlErrors = graphqlErrors(bodyBytes, req.Metadata["graphql_batch"] != "")
+	}
+
+	// Expose binary bodies as hex/base64 strings before filtering and assertions
+	if encoding := req.Metadata["response_encoding"]; encoding != "" && bodyBytes != nil {
+		body, err = encodeBody(bodyBytes, encoding, resp.Headers["Content-Type"])
+		if err != nil {
+			return nil, err
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]string{}
+		}
+		resp.Headers["Content-Type"] = "application/json"
+	}
+
 	// Apply JQ filter if specified
 	if jqFilter, ok := req.Metadata["jq_filter"]; ok && jqFilter != "" {
 		body, err = filter.ApplyJQ(body, jqFilter)
@@ -77,36 +174,116 @@ func Run(ctx context.Context, exec executor.TransportFunc, req *domain.Request,
 		resp.Headers["Content-Type"] = "application/json"
 	}
 
-	// Write to output file if specified
-	if outputFile, ok := req.Metadata["output_file"]; ok && outputFile != "" {
+	// Write filtered output to file if it wasn't streamed
+	if outputFile != "" && !streamOutput {
 		if err := os.WriteFile(outputFile, []byte(body), 0600); err != nil {
 			return nil, fmt.Errorf("failed to write output file '%s': %w", outputFile, err)
 		}
 	}
 
+	if checksum := req.Metadata["output_sha256"]; checksum != "" && outputFile != "" {
+		if err := verifySHA256(outputFile, checksum); err != nil {
+			return nil, err
+		}
+	}
+
 	bodyLines := strings.Count(body, "\n") + 1
 	bodyChars := len(body)
-	bodyBytesLen := len(bodyBytes)
 
 	return &Result{
-		Body:        body,
-		ContentType: resp.Headers["Content-Type"],
-		StatusCode:  resp.StatusCode,
-		Warnings:    warnings,
-		RequestURL:  req.URL,
-		Duration:    resp.Duration,
-		BodyLines:   bodyLines,
-		BodyChars:   bodyChars,
-		BodyBytes:   bodyBytesLen,
-		Headers:     resp.Headers,
+		Body:          body,
+		ContentType:   resp.Headers["Content-Type"],
+		StatusCode:    resp.StatusCode,
+		Warnings:      warnings,
+		RequestURL:    req.URL,
+		Duration:      resp.Duration,
+		BodyLines:     bodyLines,
+		BodyChars:     bodyChars,
+		BodyBytes:     bodySize,
+		Headers:       resp.Headers,
+		OutputFile:    outputFile,
+		Cache:         cacheInfo,
+		TLS:           resp.TLS,
+		GraphQLErrors: gqlErrors,
 	}, nil
 }
 
+// applyProjectCredentials fills in AWS credentials from project vars when they
+// are not set explicitly in the config or in the OS environment.
+func applyProjectCredentials(req *domain.Request, envOverrides map[string]string) {
+	if req.Metadata["auth_type"] != constants.AuthAWSSigV4 || len(envOverrides) == 0 {
+		return
+	}
+	if req.Metadata["aws_access_key_id"] != "" || os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		return
+	}
+	if envOverrides["AWS_ACCESS_KEY_ID"] == "" || envOverrides["AWS_SECRET_ACCESS_KEY"] == "" {
+		return
+	}
+	req.Metadata["aws_access_key_id"] = envOverrides["AWS_ACCESS_KEY_ID"]
+	req.Metadata["aws_secret_access_key"] = envOverrides["AWS_SECRET_ACCESS_KEY"]
+	req.Metadata["aws_session_token"] = envOverrides["AWS_SESSION_TOKEN"]
+}
+
+// configPathKeys are the metadata entries that name files on disk.
+var configPathKeys = []string{"tls_ca_cert", "tls_client_cert", "tls_client_key"}
+
+// resolveConfigPaths makes relative file paths in the request relative to the
+// directory of the config file instead of the working directory.
+func resolveConfigPaths(req *domain.Request, configPath string) {
+	if configPath == "" {
+		return
+	}
+	dir := filepath.Dir(configPath)
+	for _, key := range configPathKeys {
+		if path := req.Metadata[key]; path != "" && !filepath.IsAbs(path) {
+			req.Metadata[key] = filepath.Join(dir, path)
+		}
+	}
+
+	// GraphQL uploads are a JSON object of variable paths to files
+	var uploads map[string]string
+	if err := json.Unmarshal([]byte(req.Metadata["graphql_uploads"]), &uploads); err != nil || len(uploads) == 0 {
+		return
+	}
+	for name, path := range uploads {
+		if !filepath.IsAbs(path) {
+			uploads[name] = filepath.Join(dir, path)
+		}
+	}
+	if data, err := json.Marshal(uploads); err == nil {
+		req.Metadata["graphql_uploads"] = string(data)
+	}
+}
+
 // ChainResult holds the output of a chain execution
 type ChainResult struct {
 	Results            []*Result            // Results from each step
 	StepNames          []string             // Names of each step
 	ExpectationResults []*ExpectationResult // Expectation results from each step
+	Skipped            []string             // Steps skipped by their when/skip_if condition
+	Outputs            map[string]any       // Values named by chain_outputs
+	Steps              []StepReport         // How every chain and finally step ended, in order
+}
+
+// StepStatus is how a chain step ended.
+type StepStatus string
+
+// Step statuses reported in ChainResult.Steps
+const (
+	StepPassed    StepStatus = "passed"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+	StepNotRun    StepStatus = "not run"   // Not started because an earlier step failed
+	StepCancelled StepStatus = "cancelled" // Interrupted, or not started, because the run was cancelled or timed out
+)
+
+// StepReport records how one chain step ended.
+type StepReport struct {
+	Name    string
+	Status  StepStatus
+	Reason  string // Why the step failed or was skipped
+	Finally bool   // The step is from the finally section
 }
 
 // ExecutorFactory is an interface for creating transport functions
@@ -114,76 +291,187 @@ type ExecutorFactory interface {
 	Create(transport string) (executor.TransportFunc, error)
 }
 
-// RunChain executes a sequence of steps, merging each step with the base config
+// RunChain executes a sequence of steps, merging each step with the base config.
+// The base config's finally steps then run in order, whether or not the chain failed.
 func RunChain(ctx context.Context, factory ExecutorFactory, base *config.ConfigV1, steps []config.ChainStep, opts Options) (*ChainResult, error) {
-	chainCtx := NewChainContext(opts.EnvOverrides)
-	chainResult := &ChainResult{
-		Results:            make([]*Result, 0, len(steps)),
-		StepNames:          make([]string, 0, len(steps)),
-		ExpectationResults: make([]*ExpectationResult, 0, len(steps)),
+	var params map[string]any
+	if len(opts.Vars) > 0 {
+		params = make(map[string]any, len(opts.Vars))
+		for k, v := range opts.Vars {
+			params[k] = v
+		}
 	}
+	return runChain(ctx, factory, base, steps, opts, params)
+}
 
-	for i, step := range steps {
-		fmt.Fprintf(os.Stderr, "Running step %d: %s...\n", i+1, step.Name)
+// runChain runs a chain whose steps see params as variables, ahead of the environment.
+func runChain(ctx context.Context, factory ExecutorFactory, base *config.ConfigV1, steps []config.ChainStep, opts Options, params map[string]any) (*ChainResult, error) {
+	chainCtx := NewChainContext(opts.EnvOverrides)
+	chainCtx.locals = params
 
-		// 1. Merge step with base config to get full config
-		merged := base.Merge(step)
+	deps, err := chainDependencies(base, steps)
+	if err != nil {
+		return nil, err
+	}
 
-		// 2. Interpolate variables in the merged config
-		interpolatedConfig, err := interpolateConfig(chainCtx, &merged)
+	runCtx := ctx
+	if base.ChainTimeout != "" {
+		d, err := time.ParseDuration(base.ChainTimeout)
 		if err != nil {
-			return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+			return nil, fmt.Errorf("invalid chain_timeout '%s': %w", base.ChainTimeout, err)
 		}
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, d, fmt.Errorf("chain_timeout of %s exceeded", d))
+		defer cancel()
+	}
 
-		// 3. Handle Delay (wait before executing step)
-		if interpolatedConfig.Delay != "" {
-			d, err := time.ParseDuration(interpolatedConfig.Delay)
-			if err != nil {
-				return nil, fmt.Errorf("step '%s' invalid delay '%s': %w", step.Name, interpolatedConfig.Delay, err)
-			}
-			if d > 0 {
-				fmt.Fprintf(os.Stderr, "[INFO] Delaying for %s...\n", d)
-				select {
-				case <-time.After(d):
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				}
+	outcomes := scheduleSteps(len(steps), deps, base.Parallel, func(i int) stepOutcome {
+		return runChainStep(runCtx, factory, base, i, steps[i], chainCtx, opts)
+	})
+	if runCtx.Err() != nil {
+		for i, outcome := range outcomes {
+			if outcome == nil {
+				outcomes[i] = &stepOutcome{cancelled: true}
 			}
 		}
+	}
 
-		// 4. Convert to domain request (handles ALL transports: HTTP, TCP, gRPC, GraphQL)
-		req, err := interpolatedConfig.ToDomain()
-		if err != nil {
-			return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+	// Teardown runs whatever happened, even after cancellation, and a failing finally
+	// step does not stop the rest
+	teardownCtx := context.WithoutCancel(ctx)
+	if len(base.Finally) > 0 && runCtx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Running finally steps after cancellation (%v)...\n", context.Cause(runCtx))
+	}
+	finals := make([]*stepOutcome, len(base.Finally))
+	for k, step := range base.Finally {
+		outcome := runChainStep(teardownCtx, factory, base, len(steps)+k, step, chainCtx, opts)
+		finals[k] = &outcome
+	}
+
+	// Report steps in chain order, whatever order they finished in
+	chainResult := &ChainResult{
+		Results:            make([]*Result, 0, len(steps)),
+		StepNames:          make([]string, 0, len(steps)),
+		ExpectationResults: make([]*ExpectationResult, 0, len(steps)),
+	}
+	var firstErr error
+	for i, outcome := range outcomes {
+		if err := chainResult.addStep(steps[i], outcome, false); err != nil && firstErr == nil {
+			firstErr = err
 		}
+	}
+	for k, outcome := range finals {
+		if err := chainResult.addStep(base.Finally[k], outcome, true); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return chainResult, firstErr
+	}
 
-		// 5. Create executor for this step's transport
-		exec, err := factory.Create(req.Metadata["transport"])
+	if len(base.ChainOutputs) > 0 {
+		outputs, err := chainCtx.Outputs(base.ChainOutputs)
 		if err != nil {
-			return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+			return chainResult, fmt.Errorf("chain_outputs: %w", err)
 		}
+		chainResult.Outputs = outputs
+	}
+	return chainResult, nil
+}
+
+// runStep runs a single chain step. The result is returned, with its expectation
+// result, even when an assertion fails.
+func runStep(ctx context.Context, factory ExecutorFactory, base *config.ConfigV1, step config.ChainStep, chainCtx *ChainContext, opts Options) (*Result, *ExpectationResult, error) {
+	// 1. Merge step with base config to get full config
+	merged := base.Merge(step)
+
+	// 2. Interpolate variables in the merged config
+	interpolatedConfig, err := interpolateConfig(chainCtx, &merged)
+	if err != nil {
+		return nil, nil, fmt.Errorf("step '%s': %w", step.Name, err)
+	}
 
-		// 6. Execute
-		result, err := Run(ctx, exec, req, []string{}, opts)
+	// 3. Handle Delay (wait before executing step)
+	if interpolatedConfig.Delay != "" {
+		d, err := time.ParseDuration(interpolatedConfig.Delay)
 		if err != nil {
-			return nil, fmt.Errorf("step '%s' failed: %w", step.Name, err)
+			return nil, nil, fmt.Errorf("step '%s' invalid delay '%s': %w", step.Name, interpolatedConfig.Delay, err)
+		}
+		if d > 0 {
+			fmt.Fprintf(os.Stderr, "[INFO] Delaying for %s...\n", d)
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
 		}
+	}
 
-		// 7. Assert Expectations
-		expectRes := CheckExpectationsWithEnv(step.Expect, result, opts.EnvOverrides)
+	// 4. Convert to domain request (handles ALL transports: HTTP, TCP, gRPC, GraphQL)
+	req, err := interpolatedConfig.ToDomain()
+	if err != nil {
+		return nil, nil, fmt.Errorf("step '%s': %w", step.Name, err)
+	}
 
-		// 8. Store Result (including expectation result even if failed)
-		chainCtx.AddResult(step.Name, result)
-		chainResult.Results = append(chainResult.Results, result)
-		chainResult.StepNames = append(chainResult.StepNames, step.Name)
-		chainResult.ExpectationResults = append(chainResult.ExpectationResults, expectRes)
+	// 5. Create executor for this step's transport
+	exec, err := factory.Create(req.Metadata["transport"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("step '%s': %w", step.Name, err)
+	}
 
-		if expectRes.Error != nil {
-			return chainResult, fmt.Errorf("step '%s' assertion failed: %w", step.Name, expectRes.Error)
+	// 6. Execute, polling until the wait_until condition holds
+	var result *Result
+	if step.WaitUntil != nil {
+		send := func() (*Result, error) {
+			// The request body is consumed by each attempt, so rebuild it
+			if req == nil {
+				if req, err = interpolatedConfig.ToDomain(); err != nil {
+					return nil, fmt.Errorf("step '%s': %w", step.Name, err)
+				}
+			}
+			res, err := Run(ctx, exec, req, []string{}, opts)
+			req = nil
+			if err != nil {
+				return nil, fmt.Errorf("step '%s' failed: %w", step.Name, err)
+			}
+			return res, nil
+		}
+		result, err = pollUntil(ctx, step.Name, step.WaitUntil, opts.EnvOverrides, send)
+		if err != nil {
+			return result, nil, err
+		}
+	} else {
+		result, err = Run(ctx, exec, req, []string{}, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("step '%s' failed: %w", step.Name, err)
 		}
 	}
 
-	return chainResult, nil
+	// 7. Assert Expectations
+	expect, err := expandExpectStatus(chainCtx, step.Expect)
+	if err != nil {
+		return result, nil, fmt.Errorf("step '%s' expect: %w", step.Name, err)
+	}
+	expectRes := CheckExpectationsWithEnv(expect, result, opts.EnvOverrides)
+	if expectRes.Error != nil {
+		return result, expectRes, fmt.Errorf("step '%s' assertion failed: %w", step.Name, expectRes.Error)
+	}
+	return result, expectRes, nil
+}
+
+// expandExpectStatus expands a status given as a variable reference, such as
+// ${status} from a matrix row.
+func expandExpectStatus(chainCtx *ChainContext, expect config.Expectation) (config.Expectation, error) {
+	s, ok := expect.Status.(string)
+	if !ok {
+		return expect, nil
+	}
+	expanded, err := chainCtx.ExpandVariables(s)
+	if err != nil {
+		return expect, err
+	}
+	expect.Status = expanded
+	return expect, nil
 }
 
 // interpolateConfig expands chain variables in a config
@@ -252,6 +540,32 @@ func interpolateConfig(chainCtx *ChainContext, cfg *config.ConfigV1) (*config.Co
 		result.Data = expanded
 	}
 
+	// Interpolate Conversation (TCP)
+	if len(result.Conversation) > 0 {
+		steps := make([]config.ConversationStep, len(result.Conversation))
+		for i, step := range result.Conversation {
+			expanded, err := chainCtx.ExpandVariables(step.Send)
+			if err != nil {
+				return nil, fmt.Errorf("conversation[%d].send: %w", i, err)
+			}
+			step.Send = expanded
+			if step.ExpectLiteral != "" {
+				if expanded, err = chainCtx.ExpandVariables(step.ExpectLiteral); err != nil {
+					return nil, fmt.Errorf("conversation[%d].expect_literal: %w", i, err)
+				}
+				step.ExpectLiteral = expanded
+			}
+			if step.ExpectRegex != "" {
+				if expanded, err = chainCtx.ExpandRegex(step.ExpectRegex); err != nil {
+					return nil, fmt.Errorf("conversation[%d].expect_regex: %w", i, err)
+				}
+				step.ExpectRegex = expanded
+			}
+			steps[i] = step
+		}
+		result.Conversation = steps
+	}
+
 	// Interpolate Body
 	if result.Body != nil {
 		newBody, err := interpolateBody(chainCtx, result.Body)
@@ -270,6 +584,20 @@ func interpolateConfig(chainCtx *ChainContext, cfg *config.ConfigV1) (*config.Co
 		result.Variables = newVars
 	}
 
+	// Interpolate batch variables (GraphQL); the slice is shared with the step config
+	if len(result.Batch) > 0 {
+		ops := make([]config.GraphQLOperation, len(result.Batch))
+		for i, op := range result.Batch {
+			newVars, err := interpolateBody(chainCtx, op.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("batch[%d].variables: %w", i, err)
+			}
+			op.Variables = newVars
+			ops[i] = op
+		}
+		result.Batch = ops
+	}
+
 	// Interpolate Delay
 	if result.Delay != "" {
 		expanded, err := chainCtx.ExpandVariables(result.Delay)
@@ -288,6 +616,45 @@ func interpolateConfig(chainCtx *ChainContext, cfg *config.ConfigV1) (*config.Co
 		result.OutputFile = expanded
 	}
 
+	// Interpolate OutputSHA256 (e.g. a digest published by a previous step)
+	if result.OutputSHA256 != "" {
+		expanded, err := chainCtx.ExpandVariables(result.OutputSHA256)
+		if err != nil {
+			return nil, fmt.Errorf("output_sha256: %w", err)
+		}
+		result.OutputSHA256 = expanded
+	}
+
+	// Interpolate Auth credentials (e.g. a session token from a previous step)
+	if result.Auth.AccessKeyID != "" {
+		expanded, err := chainCtx.ExpandVariables(result.Auth.AccessKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("auth.access_key_id: %w", err)
+		}
+		result.Auth.AccessKeyID = expanded
+	}
+	if result.Auth.SecretAccessKey != "" {
+		expanded, err := chainCtx.ExpandVariables(result.Auth.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth.secret_access_key: %w", err)
+		}
+		result.Auth.SecretAccessKey = expanded
+	}
+	if result.Auth.SessionToken != "" {
+		expanded, err := chainCtx.ExpandVariables(result.Auth.SessionToken)
+		if err != nil {
+			return nil, fmt.Errorf("auth.session_token: %w", err)
+		}
+		result.Auth.SessionToken = expanded
+	}
+	if result.Auth.Secret != "" {
+		expanded, err := chainCtx.ExpandVariables(result.Auth.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("auth.secret: %w", err)
+		}
+		result.Auth.Secret = expanded
+	}
+
 	return &result, nil
 }
 
@@ -391,6 +758,8 @@ func formatAssertionError(detail *filter.AssertionDetail) string {
 type ExpectationResult struct {
 	StatusPassed     bool
 	StatusChecked    bool
+	GraphQLPassed    bool // The response had no GraphQL errors, or they were allowed
+	GraphQLChecked   bool
 	AssertionsPassed int
 	AssertionsTotal  int
 	AssertionResults []AssertionResult
@@ -402,9 +771,52 @@ func (e *ExpectationResult) AllPassed() bool {
 	return e.Error == nil
 }
 
+// runAssertions evaluates jq assertions against input and records their results.
+// label prefixes failure messages (e.g. "header"); it is empty for body assertions.
+// Returns false and sets e.Error at the first failure.
+func (e *ExpectationResult) runAssertions(label, input string, assertions []string, jqVars map[string]any) bool {
+	prefix := ""
+	if label != "" {
+		prefix = label + " "
+	}
+	for _, assertion := range assertions {
+		// Convert env.VARNAME syntax to $env.VARNAME for jq compatibility
+		processedAssertion := strings.ReplaceAll(assertion, "env.", "$env.")
+
+		var passed bool
+		var detail *filter.AssertionDetail
+		var err error
+
+		if jqVars != nil {
+			passed, detail, err = filter.EvalJQBoolWithDetailAndVars(input, processedAssertion, jqVars)
+		} else {
+			passed, detail, err = filter.EvalJQBoolWithDetail(input, processedAssertion)
+		}
+
+		ar := AssertionResult{
+			Expression: assertion,
+			Passed:     passed && err == nil,
+			Error:      err,
+		}
+		e.AssertionResults = append(e.AssertionResults, ar)
+
+		if err != nil {
+			e.Error = fmt.Errorf("%sassertion failed: %w", prefix, err)
+			return false
+		}
+		if !passed {
+			// Generate detailed error message based on what we know about the assertion
+			e.Error = fmt.Errorf("%s%s", prefix, formatAssertionError(detail))
+			return false
+		}
+		e.AssertionsPassed++
+	}
+	return true
+}
+
 // CheckExpectationsWithEnv validates the response against expected values with environment variables
 func CheckExpectationsWithEnv(expect config.Expectation, result *Result, envVars map[string]string) *ExpectationResult {
-	totalAssertions := len(expect.Assert.Body) + len(expect.Assert.Headers)
+	totalAssertions := len(expect.Assert.Body) + len(expect.Assert.Headers) + len(expect.Assert.Cache) + len(expect.Assert.TLS)
 	res := &ExpectationResult{
 		AssertionsTotal:  totalAssertions,
 		AssertionResults: make([]AssertionResult, 0, totalAssertions),
@@ -423,6 +835,10 @@ func CheckExpectationsWithEnv(expect config.Expectation, result *Result, envVars
 			if result.StatusCode == int(v) {
 				matched = true
 			}
+		case string: // A variable reference, expanded before the check
+			if code, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && code == result.StatusCode {
+				matched = true
+			}
 		case []any: // YAML often parses arrays as []any
 			for _, code := range v {
 				switch c := code.(type) {
@@ -444,6 +860,16 @@ func CheckExpectationsWithEnv(expect config.Expectation, result *Result, envVars
 		}
 	}
 
+	// GraphQL errors fail the request unless explicitly allowed
+	if len(result.GraphQLErrors) > 0 {
+		res.GraphQLChecked = true
+		res.GraphQLPassed = expect.AllowErrors
+		if !expect.AllowErrors {
+			res.Error = fmt.Errorf("GraphQL response contains %d error(s): %s", len(result.GraphQLErrors), result.GraphQLErrors[0])
+			return res
+		}
+	}
+
 	// Prepare environment variables for jq
 	var jqVars map[string]any
 	if len(envVars) > 0 {
@@ -457,81 +883,44 @@ func CheckExpectationsWithEnv(expect config.Expectation, result *Result, envVars
 	}
 
 	// Body Assertions - run against response body
-	for _, assertion := range expect.Assert.Body {
-		// Convert env.VARNAME syntax to $env.VARNAME for jq compatibility
-		processedAssertion := strings.ReplaceAll(assertion, "env.", "$env.")
-
-		var passed bool
-		var detail *filter.AssertionDetail
-		var err error
+	if !res.runAssertions("", result.Body, expect.Assert.Body, jqVars) {
+		return res
+	}
 
-		if jqVars != nil {
-			passed, detail, err = filter.EvalJQBoolWithDetailAndVars(result.Body, processedAssertion, jqVars)
-		} else {
-			passed, detail, err = filter.EvalJQBoolWithDetail(result.Body, processedAssertion)
+	// Header Assertions - run against headers as JSON
+	if len(expect.Assert.Headers) > 0 {
+		// Convert headers to JSON for JQ processing
+		headersJSON, err := json.Marshal(result.Headers)
+		if err != nil {
+			res.Error = fmt.Errorf("failed to marshal headers for assertions: %w", err)
+			return res
 		}
-
-		ar := AssertionResult{
-			Expression: assertion,
-			Passed:     passed && err == nil,
-			Error:      err,
+		if !res.runAssertions("header", string(headersJSON), expect.Assert.Headers, jqVars) {
+			return res
 		}
-		res.AssertionResults = append(res.AssertionResults, ar)
+	}
 
+	// Cache Assertions - run against the cache outcome and parsed Cache-Control
+	if len(expect.Assert.Cache) > 0 {
+		cacheJSON, err := cacheAssertionInput(result)
 		if err != nil {
-			res.Error = fmt.Errorf("assertion failed: %w", err)
+			res.Error = fmt.Errorf("failed to marshal cache info for assertions: %w", err)
 			return res
 		}
-		if !passed {
-			// Generate detailed error message based on what we know about the assertion
-			errorMsg := formatAssertionError(detail)
-			res.Error = fmt.Errorf("%s", errorMsg)
+		if !res.runAssertions("cache", string(cacheJSON), expect.Assert.Cache, jqVars) {
 			return res
 		}
-		res.AssertionsPassed++
 	}
 
-	// Header Assertions - run against headers as JSON
-	if len(expect.Assert.Headers) > 0 {
-		// Convert headers to JSON for JQ processing
-		headersJSON, err := json.Marshal(result.Headers)
+	// TLS Assertions - run against the negotiated session (null for plaintext connections)
+	if len(expect.Assert.TLS) > 0 {
+		tlsJSON, err := json.Marshal(result.TLS)
 		if err != nil {
-			res.Error = fmt.Errorf("failed to marshal headers for assertions: %w", err)
+			res.Error = fmt.Errorf("failed to marshal TLS info for assertions: %w", err)
 			return res
 		}
-
-		for _, assertion := range expect.Assert.Headers {
-			// Convert env.VARNAME syntax to $env.VARNAME for jq compatibility
-			processedAssertion := strings.ReplaceAll(assertion, "env.", "$env.")
-
-			var passed bool
-			var detail *filter.AssertionDetail
-			var err error
-
-			if jqVars != nil {
-				passed, detail, err = filter.EvalJQBoolWithDetailAndVars(string(headersJSON), processedAssertion, jqVars)
-			} else {
-				passed, detail, err = filter.EvalJQBoolWithDetail(string(headersJSON), processedAssertion)
-			}
-
-			ar := AssertionResult{
-				Expression: assertion,
-				Passed:     passed && err == nil,
-				Error:      err,
-			}
-			res.AssertionResults = append(res.AssertionResults, ar)
-
-			if err != nil {
-				res.Error = fmt.Errorf("header assertion failed: %w", err)
-				return res
-			}
-			if !passed {
-				// Generate detailed error message based on what we know about the assertion
-				errorMsg := formatAssertionError(detail)
-				res.Error = fmt.Errorf("header %s", errorMsg)
-				return res
-			}
-			res.AssertionsPassed++
+		if !res.runAssertions("tls", string(tlsJSON), expect.Assert.TLS, jqVars) {
+			return res
 		}
 	}
 
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/filter"
+)
+
+// Attempt is one request of a wait_until step.
+type Attempt struct {
+	StatusCode int
+	Duration   time.Duration
+	Met        bool  // The condition held for this response
+	Error      error // The request failed, or the condition could not be evaluated (e.g. the body was not JSON yet)
+}
+
+// pollUntil sends the step's request until the wait_until condition holds for the
+// response, waiting between attempts. Failed requests are retried like unmet conditions.
+// The last response is returned, also when the timeout is reached, with every attempt
+// recorded on it.
+func pollUntil(ctx context.Context, name string, wait *config.WaitUntil, envVars map[string]string, send func() (*Result, error)) (*Result, error) {
+	interval, maxInterval, timeout, err := wait.Durations()
+	if err != nil {
+		return nil, fmt.Errorf("step '%s' wait_until: %w", name, err)
+	}
+	backoff := wait.Backoff
+	if backoff < 1 {
+		backoff = 1
+	}
+	deadline := time.Now().Add(timeout)
+
+	var attempts []Attempt
+	var last *Result
+	for {
+		// A request that fails outright, e.g. while the service is still starting,
+		// is an attempt that is not done yet
+		result, sendErr := send()
+		if sendErr != nil {
+			attempts = append(attempts, Attempt{Error: sendErr})
+			err = sendErr
+		} else {
+			met, condErr := evalWaitCondition(wait.Condition, result, envVars)
+			attempts = append(attempts, Attempt{
+				StatusCode: result.StatusCode,
+				Duration:   result.Duration,
+				Met:        met,
+				Error:      condErr,
+			})
+			if met {
+				result.Attempts = attempts
+				return result, nil
+			}
+			last, err = result, condErr
+		}
+		if last != nil {
+			last.Attempts = attempts
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			msg := fmt.Sprintf("step '%s' wait_until: condition not met after %d attempts (timeout %s)", name, len(attempts), timeout)
+			if err != nil {
+				return last, fmt.Errorf("%s: %w", msg, err)
+			}
+			return last, errors.New(msg)
+		}
+		if sendErr != nil {
+			fmt.Fprintf(os.Stderr, "[INFO] %s: attempt %d failed (%v), retrying in %s...\n", name, len(attempts), sendErr, interval)
+		} else {
+			fmt.Fprintf(os.Stderr, "[INFO] %s: attempt %d not done (status %d), retrying in %s...\n", name, len(attempts), result.StatusCode, interval)
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return last, ctx.Err()
+		}
+
+		interval = time.Duration(float64(interval) * backoff)
+		if maxInterval > 0 && interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// evalWaitCondition evaluates a wait_until condition against the response body, with
+// $status set to the response status and env.NAME reading the environment overrides,
+// like in assertions.
+func evalWaitCondition(condition string, result *Result, envVars map[string]string) (bool, error) {
+	env := make(map[string]any, len(envVars))
+	for k, v := range envVars {
+		env[k] = v
+	}
+	expr := strings.ReplaceAll(condition, "env.", "$env.")
+	ok, _, err := filter.EvalJQBoolWithDetailAndVars(result.Body, expr, map[string]any{
+		"env":    env,
+		"status": result.StatusCode,
+	})
+	return ok, err
+}
@@ -3,11 +3,16 @@ package validation
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/itchyny/gojq"
 	"gopkg.in/yaml.v3"
 	"yapi.run/cli/internal/config"
 	"yapi.run/cli/internal/domain"
@@ -110,6 +115,12 @@ func AnalyzeConfigString(text string) (*Analysis, error) {
 // If project is provided, performs cross-environment variable validation, uses project
 // variables from the default environment for resolution, and applies environment defaults.
 func AnalyzeConfigStringWithProject(text string, project *config.ProjectConfigV1, projectRoot string) (*Analysis, error) {
+	return AnalyzeConfigStringInDir(text, project, projectRoot, "")
+}
+
+// AnalyzeConfigStringInDir is AnalyzeConfigStringWithProject for a config file in dir.
+// Relative paths in the config, such as a GraphQL `schema` file, are resolved against dir.
+func AnalyzeConfigStringInDir(text string, project *config.ProjectConfigV1, projectRoot, dir string) (*Analysis, error) {
 	var parseRes *config.ParseResult
 	var err error
 
@@ -158,7 +169,7 @@ func AnalyzeConfigStringWithProject(text string, project *config.ProjectConfigV1
 		}
 		return &Analysis{Diagnostics: []Diagnostic{diag}}, nil
 	}
-	return analyzeParsed(text, parseRes, project, projectRoot), nil
+	return analyzeParsed(text, parseRes, project, projectRoot, dir), nil
 }
 
 // AnalyzeConfigFile loads a file and analyzes it.
@@ -188,22 +199,44 @@ func AnalyzeConfigFile(path string) (*Analysis, error) {
 		return &Analysis{Diagnostics: []Diagnostic{diag}}, nil
 	}
 
-	return analyzeParsed(string(data), parseRes, nil, ""), nil
+	dir := ""
+	if path != "-" {
+		dir = filepath.Dir(path)
+	}
+	return analyzeParsed(string(data), parseRes, nil, "", dir), nil
 }
 
 // analyzeParsed is the common analysis path for both string and file inputs.
-func analyzeParsed(text string, parseRes *config.ParseResult, project *config.ProjectConfigV1, projectRoot string) *Analysis {
+func analyzeParsed(text string, parseRes *config.ParseResult, project *config.ProjectConfigV1, projectRoot, dir string) *Analysis {
 	var diags []Diagnostic
 
 	// Chain config
 	if len(parseRes.Chain) > 0 {
-		diags = append(diags, validateChain(text, parseRes.Base, parseRes.Chain)...)
+		// Finally steps run after the chain and share its step names
+		steps := parseRes.Chain
+		if parseRes.Base != nil {
+			steps = append(slices.Clone(steps), parseRes.Base.Finally...)
+		}
+		diags = append(diags, validateChain(text, parseRes.Base, steps)...)
+		diags = append(diags, validateCalls(text, dir, steps)...)
+		diags = append(diags, validateMatrix(text, parseRes.Base, dir)...)
+		if parseRes.Base != nil && parseRes.Base.ChainTimeout != "" {
+			if d, err := time.ParseDuration(parseRes.Base.ChainTimeout); err != nil || d <= 0 {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityError,
+					Field:    "chain_timeout",
+					Message:  fmt.Sprintf("invalid `chain_timeout` '%s': expected a positive duration like 30s or 2m", parseRes.Base.ChainTimeout),
+					Line:     findFieldLine(text, "chain_timeout"),
+					Col:      0,
+				})
+			}
+		}
 
 		// Use project-aware validation if available
 		if project != nil {
-			diags = append(diags, ValidateProjectVars(text, project, projectRoot)...)
+			diags = append(diags, validateProjectVars(text, project, projectRoot, dir)...)
 		} else {
-			diags = append(diags, validateEnvVars(text)...)
+			diags = append(diags, validateEnvVars(text, dir)...)
 		}
 
 		return &Analysis{
@@ -217,6 +250,35 @@ func analyzeParsed(text string, parseRes *config.ParseResult, project *config.Pr
 	// Single request config
 	req := parseRes.Request
 
+	if parseRes.Base != nil && len(parseRes.Base.ChainOutputs) > 0 {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Field:    "chain_outputs",
+			Message:  "`chain_outputs` has no effect without `chain`",
+			Line:     findFieldLine(text, "chain_outputs"),
+			Col:      0,
+		})
+	}
+	if parseRes.Base != nil && len(parseRes.Base.Finally) > 0 {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Field:    "finally",
+			Message:  "`finally` has no effect without `chain`",
+			Line:     findFieldLine(text, "finally"),
+			Col:      0,
+		})
+	}
+
+	if parseRes.Base != nil && parseRes.Base.ChainTimeout != "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Field:    "chain_timeout",
+			Message:  "`chain_timeout` has no effect without `chain`; use `timeout` for a single request",
+			Line:     findFieldLine(text, "chain_timeout"),
+			Col:      0,
+		})
+	}
+
 	for _, iss := range ValidateRequest(req) {
 		diags = append(diags, Diagnostic{
 			Severity: iss.Severity,
@@ -228,14 +290,16 @@ func analyzeParsed(text string, parseRes *config.ParseResult, project *config.Pr
 	}
 
 	diags = append(diags, ValidateGraphQLSyntax(text, req)...)
+	diags = append(diags, ValidateGraphQLSchema(text, req, dir)...)
 	diags = append(diags, ValidateJQSyntax(text, req)...)
 	diags = append(diags, validateUnknownKeys(text)...)
+	diags = append(diags, validateMatrix(text, parseRes.Base, dir)...)
 
 	// Use project-aware validation if available
 	if project != nil {
-		diags = append(diags, ValidateProjectVars(text, project, projectRoot)...)
+		diags = append(diags, validateProjectVars(text, project, projectRoot, dir)...)
 	} else {
-		diags = append(diags, validateEnvVars(text)...)
+		diags = append(diags, validateEnvVars(text, dir)...)
 	}
 
 	if len(parseRes.Expect.Assert.Body) > 0 {
@@ -244,6 +308,12 @@ func analyzeParsed(text string, parseRes *config.ParseResult, project *config.Pr
 	if len(parseRes.Expect.Assert.Headers) > 0 {
 		diags = append(diags, ValidateChainAssertions(text, parseRes.Expect.Assert.Headers, "")...)
 	}
+	if len(parseRes.Expect.Assert.Cache) > 0 {
+		diags = append(diags, ValidateChainAssertions(text, parseRes.Expect.Assert.Cache, "")...)
+	}
+	if len(parseRes.Expect.Assert.TLS) > 0 {
+		diags = append(diags, ValidateChainAssertions(text, parseRes.Expect.Assert.TLS, "")...)
+	}
 
 	return &Analysis{
 		Request:     req,
@@ -325,9 +395,29 @@ func validateChain(text string, base *config.ConfigV1, chain []config.ChainStep)
 	var diags []Diagnostic
 	definedSteps := make(map[string]bool)
 
+	if base != nil && base.Parallel < 0 {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Field:    "parallel",
+			Message:  "`parallel` must not be negative",
+			Line:     findFieldLine(text, "parallel"),
+			Col:      0,
+		})
+	}
+
 	for i, step := range chain {
 		stepLine := findChainStepLine(text, step.Name)
 
+		// A foreach step may also reference its current item
+		scope := definedSteps
+		if step.Foreach != nil {
+			scope = make(map[string]bool, len(definedSteps)+1)
+			for name := range definedSteps {
+				scope[name] = true
+			}
+			scope["item"] = true
+		}
+
 		// 1. Check name is present
 		if step.Name == "" {
 			diags = append(diags, Diagnostic{
@@ -348,7 +438,7 @@ func validateChain(text string, base *config.ConfigV1, chain []config.ChainStep)
 
 		// 2. Check URL is present (either in step or in base config)
 		hasURL := step.URL != "" || (base != nil && base.URL != "")
-		if !hasURL {
+		if !hasURL && step.Call == "" {
 			diags = append(diags, Diagnostic{
 				Severity: SeverityError,
 				Field:    step.Name,
@@ -359,25 +449,181 @@ func validateChain(text string, base *config.ConfigV1, chain []config.ChainStep)
 		}
 
 		// 3. Check for references to future steps
-		diags = append(diags, scanForUndefinedRefs(text, step.URL, definedSteps, step.Name, "url")...)
+		diags = append(diags, scanForUndefinedRefs(text, step.URL, scope, step.Name, "url")...)
 
 		// Check Headers
 		for _, v := range step.Headers {
-			diags = append(diags, scanForUndefinedRefs(text, v, definedSteps, step.Name, "headers")...)
+			diags = append(diags, scanForUndefinedRefs(text, v, scope, step.Name, "headers")...)
 		}
 
 		// Check Body values recursively (handles nested maps like body.params.track_index)
-		diags = append(diags, scanBodyForUndefinedRefs(text, step.Body, definedSteps, step.Name, "body")...)
+		diags = append(diags, scanBodyForUndefinedRefs(text, step.Body, scope, step.Name, "body")...)
 
 		// Check JSON field
 		if step.JSON != "" {
-			diags = append(diags, scanForUndefinedRefs(text, step.JSON, definedSteps, step.Name, "json")...)
+			diags = append(diags, scanForUndefinedRefs(text, step.JSON, scope, step.Name, "json")...)
 		}
 
 		// Check Variables
 		for k, v := range step.Variables {
 			if s, ok := v.(string); ok {
-				diags = append(diags, scanForUndefinedRefs(text, s, definedSteps, step.Name, fmt.Sprintf("variables.%s", k))...)
+				diags = append(diags, scanForUndefinedRefs(text, s, scope, step.Name, fmt.Sprintf("variables.%s", k))...)
+			}
+		}
+
+		// Check call parameters, and that a call step does nothing a request step would
+		for _, k := range slices.Sorted(maps.Keys(step.With)) {
+			diags = append(diags, scanForUndefinedRefs(text, step.With[k], definedSteps, step.Name, "with."+k)...)
+		}
+		if step.Call == "" && len(step.With) > 0 {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityWarning,
+				Field:    fmt.Sprintf("%s.with", step.Name),
+				Message:  fmt.Sprintf("`with` on step '%s' has no effect without `call`", step.Name),
+				Line:     stepLine,
+				Col:      0,
+			})
+		}
+		if step.Call != "" && (step.Foreach != nil || step.WaitUntil != nil) {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("%s.call", step.Name),
+				Message:  fmt.Sprintf("step '%s' cannot combine `call` with `foreach` or `wait_until`", step.Name),
+				Line:     stepLine,
+				Col:      0,
+			})
+		}
+
+		// Check explicit dependencies, which must also be earlier steps
+		for _, dep := range step.DependsOn {
+			if definedSteps[dep] {
+				continue
+			}
+			msg := fmt.Sprintf("step '%s' depends on '%s' before it is defined", step.Name, dep)
+			if dep == step.Name {
+				msg = fmt.Sprintf("step '%s' cannot depend on itself", step.Name)
+			}
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("%s.depends_on", step.Name),
+				Message:  msg,
+				Line:     stepLine,
+				Col:      0,
+			})
+		}
+
+		// Check when/skip_if conditions parse as jq
+		for _, c := range []struct{ field, cond string }{{"when", step.When}, {"skip_if", step.SkipIf}} {
+			field, cond := c.field, c.cond
+			if cond == "" {
+				continue
+			}
+			if _, err := gojq.Parse(cond); err != nil {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityError,
+					Field:    fmt.Sprintf("%s.%s", step.Name, field),
+					Message:  fmt.Sprintf("JQ syntax error in `%s`: %s", field, err.Error()),
+					Line:     findValueInTextForAssertion(text, cond),
+					Col:      0,
+				})
+			}
+		}
+
+		// Check foreach is a jq expression or a list
+		switch v := step.Foreach.(type) {
+		case nil:
+			if step.Parallel != 0 {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityWarning,
+					Field:    fmt.Sprintf("%s.parallel", step.Name),
+					Message:  fmt.Sprintf("`parallel` on step '%s' has no effect without `foreach`", step.Name),
+					Line:     stepLine,
+					Col:      0,
+				})
+			}
+		case string:
+			if _, err := gojq.Parse(v); err != nil {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityError,
+					Field:    fmt.Sprintf("%s.foreach", step.Name),
+					Message:  fmt.Sprintf("JQ syntax error in `foreach`: %s", err.Error()),
+					Line:     findValueInTextForAssertion(text, v),
+					Col:      0,
+				})
+			}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					diags = append(diags, scanForUndefinedRefs(text, s, definedSteps, step.Name, "foreach")...)
+				}
+			}
+		default:
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("%s.foreach", step.Name),
+				Message:  fmt.Sprintf("`foreach` on step '%s' must be a jq expression or a list", step.Name),
+				Line:     stepLine,
+				Col:      0,
+			})
+		}
+		if step.Parallel < 0 {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("%s.parallel", step.Name),
+				Message:  "`parallel` must not be negative",
+				Line:     stepLine,
+				Col:      0,
+			})
+		}
+
+		// Check wait_until has a valid condition and timings
+		if w := step.WaitUntil; w != nil {
+			field := fmt.Sprintf("%s.wait_until", step.Name)
+			var problems []string
+			if w.Condition == "" {
+				problems = append(problems, "`wait_until` requires a `condition`")
+			} else if _, err := gojq.Parse(w.Condition); err != nil {
+				problems = append(problems, fmt.Sprintf("JQ syntax error in `wait_until.condition`: %s", err.Error()))
+			}
+			if _, _, _, err := w.Durations(); err != nil {
+				problems = append(problems, "`wait_until` "+err.Error())
+			}
+			if w.Backoff != 0 && w.Backoff < 1 {
+				problems = append(problems, "`wait_until.backoff` must be at least 1")
+			}
+			for _, msg := range problems {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityError,
+					Field:    field,
+					Message:  msg,
+					Line:     stepLine,
+					Col:      0,
+				})
+			}
+		}
+
+		// Check capture names and expressions
+		for _, name := range slices.Sorted(maps.Keys(step.Capture)) {
+			field := fmt.Sprintf("%s.capture.%s", step.Name, name)
+			var msg string
+			switch {
+			case name == "body" || name == "status" || name == "headers":
+				msg = fmt.Sprintf("capture name '%s' is reserved for the step's response", name)
+			case strings.ContainsAny(name, ".| "):
+				msg = fmt.Sprintf("capture name '%s' must not contain dots, pipes or spaces", name)
+			default:
+				if _, err := gojq.Parse(step.Capture[name]); err != nil {
+					msg = fmt.Sprintf("JQ syntax error in capture '%s': %s", name, err.Error())
+				}
+			}
+			if msg != "" {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityError,
+					Field:    field,
+					Message:  msg,
+					Line:     stepLine,
+					Col:      0,
+				})
 			}
 		}
 
@@ -388,12 +634,50 @@ func validateChain(text string, base *config.ConfigV1, chain []config.ChainStep)
 		if len(step.Expect.Assert.Headers) > 0 {
 			diags = append(diags, ValidateChainAssertions(text, step.Expect.Assert.Headers, step.Name)...)
 		}
+		if len(step.Expect.Assert.Cache) > 0 {
+			diags = append(diags, ValidateChainAssertions(text, step.Expect.Assert.Cache, step.Name)...)
+		}
+		if len(step.Expect.Assert.TLS) > 0 {
+			diags = append(diags, ValidateChainAssertions(text, step.Expect.Assert.TLS, step.Name)...)
+		}
 
 		// 5. Add to defined scope
 		if step.Name != "" {
 			definedSteps[step.Name] = true
 		}
 	}
+
+	// Chain outputs may reference any step
+	if base != nil {
+		for _, name := range slices.Sorted(maps.Keys(base.ChainOutputs)) {
+			value := base.ChainOutputs[name]
+			for _, match := range vars.Expansion.FindAllStringSubmatch(value, -1) {
+				key := match[1]
+				if key == "" {
+					key = match[2]
+				}
+				step, expr, isJQ, ok := vars.ChainRef(key)
+				if !ok {
+					continue
+				}
+				var msg string
+				if !definedSteps[step] {
+					msg = fmt.Sprintf("chain_outputs '%s' references unknown step '%s'", name, step)
+				} else if _, err := gojq.Parse(expr); isJQ && err != nil {
+					msg = fmt.Sprintf("JQ syntax error in reference '%s': %s", match[0], err.Error())
+				}
+				if msg != "" {
+					diags = append(diags, Diagnostic{
+						Severity: SeverityError,
+						Field:    "chain_outputs." + name,
+						Message:  msg,
+						Line:     findValueInText(text, match[0]),
+						Col:      0,
+					})
+				}
+			}
+		}
+	}
 	return diags
 }
 
@@ -434,10 +718,19 @@ func scanForUndefinedRefs(text, value string, definedSteps map[string]bool, curr
 			key = match[2]
 		}
 
-		// Only check chain references (containing dot)
-		if strings.Contains(key, ".") {
-			parts := strings.Split(key, ".")
-			refStep := parts[0]
+		// Only check chain references (containing a dot, or a pipe for jq)
+		if refStep, expr, isJQ, ok := vars.ChainRef(key); ok {
+			if isJQ {
+				if _, err := gojq.Parse(expr); err != nil {
+					diags = append(diags, Diagnostic{
+						Severity: SeverityError,
+						Field:    fmt.Sprintf("%s.%s", currentStep, fieldName),
+						Message:  fmt.Sprintf("JQ syntax error in reference '%s': %s", match[0], err.Error()),
+						Line:     findValueInText(text, match[0]),
+						Col:      0,
+					})
+				}
+			}
 
 			if !definedSteps[refStep] {
 				msg := fmt.Sprintf("step '%s' references '%s' before it is defined", currentStep, refStep)
@@ -482,10 +775,16 @@ func isJQBuiltin(varName string) bool {
 	return false
 }
 
+// foreachKey matches a chain step's foreach key, which makes ${item} and ${index}
+// loop variables rather than environment variables.
+var foreachKey = regexp.MustCompile(`(?m)^\s*(-\s+)?foreach:`)
+
 // FindEnvVarRefs finds all environment variable references in text
 func FindEnvVarRefs(text string) []EnvVarInfo {
 	var refs []EnvVarInfo
 	lines := strings.Split(text, "\n")
+	hasForeach := foreachKey.MatchString(text)
+	matrixVars := matrixFields(text, "")
 
 	// Track if we're inside a graphql block (which uses $var syntax for GraphQL variables)
 	inGraphQLBlock := false
@@ -539,12 +838,27 @@ func FindEnvVarRefs(text string) []EnvVarInfo {
 			}
 		}
 
+		// $name inside a jq chain reference, ${step | ...}, is a jq variable
+		var jqRefs [][]int
+		for _, ref := range vars.Expansion.FindAllStringSubmatchIndex(lineWithoutComment, -1) {
+			if ref[2] >= 0 && strings.Contains(lineWithoutComment[ref[2]:ref[3]], "|") {
+				jqRefs = append(jqRefs, ref)
+			}
+		}
+
 		matches := vars.EnvOnly.FindAllStringSubmatchIndex(lineWithoutComment, -1)
+	envMatches:
 		for _, match := range matches {
 			// match[0:2] = full match, match[2:4] = ${VAR} capture, match[4:6] = $VAR capture
 			fullStart, fullEnd := match[0], match[1]
 			fullMatch := lineWithoutComment[fullStart:fullEnd]
 
+			for _, ref := range jqRefs {
+				if fullStart > ref[0] && fullStart < ref[1] {
+					continue envMatches
+				}
+			}
+
 			// Skip if this looks like a chain reference (contains a dot after the var name)
 			// Check the character after the match
 			if fullEnd < len(lineWithoutComment) && lineWithoutComment[fullEnd] == '.' {
@@ -576,6 +890,16 @@ func FindEnvVarRefs(text string) []EnvVarInfo {
 				continue
 			}
 
+			// Skip foreach loop variables
+			if hasForeach && (varName == "item" || varName == "index") {
+				continue
+			}
+
+			// Skip fields of an inline matrix
+			if matrixVars[varName] {
+				continue
+			}
+
 			value := os.Getenv(varName)
 			refs = append(refs, EnvVarInfo{
 				Name:       varName,
@@ -603,12 +927,13 @@ func RedactValue(value string) string {
 }
 
 // validateEnvVars checks for undefined environment variables and returns warnings
-func validateEnvVars(text string) []Diagnostic {
+func validateEnvVars(text, dir string) []Diagnostic {
 	var diags []Diagnostic
 
 	refs := FindEnvVarRefs(text)
+	fields := matrixFields(text, dir)
 	for _, ref := range refs {
-		if !ref.IsDefined {
+		if !ref.IsDefined && !fields[ref.Name] {
 			diags = append(diags, Diagnostic{
 				Severity: SeverityWarning,
 				Field:    ref.Name,
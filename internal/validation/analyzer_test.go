@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -111,6 +113,78 @@ graphql: |
 	}
 }
 
+func TestAnalyzeConfig_GraphQLSchema(t *testing.T) {
+	dir := t.TempDir()
+	schema := "type Query { user(id: ID!): User }\ntype User { id: ID! name: String }\n"
+	if err := os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte(schema), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	yaml := `yapi: v1
+url: http://example.com/graphql
+schema: schema.graphql
+graphql: |
+  query GetUser($id: ID!) {
+    user(id: $id) { id email }
+  }`
+
+	a, err := AnalyzeConfigStringInDir(yaml, nil, "", dir)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+
+	var fieldErr, varErr *Diagnostic
+	for i, d := range a.Diagnostics {
+		switch {
+		case strings.Contains(d.Message, `Cannot query field "email"`):
+			fieldErr = &a.Diagnostics[i]
+		case strings.Contains(d.Message, "missing required GraphQL variable `$id`"):
+			varErr = &a.Diagnostics[i]
+		}
+	}
+	if fieldErr == nil || fieldErr.Severity != SeverityError {
+		t.Fatalf("expected unknown field error, got %+v", a.Diagnostics)
+	}
+	if fieldErr.Line != 5 || fieldErr.Col != 23 {
+		t.Errorf("unknown field at %d:%d, want 5:23", fieldErr.Line, fieldErr.Col)
+	}
+	if varErr == nil {
+		t.Errorf("expected missing variable error, got %+v", a.Diagnostics)
+	}
+
+	a, err = AnalyzeConfigStringInDir(yaml+"\nvariables:\n  id: \"1\"", nil, "", filepath.Join(dir, "elsewhere"))
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+	if !hasDiagnostic(a.Diagnostics, "failed to load GraphQL schema") {
+		t.Errorf("expected schema load error for a missing file, got %+v", a.Diagnostics)
+	}
+}
+
+func TestAnalyzeConfig_GraphQLUploadsProvideVariables(t *testing.T) {
+	dir := t.TempDir()
+	schema := "scalar Upload\ntype Query { ok: Boolean }\ntype Mutation { upload(file: Upload!): Boolean }\n"
+	if err := os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte(schema), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	yaml := `yapi: v1
+url: http://example.com/graphql
+schema: schema.graphql
+graphql: |
+  mutation($file: Upload!) { upload(file: $file) }
+uploads:
+  file: ./avatar.png`
+
+	a, err := AnalyzeConfigStringInDir(yaml, nil, "", dir)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+	if hasDiagnostic(a.Diagnostics, "missing required GraphQL variable") {
+		t.Errorf("uploads should provide `$file`, got %+v", a.Diagnostics)
+	}
+}
+
 func TestAnalyzeConfig_BadJQ(t *testing.T) {
 	yaml := `yapi: v1
 url: http://example.com
@@ -713,3 +787,63 @@ variables:
 		}
 	}
 }
+
+func TestFindEnvVarRefs_JQChainReference(t *testing.T) {
+	yaml := `yapi: v1
+chain:
+  - name: list
+    url: ${BASE_URL}/items
+  - name: next
+    url: ${BASE_URL}/items/${list | .items[$status - 200].id}?t=$TOKEN`
+
+	var names []string
+	for _, ref := range FindEnvVarRefs(yaml) {
+		names = append(names, ref.Name)
+	}
+	if strings.Join(names, ",") != "BASE_URL,BASE_URL,TOKEN" {
+		t.Errorf("expected only BASE_URL and TOKEN, got %v", names)
+	}
+}
+
+func TestAnalyzeConfig_Matrix(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rows.csv"), []byte("email,status\nbad,400\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Row fields are variables, not environment variables
+	inline := `yapi: v1
+url: https://example.com/users?email=${email}&token=${YAPI_MATRIX_TOKEN}
+matrix:
+  - email: bad
+    status: 400
+expect:
+  status: ${status}`
+	a, err := AnalyzeConfigString(inline)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+	if hasDiagnostic(a.Diagnostics, "'email'") || hasDiagnostic(a.Diagnostics, "'status'") {
+		t.Errorf("matrix fields should not be reported: %v", a.Diagnostics)
+	}
+	if !hasDiagnostic(a.Diagnostics, "environment variable 'YAPI_MATRIX_TOKEN' is not defined") {
+		t.Errorf("expected other variables to still be checked: %v", a.Diagnostics)
+	}
+
+	file := strings.Replace(inline, "matrix:\n  - email: bad\n    status: 400", "matrix: rows.csv", 1)
+	a, err = AnalyzeConfigStringInDir(file, nil, "", dir)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+	if hasDiagnostic(a.Diagnostics, "'email'") || a.HasErrors() {
+		t.Errorf("matrix file fields should not be reported: %v", a.Diagnostics)
+	}
+
+	a, err = AnalyzeConfigStringInDir(strings.Replace(file, "rows.csv", "missing.csv", 1), nil, "", dir)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+	if !hasDiagnostic(a.Diagnostics, "matrix: open") {
+		t.Errorf("expected a missing matrix file error: %v", a.Diagnostics)
+	}
+}
@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"yapi.run/cli/internal/config"
+)
+
+// validateCalls checks that the files chain steps call exist and parse, following
+// their own calls in turn to find cycles. Paths are relative to dir, the directory
+// of the config being analyzed.
+func validateCalls(text, dir string, chain []config.ChainStep) []Diagnostic {
+	var diags []Diagnostic
+	for _, step := range chain {
+		if step.Call == "" {
+			continue
+		}
+		path := step.Call
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if msg := callProblem(dir, path, nil); msg != "" {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Field:    step.Name + ".call",
+				Message:  fmt.Sprintf("step '%s' call: %s", step.Name, msg),
+				Line:     findValueInText(text, step.Call),
+				Col:      0,
+			})
+		}
+	}
+	return diags
+}
+
+// callProblem describes the first problem with the called file at path or the files
+// it calls, or returns "". stack holds the files being followed, outermost first.
+func callProblem(dir, path string, stack []string) string {
+	base, _ := filepath.Abs(dir)
+	display := func(p string) string {
+		if rel, err := filepath.Rel(base, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return p
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err.Error()
+	}
+	if start := slices.Index(stack, abs); start >= 0 {
+		names := make([]string, 0, len(stack)-start+1)
+		for _, p := range stack[start:] {
+			names = append(names, display(p))
+		}
+		return "call cycle: " + strings.Join(append(names, display(abs)), " -> ")
+	}
+
+	data, err := os.ReadFile(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("%s does not exist", display(abs))
+	}
+	if err != nil {
+		return err.Error()
+	}
+	_, steps, err := config.LoadCallTarget(string(data), "", nil)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", display(abs), err)
+	}
+
+	stack = append(stack, abs)
+	for _, step := range steps {
+		if step.Call == "" {
+			continue
+		}
+		next := step.Call
+		if !filepath.IsAbs(next) {
+			next = filepath.Join(filepath.Dir(abs), next)
+		}
+		if msg := callProblem(dir, next, stack); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
@@ -1,6 +1,9 @@
 package validation
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -265,3 +268,350 @@ chain:
 		t.Errorf("expected 1 chain step, got %d", len(a.Chain))
 	}
 }
+
+func TestAnalyzeConfig_ChainDependsOn(t *testing.T) {
+	yaml := `yapi: v1
+parallel: 4
+chain:
+  - name: login
+    url: https://example.com/login
+  - name: seed
+    url: https://example.com/seed
+    depends_on: [login]
+  - name: report
+    url: https://example.com/report
+    depends_on: [seed, cleanup]`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "depends on 'cleanup' before it is defined") {
+		t.Errorf("expected only the forward dependency error, got %v", errs)
+	}
+}
+
+func TestAnalyzeConfig_ChainConditions(t *testing.T) {
+	yaml := `yapi: v1
+chain:
+  - name: lookup
+    url: https://example.com/users/1
+  - name: create
+    url: https://example.com/users
+    when: .lookup.status == 404
+  - name: update
+    url: https://example.com/users/1
+    skip_if: .lookup.status ==`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "JQ syntax error in `skip_if`") {
+		t.Errorf("expected only the skip_if syntax error, got %v", errs)
+	}
+}
+
+func TestAnalyzeConfig_ChainForeach(t *testing.T) {
+	yaml := `yapi: v1
+chain:
+  - name: list
+    url: https://example.com/users
+  - name: cleanup
+    foreach: .list.body.items
+    parallel: 4
+    url: https://example.com/users/${item.id}?n=${index}
+  - name: tags
+    foreach: [red, "${cleanup.0.id}"]
+    url: https://example.com/tags/${item}
+  - name: bad
+    foreach: '.list.body |'
+    url: https://example.com/bad
+  - name: plain
+    parallel: 2
+    url: https://example.com/${item.id}`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs, warns []string
+	for _, d := range a.Diagnostics {
+		switch d.Severity {
+		case SeverityError:
+			errs = append(errs, d.Message)
+		case SeverityWarning:
+			warns = append(warns, d.Message)
+		}
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], "JQ syntax error in `foreach`") || !strings.Contains(errs[1], "step 'plain' references 'item' before it is defined") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(warns) != 1 || !strings.Contains(warns[0], "`parallel` on step 'plain' has no effect without `foreach`") {
+		t.Errorf("unexpected warnings: %v", warns)
+	}
+}
+
+func TestAnalyzeConfig_ChainWaitUntil(t *testing.T) {
+	yaml := `yapi: v1
+chain:
+  - name: submit
+    url: https://example.com/jobs
+  - name: poll
+    url: https://example.com/jobs/${submit.id}
+    wait_until:
+      condition: .status == "done"
+      interval: 500ms
+      backoff: 2
+      max_interval: 5s
+      timeout: 2m
+  - name: bad
+    url: https://example.com/jobs
+    wait_until:
+      interval: soon
+      backoff: 0.5`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	want := []string{
+		"`wait_until` requires a `condition`",
+		"`wait_until` invalid interval 'soon'",
+		"`wait_until.backoff` must be at least 1",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i], w) {
+			t.Errorf("error %d = %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+}
+
+func TestAnalyzeConfig_ChainJQReferences(t *testing.T) {
+	yaml := `yapi: v1
+chain:
+  - name: list
+    url: https://example.com/items
+  - name: first
+    url: https://example.com/items/${list | .items[0].id}
+  - name: broken
+    url: https://example.com/items/${list | .items[0}
+  - name: early
+    url: https://example.com/items/${later | length}
+  - name: later
+    url: https://example.com/items`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], "JQ syntax error in reference '${list | .items[0}'") || !strings.Contains(errs[1], "step 'early' references 'later' before it is defined") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestAnalyzeConfig_ChainCaptureAndOutputs(t *testing.T) {
+	yaml := `yapi: v1
+chain_outputs:
+  user_id: ${create.user_id}
+  audit: ${audit.id}
+chain:
+  - name: create
+    url: https://example.com/users
+    capture:
+      user_id: .data.user.id
+      status: .status
+      bad: .data[
+  - name: fetch
+    url: https://example.com/users/${create.user_id}`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	want := []string{
+		"JQ syntax error in capture 'bad'",
+		"capture name 'status' is reserved",
+		"chain_outputs 'audit' references unknown step 'audit'",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i], w) {
+			t.Errorf("error %d = %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+}
+
+func TestAnalyzeConfig_ChainCalls(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("login.yapi.yml", "yapi: v1\nurl: https://example.com/login\nmethod: POST\n")
+	write("a.yapi.yml", "yapi: v1\nchain:\n  - name: b\n    call: b.yapi.yml\n")
+	write("b.yapi.yml", "yapi: v1\nchain:\n  - name: a\n    call: a.yapi.yml\n")
+
+	yaml := `yapi: v1
+chain:
+  - name: login
+    call: login.yapi.yml
+    with:
+      username: ${USER_NAME}
+  - name: missing
+    call: missing.yapi.yml
+  - name: loop
+    call: a.yapi.yml
+    with:
+      token: ${later.token}
+  - name: later
+    url: https://example.com/me
+    with:
+      token: abc`
+
+	a, err := AnalyzeConfigStringInDir(yaml, nil, "", dir)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigStringInDir error: %v", err)
+	}
+
+	var errs, warnings []string
+	for _, d := range a.Diagnostics {
+		switch d.Severity {
+		case SeverityError:
+			errs = append(errs, d.Message)
+		case SeverityWarning:
+			warnings = append(warnings, d.Message)
+		}
+	}
+	want := []string{
+		"step 'loop' references 'later' before it is defined",
+		"step 'missing' call: missing.yapi.yml does not exist",
+		"step 'loop' call: call cycle: a.yapi.yml -> b.yapi.yml -> a.yapi.yml",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i], w) {
+			t.Errorf("error %d = %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "`with` on step 'later' has no effect") }) {
+		t.Errorf("expected a warning about `with` without `call`, got %v", warnings)
+	}
+}
+
+func TestAnalyzeConfig_ChainFinally(t *testing.T) {
+	yaml := `yapi: v1
+url: https://example.com
+chain:
+  - name: create
+    path: /records
+    method: POST
+    continue_on_error: true
+    body:
+      note: ${cleanup.id}
+finally:
+  - name: cleanup
+    path: /records/${create.id}
+    method: DELETE
+  - name: create
+    path: /again`
+
+	a, err := AnalyzeConfigString(yaml)
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+
+	var errs []string
+	for _, d := range a.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.Message)
+		}
+	}
+	want := []string{
+		"step 'create' references 'cleanup' before it is defined",
+		"duplicate step name 'create'",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i], w) {
+			t.Errorf("error %d = %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+
+	a, err = AnalyzeConfigString("yapi: v1\nurl: https://example.com\nfinally:\n  - name: cleanup\n")
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+	if !slices.ContainsFunc(a.Diagnostics, func(d Diagnostic) bool { return strings.Contains(d.Message, "`finally` has no effect without `chain`") }) {
+		t.Errorf("expected a warning about finally without chain, got %v", a.Diagnostics)
+	}
+}
+
+func TestAnalyzeConfig_ChainTimeout(t *testing.T) {
+	a, err := AnalyzeConfigString("yapi: v1\nurl: https://example.com\nchain_timeout: soon\nchain:\n  - name: a\n    path: /a\n")
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+	if !slices.ContainsFunc(a.Diagnostics, func(d Diagnostic) bool {
+		return d.Severity == SeverityError && d.Field == "chain_timeout" && d.Line == 2
+	}) {
+		t.Errorf("expected an error on the invalid chain_timeout, got %v", a.Diagnostics)
+	}
+
+	a, err = AnalyzeConfigString("yapi: v1\nurl: https://example.com\nchain_timeout: 1m\n")
+	if err != nil {
+		t.Fatalf("AnalyzeConfigString error: %v", err)
+	}
+	if !slices.ContainsFunc(a.Diagnostics, func(d Diagnostic) bool {
+		return d.Severity == SeverityWarning && strings.Contains(d.Message, "`chain_timeout` has no effect without `chain`")
+	}) {
+		t.Errorf("expected a warning about chain_timeout without chain, got %v", a.Diagnostics)
+	}
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"yapi.run/cli/internal/config"
+	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/gqlschema"
+)
+
+// ValidateGraphQLSchema validates the GraphQL query against the request's schema:
+// the `schema` SDL file if set (resolved against baseDir), otherwise the introspection
+// cached for the URL by `yapi introspect`. Violations against a schema file are errors;
+// against a cached schema they are warnings, since the cache may be stale.
+func ValidateGraphQLSchema(fullYaml string, req *domain.Request, baseDir string) []Diagnostic {
+	q := req.Metadata["graphql_query"]
+	if q == "" {
+		return nil
+	}
+
+	schema, err := gqlschema.Load(req.Metadata["graphql_schema"], baseDir, req.URL, gqlschema.NewStore(""))
+	if err != nil {
+		return []Diagnostic{{
+			Severity: SeverityError,
+			Field:    "schema",
+			Message:  "failed to load GraphQL schema: " + err.Error(),
+			Line:     findFieldLine(fullYaml, "schema"),
+			Col:      0,
+		}}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	src := source.NewSource(&source.Source{Body: []byte(q), Name: "GraphQL Query"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return nil // Reported by ValidateGraphQLSyntax
+	}
+
+	severity := SeverityError
+	if schema.Cached {
+		severity = SeverityWarning
+	}
+	queryLine, queryCol := GraphQLQueryStart(fullYaml)
+	position := func(loc location.SourceLocation) (int, int) {
+		if queryLine < 0 {
+			return -1, 0
+		}
+		if queryCol < 0 {
+			return queryLine, 0 // Inline query: only the line is known
+		}
+		return queryLine + loc.Line - 1, queryCol + loc.Column - 1
+	}
+
+	var diags []Diagnostic
+	result := graphql.ValidateDocument(schema.Schema, doc, nil)
+	for _, e := range result.Errors {
+		line, col := queryLine, 0
+		if len(e.Locations) > 0 {
+			line, col = position(e.Locations[0])
+		}
+		diags = append(diags, Diagnostic{
+			Severity: severity,
+			Field:    "graphql",
+			Message:  "GraphQL schema error: " + e.Message,
+			Line:     line,
+			Col:      col,
+		})
+	}
+
+	// Required variables of the operations that will run must be supplied by
+	// `variables` (or `uploads`), or by each `batch` operation, since they have no default
+	type target struct {
+		operation, batch string
+		provided         map[string]any
+	}
+	var targets []target
+	if raw := req.Metadata["graphql_batch"]; raw != "" {
+		var batch []config.GraphQLBatchEntry
+		_ = json.Unmarshal([]byte(raw), &batch)
+		for _, entry := range batch {
+			targets = append(targets, target{operation: entry.OperationName, batch: entry.Name, provided: entry.Variables})
+		}
+	} else {
+		var provided map[string]any
+		if vars := req.Metadata["graphql_variables"]; vars != "" {
+			_ = json.Unmarshal([]byte(vars), &provided)
+		}
+		var uploads map[string]string
+		if raw := req.Metadata["graphql_uploads"]; raw != "" {
+			_ = json.Unmarshal([]byte(raw), &uploads)
+		}
+		for path := range uploads {
+			name, _, _ := strings.Cut(path, ".")
+			if provided == nil {
+				provided = map[string]any{}
+			}
+			if provided[name] == nil {
+				provided[name] = true
+			}
+		}
+		targets = append(targets, target{operation: req.Metadata["graphql_operation_name"], provided: provided})
+	}
+
+	for _, t := range targets {
+		for _, def := range doc.Definitions {
+			op, ok := def.(*ast.OperationDefinition)
+			if !ok || (t.operation != "" && (op.Name == nil || op.Name.Value != t.operation)) {
+				continue
+			}
+			for _, v := range op.VariableDefinitions {
+				if _, nonNull := v.Type.(*ast.NonNull); !nonNull || v.DefaultValue != nil {
+					continue
+				}
+				name := v.Variable.Name.Value
+				if val, ok := t.provided[name]; ok && val != nil {
+					continue
+				}
+				msg := fmt.Sprintf("missing required GraphQL variable `$%s` (%s)", name, typeString(v.Type))
+				if t.batch != "" {
+					msg += fmt.Sprintf(" in batch operation `%s`", t.batch)
+				}
+				line, col := position(location.GetLocation(src, v.Loc.Start))
+				diags = append(diags, Diagnostic{
+					Severity: severity,
+					Field:    "variables",
+					Message:  msg,
+					Line:     line,
+					Col:      col,
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+// GraphQLQueryStart returns the 0-based line and column where the embedded query
+// text begins in the YAML. For a block scalar (graphql: |) that is the line after the
+// key and the block indentation; for an inline value the column is -1.
+func GraphQLQueryStart(fullYaml string) (int, int) {
+	line := findFieldLine(fullYaml, "graphql")
+	if line < 0 {
+		return -1, -1
+	}
+	lines := strings.Split(fullYaml, "\n")
+	_, value, _ := strings.Cut(lines[line], ":")
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "|") && !strings.HasPrefix(value, ">") {
+		return line, -1
+	}
+	// Leading blank lines are part of the block, so the query starts on the next line;
+	// the indentation is taken from the first non-blank line
+	for i := line + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			return line + 1, len(lines[i]) - len(strings.TrimLeft(lines[i], " \t"))
+		}
+	}
+	return line, -1
+}
+
+// typeString prints a variable type such as [ID!]!.
+func typeString(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return typeString(t.Type) + "!"
+	case *ast.List:
+		return "[" + typeString(t.Type) + "]"
+	case *ast.Named:
+		return t.Name.Value
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"gopkg.in/yaml.v3"
+	"yapi.run/cli/internal/config"
+)
+
+// matrixFields returns the fields of the config's matrix rows, which the config reads
+// as ${field} rather than from the environment. A matrix file is read from dir; with
+// no dir, only an inline matrix is considered.
+func matrixFields(text, dir string) map[string]bool {
+	var cfg struct {
+		Matrix any `yaml:"matrix"`
+	}
+	if err := yaml.Unmarshal([]byte(text), &cfg); err != nil || cfg.Matrix == nil {
+		return nil
+	}
+	if _, isFile := cfg.Matrix.(string); isFile && dir == "" {
+		return nil
+	}
+	rows, err := config.MatrixRows(cfg.Matrix, dir)
+	if err != nil {
+		return nil
+	}
+	fields := make(map[string]bool)
+	for _, row := range rows {
+		for k := range row {
+			fields[k] = true
+		}
+	}
+	return fields
+}
+
+// validateMatrix checks that the config's matrix rows load.
+func validateMatrix(text string, base *config.ConfigV1, dir string) []Diagnostic {
+	if base == nil || base.Matrix == nil {
+		return nil
+	}
+	rows, err := config.MatrixRows(base.Matrix, dir)
+	msg := ""
+	switch {
+	case err != nil:
+		msg = err.Error()
+	case len(rows) == 0:
+		msg = "matrix has no rows"
+	}
+	if msg == "" {
+		return nil
+	}
+	return []Diagnostic{{
+		Severity: SeverityError,
+		Field:    "matrix",
+		Message:  msg,
+		Line:     findFieldLine(text, "matrix"),
+		Col:      0,
+	}}
+}
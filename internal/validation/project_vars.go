@@ -22,13 +22,19 @@ type VarDiagnosis struct {
 // ValidateProjectVars performs matrix validation of variables across all environments.
 // This is the "smart validation" that enables diagnostics like "API_URL is missing in 'staging'".
 func ValidateProjectVars(text string, project *config.ProjectConfigV1, projectRoot string) []Diagnostic {
+	return validateProjectVars(text, project, projectRoot, "")
+}
+
+// validateProjectVars is ValidateProjectVars for a config file in dir, whose matrix
+// file, if any, names variables too.
+func validateProjectVars(text string, project *config.ProjectConfigV1, projectRoot, dir string) []Diagnostic {
 	if project == nil {
 		// Fallback to legacy OS env check
-		return validateEnvVars(text)
+		return validateEnvVars(text, dir)
 	}
 
-	// 1. Extract all ${VAR} tokens from the config file (excluding chain refs)
-	varNames := extractEnvVarNames(text)
+	// 1. Extract all ${VAR} tokens from the config file (excluding chain refs and matrix fields)
+	varNames := extractEnvVarNames(text, dir)
 	if len(varNames) == 0 {
 		return nil
 	}
@@ -152,12 +158,17 @@ func ValidateProjectVars(text string, project *config.ProjectConfigV1, projectRo
 }
 
 // extractEnvVarNames extracts all unique environment variable names from the text.
-// Excludes chain references (${step.field}) and known JQ built-in variables.
-func extractEnvVarNames(text string) map[string]bool {
+// Excludes chain references (${step.field}), known JQ built-in variables, and the
+// fields of the config's matrix, whose file is resolved against dir.
+func extractEnvVarNames(text, dir string) map[string]bool {
 	result := make(map[string]bool)
 	refs := FindEnvVarRefs(text)
+	fields := matrixFields(text, dir)
 
 	for _, ref := range refs {
+		if fields[ref.Name] {
+			continue
+		}
 		// Skip if it's a chain reference
 		if strings.Contains(ref.Name, ".") {
 			continue
@@ -227,8 +238,14 @@ type EnvironmentRequirement struct {
 // CheckEnvironmentRequirement analyzes a config to determine if it needs an environment.
 // Returns requirement info including which variables are missing and where they're defined.
 func CheckEnvironmentRequirement(text string, project *config.ProjectConfigV1, projectRoot string) *EnvironmentRequirement {
+	return CheckEnvironmentRequirementInDir(text, project, projectRoot, "")
+}
+
+// CheckEnvironmentRequirementInDir is CheckEnvironmentRequirement for a config file in dir,
+// against which a matrix file is resolved.
+func CheckEnvironmentRequirementInDir(text string, project *config.ProjectConfigV1, projectRoot, dir string) *EnvironmentRequirement {
 	// Extract all variables used in the config
-	varNames := extractEnvVarNames(text)
+	varNames := extractEnvVarNames(text, dir)
 	if len(varNames) == 0 {
 		// No variables used - no environment needed
 		return &EnvironmentRequirement{Required: false}
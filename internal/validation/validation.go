@@ -1,10 +1,19 @@
 package validation
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"yapi.run/cli/internal/config"
 	"yapi.run/cli/internal/constants"
 	"yapi.run/cli/internal/domain"
+	"yapi.run/cli/internal/gqlschema"
 )
 
 // Severity indicates the level of a validation issue.
@@ -45,6 +54,11 @@ func isTCPRequest(req *domain.Request) bool {
 	return req.Metadata["transport"] == constants.TransportTCP
 }
 
+// isUDPRequest returns true if this is a UDP request
+func isUDPRequest(req *domain.Request) bool {
+	return req.Metadata["transport"] == constants.TransportUDP
+}
+
 // isHTTPRequest returns true if this is an HTTP request
 func isHTTPRequest(req *domain.Request) bool {
 	t := req.Metadata["transport"]
@@ -81,6 +95,123 @@ func ValidateRequest(req *domain.Request) []Issue {
 			fmt.Sprintf("unsupported TCP encoding `%s` (allowed: text, hex, base64)", req.Metadata["encoding"]))
 	}
 
+	if req.Metadata["tls"] == "true" && (req.Metadata["tls_client_cert"] == "") != (req.Metadata["tls_client_key"] == "") {
+		add(SeverityError, "tls", "`tls.client_cert` and `tls.client_key` must be set together")
+	}
+
+	if script := req.Metadata["conversation"]; script != "" {
+		if req.Metadata["data"] != "" {
+			add(SeverityWarning, "data", "`data` is ignored when `conversation` is set")
+		}
+		var steps []config.ConversationStep
+		if err := json.Unmarshal([]byte(script), &steps); err == nil {
+			for i, step := range steps {
+				if step.ExpectRegex != "" {
+					if _, err := regexp.Compile(step.ExpectRegex); err != nil {
+						add(SeverityError, "conversation", fmt.Sprintf("step %d: invalid `expect_regex`: %v", i+1, err))
+					}
+				}
+				if step.Timeout != "" {
+					if _, err := time.ParseDuration(step.Timeout); err != nil {
+						add(SeverityError, "conversation", fmt.Sprintf("step %d: invalid `timeout` '%s'", i+1, step.Timeout))
+					}
+				}
+			}
+		}
+	}
+
+	switch req.Metadata["framing"] {
+	case "":
+		// No framing configured
+	case "delimiter":
+		if req.Metadata["framing_delimiter"] == "" {
+			add(SeverityError, "framing", "framing type `delimiter` requires `delimiter`")
+		}
+	case "fixed":
+		if n, _ := strconv.Atoi(req.Metadata["framing_length"]); n <= 0 {
+			add(SeverityError, "framing", "framing type `fixed` requires a positive `length`")
+		}
+	case "length_prefix":
+		switch req.Metadata["framing_prefix_bytes"] {
+		case "0", "1", "2", "4":
+		default:
+			add(SeverityError, "framing",
+				fmt.Sprintf("unsupported framing `prefix_bytes` %s (allowed: 1, 2, 4)", req.Metadata["framing_prefix_bytes"]))
+		}
+		switch req.Metadata["framing_byte_order"] {
+		case "", "big", "little":
+		default:
+			add(SeverityError, "framing",
+				fmt.Sprintf("unsupported framing `byte_order` `%s` (allowed: big, little)", req.Metadata["framing_byte_order"]))
+		}
+	default:
+		add(SeverityError, "framing",
+			fmt.Sprintf("unsupported framing type `%s` (allowed: delimiter, fixed, length_prefix)", req.Metadata["framing"]))
+	}
+	if req.Metadata["framing"] != "" && req.Metadata["conversation"] != "" {
+		add(SeverityWarning, "framing", "`framing` is ignored when `conversation` is set")
+	}
+
+	if isUDPRequest(req) {
+		if req.Metadata["encoding"] != "" && !validEncoding(req.Metadata["encoding"]) {
+			add(SeverityError, "encoding",
+				fmt.Sprintf("unsupported UDP encoding `%s` (allowed: text, hex, base64)", req.Metadata["encoding"]))
+		}
+		if n, err := strconv.Atoi(req.Metadata["datagrams"]); err == nil && n < 0 {
+			add(SeverityError, "datagrams", "`datagrams` must not be negative")
+		}
+	}
+
+	switch req.Metadata["auth_type"] {
+	case "":
+		// No auth configured
+	case constants.AuthAWSSigV4:
+		if !isHTTPRequest(req) {
+			add(SeverityError, "auth", "`aws_sigv4` auth is only supported for HTTP and GraphQL requests")
+		}
+		if req.Metadata["aws_region"] == "" {
+			add(SeverityError, "auth", "`aws_sigv4` auth requires `region`")
+		}
+		if req.Metadata["aws_service"] == "" {
+			add(SeverityError, "auth", "`aws_sigv4` auth requires `service`")
+		}
+	case constants.AuthHMAC:
+		if req.Metadata["transport"] != constants.TransportHTTP {
+			add(SeverityError, "auth", "`hmac` auth is only supported for HTTP requests")
+		}
+		if req.Metadata["hmac_secret"] == "" {
+			add(SeverityError, "auth", "`hmac` auth requires `secret`")
+		}
+		switch strings.ToLower(req.Metadata["hmac_algorithm"]) {
+		case "", "sha256", "sha1", "sha512":
+		default:
+			add(SeverityError, "auth", fmt.Sprintf("unsupported hmac algorithm `%s` (allowed: sha256, sha1, sha512)", req.Metadata["hmac_algorithm"]))
+		}
+		switch strings.ToLower(req.Metadata["hmac_format"]) {
+		case "", "hex", "base64":
+		default:
+			add(SeverityError, "auth", fmt.Sprintf("unsupported hmac format `%s` (allowed: hex, base64)", req.Metadata["hmac_format"]))
+		}
+	default:
+		add(SeverityError, "auth",
+			fmt.Sprintf("unsupported auth type `%s` (allowed: %s, %s)", req.Metadata["auth_type"], constants.AuthAWSSigV4, constants.AuthHMAC))
+	}
+
+	if req.Metadata["cache"] == "true" && (req.Metadata["transport"] != constants.TransportHTTP || (method != constants.MethodGET && method != constants.MethodHEAD)) {
+		add(SeverityWarning, "cache", "`cache` only applies to HTTP GET and HEAD requests and will be ignored")
+	}
+
+	if sum := req.Metadata["output_sha256"]; sum != "" && !validSHA256(sum) {
+		add(SeverityError, "output_sha256", "`output_sha256` must be a 64-character hex SHA-256 digest")
+	}
+
+	switch req.Metadata["response_encoding"] {
+	case "", "hex", "base64":
+	default:
+		add(SeverityError, "response_encoding",
+			fmt.Sprintf("unsupported response_encoding `%s` (allowed: hex, base64)", req.Metadata["response_encoding"]))
+	}
+
 	hasBody := req.Body != nil
 	if req.Metadata["graphql_query"] != "" && hasBody {
 		field := "body"
@@ -90,9 +221,87 @@ func ValidateRequest(req *domain.Request) []Issue {
 		add(SeverityError, field, "`graphql` cannot be used with `body` or `json`")
 	}
 
+	if query := req.Metadata["graphql_query"]; query != "" {
+		issues = append(issues, validateGraphQLOperations(req)...)
+
+		events, _ := strconv.Atoi(req.Metadata["graphql_events"])
+		subscription := gqlschema.OperationType(query, req.Metadata["graphql_operation_name"]) == "subscription"
+		switch {
+		case events < 0:
+			add(SeverityError, "events", "`events` must not be negative")
+		case subscription && events == 0 && req.Metadata["timeout"] == "":
+			add(SeverityError, "events", "GraphQL subscriptions need `events` or `timeout` to know when to stop")
+		case !subscription && events > 0:
+			add(SeverityWarning, "events", "`events` only applies to GraphQL subscriptions and will be ignored")
+		}
+		if subscription && req.Metadata["graphql_uploads"] != "" {
+			add(SeverityWarning, "uploads", "`uploads` is not supported for GraphQL subscriptions and will be ignored")
+		}
+		if subscription && req.Metadata["graphql_persisted"] == "true" {
+			add(SeverityWarning, "persisted_query", "`persisted_query` is not supported for GraphQL subscriptions and will be ignored")
+		}
+	}
+
 	return issues
 }
 
+// validateGraphQLOperations checks that `operation_name` and the `batch` operations
+// select operations that exist in the document, and that a document with several
+// operations says which one to run.
+func validateGraphQLOperations(req *domain.Request) []Issue {
+	var issues []Issue
+	add := func(sev Severity, field, msg string) {
+		issues = append(issues, Issue{Severity: sev, Field: field, Message: msg})
+	}
+
+	names := gqlschema.OperationNames(req.Metadata["graphql_query"])
+	if names == nil {
+		return nil // Syntax errors are reported by ValidateGraphQLSyntax
+	}
+	check := func(field, name string) {
+		switch {
+		case name == "" && len(names) > 1:
+			add(SeverityError, field, fmt.Sprintf("`graphql` has %d operations; set `operation_name` to choose one", len(names)))
+		case name != "" && !slices.Contains(names, name):
+			add(SeverityError, field, fmt.Sprintf("operation `%s` not found in `graphql`", name))
+		case gqlschema.OperationType(req.Metadata["graphql_query"], name) == "subscription" && req.Metadata["graphql_batch"] != "":
+			add(SeverityError, field, "GraphQL subscriptions cannot be batched")
+		}
+	}
+
+	raw := req.Metadata["graphql_batch"]
+	if raw == "" {
+		check("operation_name", req.Metadata["graphql_operation_name"])
+		return issues
+	}
+
+	var batch []config.GraphQLBatchEntry
+	if err := json.Unmarshal([]byte(raw), &batch); err != nil {
+		return issues
+	}
+	for i, entry := range batch {
+		check(fmt.Sprintf("batch[%d].operation_name", i), entry.OperationName)
+	}
+	if req.Metadata["graphql_operation_name"] != "" {
+		add(SeverityWarning, "operation_name", "`operation_name` is ignored for batched requests; set it on each `batch` operation")
+	}
+	if req.Metadata["graphql_uploads"] != "" {
+		add(SeverityWarning, "uploads", "`uploads` is not supported for batched requests and will be ignored")
+	}
+	if req.Metadata["graphql_persisted"] == "true" {
+		add(SeverityWarning, "persisted_query", "`persisted_query` is not supported for batched requests and will be ignored")
+	}
+	return issues
+}
+
+func validSHA256(sum string) bool {
+	if len(sum) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(sum)
+	return err == nil
+}
+
 func validEncoding(enc string) bool {
 	switch enc {
 	case "text", "hex", "base64":
@@ -173,6 +173,61 @@ encoding: invalid`)
 	}
 }
 
+func TestValidateRequest_UDP(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantField string
+	}{
+		{
+			name: "valid",
+			yaml: `yapi: v1
+url: udp://localhost:5353
+data: "0001"
+encoding: hex
+datagrams: 2`,
+		},
+		{
+			name: "invalid encoding",
+			yaml: `yapi: v1
+url: udp://localhost:5353
+data: hello
+encoding: utf16`,
+			wantField: "encoding",
+		},
+		{
+			name: "negative datagrams",
+			yaml: `yapi: v1
+url: udp://localhost:5353
+data: hello
+datagrams: -1`,
+			wantField: "datagrams",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString(tt.yaml)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			if res.Request.Metadata["transport"] != "udp" {
+				t.Fatalf("transport = %q, want udp", res.Request.Metadata["transport"])
+			}
+			issues := ValidateRequest(res.Request)
+			if tt.wantField == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %+v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Field != tt.wantField || issues[0].Severity != SeverityError {
+				t.Errorf("expected one %s error, got %+v", tt.wantField, issues)
+			}
+		})
+	}
+}
+
 func TestValidateRequest_ValidConfig(t *testing.T) {
 	res, err := config.LoadFromString(`yapi: v1
 url: http://example.com/api
@@ -250,6 +305,77 @@ graphql: 'query { foo }'`)
 	}
 }
 
+func TestValidateRequest_GraphQLSubscription(t *testing.T) {
+	tests := []struct {
+		name     string
+		extra    string
+		query    string
+		severity Severity
+		want     string
+	}{
+		{"no stop condition", "", "subscription { tick }", SeverityError, "need `events` or `timeout`"},
+		{"negative events", "events: -1", "subscription { tick }", SeverityError, "must not be negative"},
+		{"events on a query", "events: 2", "query { now }", SeverityWarning, "only applies to GraphQL subscriptions"},
+		{"events", "events: 2", "subscription { tick }", 0, ""},
+		{"timeout", "timeout: 5s", "subscription { tick }", 0, ""},
+		{"uploads", "timeout: 5s\nuploads:\n  file: ./a.png", "subscription { tick }", SeverityWarning, "`uploads` is not supported"},
+		{"persisted query", "timeout: 5s\npersisted_query: true", "subscription { tick }", SeverityWarning, "`persisted_query` is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString("yapi: v1\nurl: http://example.com/graphql\ngraphql: '" + tt.query + "'\n" + tt.extra)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			issues := ValidateRequest(res.Request)
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %+v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Severity != tt.severity || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("expected one issue containing %q, got %+v", tt.want, issues)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_GraphQLOperations(t *testing.T) {
+	doc := "graphql: 'query A { a } query B { b } subscription S { s }'\n"
+	tests := []struct {
+		name  string
+		extra string
+		want  string // "" for no issues
+	}{
+		{"operation chosen", "operation_name: B", ""},
+		{"no operation chosen", "", "has 3 operations"},
+		{"unknown operation", "operation_name: C", "operation `C` not found"},
+		{"batch", "batch:\n  - operation_name: A\n  - operation_name: B", ""},
+		{"batch with unknown operation", "batch:\n  - operation_name: A\n  - operation_name: X", "operation `X` not found"},
+		{"batch without operation", "batch:\n  - variables: {}", "set `operation_name`"},
+		{"batched subscription", "batch:\n  - operation_name: S", "cannot be batched"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString("yapi: v1\nurl: http://example.com/graphql\n" + doc + tt.extra)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			issues := ValidateRequest(res.Request)
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %+v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("expected one error containing %q, got %+v", tt.want, issues)
+			}
+		})
+	}
+}
+
 func TestValidateRequest_NoIssuesForMinimalValidTCP(t *testing.T) {
 	res, err := config.LoadFromString(`yapi: v1
 url: tcp://localhost:9000
@@ -388,3 +514,248 @@ func FuzzRedactValue(f *testing.F) {
 		_ = RedactValue(input)
 	})
 }
+
+func TestValidateRequest_AWSSigV4(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantMsg string
+	}{
+		{
+			name: "valid",
+			yaml: `yapi: v1
+url: https://abc.execute-api.us-east-1.amazonaws.com/prod
+auth:
+  type: aws_sigv4
+  region: us-east-1
+  service: execute-api`,
+		},
+		{
+			name: "missing region",
+			yaml: `yapi: v1
+url: https://abc.execute-api.us-east-1.amazonaws.com/prod
+auth:
+  type: aws_sigv4
+  service: execute-api`,
+			wantMsg: "requires `region`",
+		},
+		{
+			name: "missing service",
+			yaml: `yapi: v1
+url: https://abc.execute-api.us-east-1.amazonaws.com/prod
+auth:
+  type: aws_sigv4
+  region: us-east-1`,
+			wantMsg: "requires `service`",
+		},
+		{
+			name: "unsupported type",
+			yaml: `yapi: v1
+url: https://example.com
+auth:
+  type: kerberos`,
+			wantMsg: "unsupported auth type",
+		},
+		{
+			name: "not http",
+			yaml: `yapi: v1
+url: tcp://localhost:9000
+auth:
+  type: aws_sigv4
+  region: us-east-1
+  service: execute-api`,
+			wantMsg: "only supported for HTTP",
+		},
+		{
+			name: "hmac valid",
+			yaml: `yapi: v1
+url: https://example.com
+auth:
+  type: hmac
+  secret: s
+  algorithm: sha512`,
+		},
+		{
+			name: "hmac missing secret",
+			yaml: `yapi: v1
+url: https://example.com
+auth:
+  type: hmac`,
+			wantMsg: "requires `secret`",
+		},
+		{
+			name: "hmac bad algorithm",
+			yaml: `yapi: v1
+url: https://example.com
+auth:
+  type: hmac
+  secret: s
+  algorithm: md5`,
+			wantMsg: "unsupported hmac algorithm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString(tt.yaml)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			var authIssues []Issue
+			for _, issue := range ValidateRequest(res.Request) {
+				if issue.Field == "auth" {
+					authIssues = append(authIssues, issue)
+				}
+			}
+
+			if tt.wantMsg == "" {
+				if len(authIssues) != 0 {
+					t.Errorf("expected no auth issues, got %+v", authIssues)
+				}
+				return
+			}
+			if len(authIssues) != 1 || !strings.Contains(authIssues[0].Message, tt.wantMsg) {
+				t.Errorf("expected one auth issue containing %q, got %+v", tt.wantMsg, authIssues)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_OutputSHA256(t *testing.T) {
+	tests := []struct {
+		name    string
+		sum     string
+		wantErr bool
+	}{
+		{"valid lowercase", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", false},
+		{"valid uppercase", "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08", false},
+		{"too short", "9f86d081", true},
+		{"not hex", strings.Repeat("z", 64), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString("yapi: v1\nurl: https://example.com/file.zip\noutput_file: ./file.zip\noutput_sha256: " + tt.sum)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			var found bool
+			for _, issue := range ValidateRequest(res.Request) {
+				if issue.Field == "output_sha256" {
+					found = true
+				}
+			}
+			if found != tt.wantErr {
+				t.Errorf("output_sha256 issue = %v, want %v", found, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_ResponseEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{"hex", false},
+		{"base64", false},
+		{"text", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			res, err := config.LoadFromString("yapi: v1\nurl: https://example.com/logo.png\nresponse_encoding: " + tt.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			var found bool
+			for _, issue := range ValidateRequest(res.Request) {
+				if issue.Field == "response_encoding" {
+					found = true
+				}
+			}
+			if found != tt.wantErr {
+				t.Errorf("response_encoding issue = %v, want %v", found, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_TLSClientCertRequiresKey(t *testing.T) {
+	res, err := config.LoadFromString(`yapi: v1
+url: tls://localhost:6380
+data: "PING\r\n"
+tls:
+  client_cert: ./client.crt`)
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if res.Request.Metadata["transport"] != "tcp" || res.Request.Metadata["tls"] != "true" {
+		t.Fatalf("expected TLS over TCP, got metadata %v", res.Request.Metadata)
+	}
+
+	issues := ValidateRequest(res.Request)
+	if len(issues) != 1 || issues[0].Field != "tls" {
+		t.Errorf("expected one tls issue, got %+v", issues)
+	}
+}
+
+func TestValidateRequest_Conversation(t *testing.T) {
+	res, err := config.LoadFromString(`yapi: v1
+url: tcp://localhost:25
+data: "ignored"
+conversation:
+  - expect_regex: "^220 ("
+  - send: "QUIT\r\n"
+    expect_literal: "221"
+    timeout: soon`)
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	var msgs []string
+	for _, issue := range ValidateRequest(res.Request) {
+		msgs = append(msgs, issue.Field+": "+issue.Message)
+	}
+	joined := strings.Join(msgs, "\n")
+	for _, want := range []string{"data: `data` is ignored", "step 1: invalid `expect_regex`", "step 2: invalid `timeout`"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected issue %q, got:\n%s", want, joined)
+		}
+	}
+}
+
+func TestValidateRequest_Framing(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing string
+		wantMsg string
+	}{
+		{"valid length prefix", "type: length_prefix\n  prefix_bytes: 2\n  byte_order: little", ""},
+		{"valid delimiter", "type: delimiter\n  delimiter: \"\\n\"", ""},
+		{"unknown type", "type: chunked", "unsupported framing type"},
+		{"bad prefix size", "type: length_prefix\n  prefix_bytes: 3", "prefix_bytes"},
+		{"bad byte order", "type: length_prefix\n  byte_order: middle", "byte_order"},
+		{"fixed without length", "type: fixed", "positive `length`"},
+		{"delimiter without delimiter", "type: delimiter", "requires `delimiter`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := config.LoadFromString("yapi: v1\nurl: tcp://localhost:9000\ndata: hi\nframing:\n  " + tt.framing)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			issues := ValidateRequest(res.Request)
+			if tt.wantMsg == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %+v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Field != "framing" || !strings.Contains(issues[0].Message, tt.wantMsg) {
+				t.Errorf("expected one framing issue containing %q, got %+v", tt.wantMsg, issues)
+			}
+		})
+	}
+}
@@ -19,8 +19,22 @@ var EnvOnly = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-
 // Resolver resolves a variable key to its value.
 type Resolver func(key string) (string, error)
 
-// ChainVar matches ${step.field} patterns (contains a dot).
-var ChainVar = regexp.MustCompile(`\$\{[^}]*\.[^}]+\}|\$[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-\.]+`)
+// ChainVar matches ${step.field} patterns (contains a dot) and jq references
+// such as ${step | .items[0].id} (contains a pipe).
+var ChainVar = regexp.MustCompile(`\$\{[^}]*[.|][^}]+\}|\$[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-\.]+`)
+
+// ChainRef splits a chain reference into the step it reads and the rest: the field
+// path of ${step.a.b}, or the jq expression of ${step | .items[0].id}, for which jq
+// is true. ok is false for keys that are not chain references (environment variables).
+func ChainRef(key string) (step, rest string, jq, ok bool) {
+	if step, expr, found := strings.Cut(key, "|"); found {
+		return strings.TrimSpace(step), strings.TrimSpace(expr), true, true
+	}
+	if step, path, found := strings.Cut(key, "."); found {
+		return step, path, false, true
+	}
+	return "", "", false, false
+}
 
 // HasChainVars returns true if the string contains chain variable references (${step.field}).
 func HasChainVars(s string) bool {
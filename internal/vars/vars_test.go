@@ -102,3 +102,27 @@ func FuzzHasEnvVars(f *testing.F) {
 		_ = HasEnvVars(input)
 	})
 }
+
+func TestChainRef(t *testing.T) {
+	tests := []struct {
+		key    string
+		step   string
+		rest   string
+		jq, ok bool
+	}{
+		{"login.token", "login", "token", false, true},
+		{"list.data.items", "list", "data.items", false, true},
+		{"list | .items[0].id", "list", ".items[0].id", true, true},
+		{"list|length", "list", "length", true, true},
+		{"API_KEY", "", "", false, false},
+	}
+	for _, tt := range tests {
+		step, rest, jq, ok := ChainRef(tt.key)
+		if step != tt.step || rest != tt.rest || jq != tt.jq || ok != tt.ok {
+			t.Errorf("ChainRef(%q) = %q, %q, %v, %v", tt.key, step, rest, jq, ok)
+		}
+	}
+	if !HasChainVars("${list | length}") || HasChainVars("${API_KEY}") {
+		t.Error("HasChainVars should match jq references only")
+	}
+}